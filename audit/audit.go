@@ -0,0 +1,20 @@
+package audit
+
+import "context"
+
+// Meta - откуда пришёл запрос. Заполняется HTTP-миддлварью, у фоновых задач и CLI пустая
+type Meta struct {
+	RequestID string
+	ClientIP  string
+}
+
+type metaKey struct{}
+
+func WithMeta(ctx context.Context, meta Meta) context.Context {
+	return context.WithValue(ctx, metaKey{}, meta)
+}
+
+func MetaFrom(ctx context.Context) Meta {
+	meta, _ := ctx.Value(metaKey{}).(Meta)
+	return meta
+}
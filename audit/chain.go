@@ -0,0 +1,139 @@
+package audit
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"ttavito/domain/entities"
+)
+
+// CanonicalPayload приводит JSON к виду, который не зависит от того, как его отформатировала база:
+// ключи по алфавиту, без пробелов, числа в исходной записи
+func CanonicalPayload(payload []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(payload))
+	dec.UseNumber()
+
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, fmt.Errorf("invalid audit payload: %w", err)
+	}
+	return json.Marshal(v)
+}
+
+// Hash считает хеш записи журнала. Каждое поле предваряется длиной,
+// поэтому перенос символов между соседними полями меняет хеш
+func Hash(e entities.AuditEvent) (string, error) {
+	payload, err := CanonicalPayload(e.Payload)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	for _, field := range []string{
+		e.PrevHash, e.Actor, e.Action, e.Target, string(payload),
+		e.RequestID, e.ClientIP, strconv.FormatInt(e.CreatedAt.UnixMicro(), 10),
+	} {
+		fmt.Fprintf(h, "%d:%s\n", len(field), field)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// BrokenLinkError - первая запись, на которой цепочка не сходится
+type BrokenLinkError struct {
+	ID     int64
+	Reason string
+}
+
+func (e *BrokenLinkError) Error() string {
+	return fmt.Sprintf("audit chain broken at event %d: %s", e.ID, e.Reason)
+}
+
+// Chain проверяет записи журнала по порядку chain_seq. Записи без хеша в начале журнала
+// сделаны до появления цепочки и только считаются
+type Chain struct {
+	Checked int
+	Legacy  int
+	LastID  int64
+	last    string
+	started bool
+}
+
+func (c *Chain) Add(e entities.AuditEvent) error {
+	if e.Hash == "" {
+		if c.started {
+			return &BrokenLinkError{ID: e.ID, Reason: "hash is missing"}
+		}
+		c.Legacy++
+		return nil
+	}
+
+	if e.PrevHash != c.last {
+		return &BrokenLinkError{ID: e.ID, Reason: "previous hash does not match, an earlier event was changed or removed"}
+	}
+
+	hash, err := Hash(e)
+	if err != nil {
+		return &BrokenLinkError{ID: e.ID, Reason: err.Error()}
+	}
+	if hash != e.Hash {
+		return &BrokenLinkError{ID: e.ID, Reason: "hash does not match the event contents"}
+	}
+
+	c.started = true
+	c.last = e.Hash
+	c.LastID = e.ID
+	c.Checked++
+	return nil
+}
+
+// Report - итог проверки журнала
+type Report struct {
+	Chain
+	Checkpoints int
+}
+
+// Verify проходит журнал через scan и сверяет его с контрольными точками: подпись каждой точки
+// должна быть верной, а запись с её id - иметь записанный в ней хеш. Точка, чьей записи
+// в журнале нет, означает, что хвост журнала удалён
+func Verify(scan func(fn func(entities.AuditEvent) error) error, checkpoints []Checkpoint, key ed25519.PublicKey) (*Report, error) {
+	expected := make(map[int64]Checkpoint, len(checkpoints))
+	for _, c := range checkpoints {
+		if !c.Verify(key) {
+			return nil, fmt.Errorf("checkpoint for event %d signed at %s has an invalid signature", c.ID, c.SignedAt.Format(time.RFC3339))
+		}
+		expected[c.ID] = c
+	}
+
+	res := &Report{}
+	err := scan(func(e entities.AuditEvent) error {
+		if err := res.Add(e); err != nil {
+			return err
+		}
+		c, ok := expected[e.ID]
+		if !ok {
+			return nil
+		}
+		if c.Hash != e.Hash {
+			return &BrokenLinkError{ID: e.ID, Reason: "hash differs from the signed checkpoint"}
+		}
+		delete(expected, e.ID)
+		res.Checkpoints++
+		return nil
+	})
+	if err != nil {
+		return res, err
+	}
+
+	for _, c := range checkpoints {
+		if _, ok := expected[c.ID]; ok {
+			return res, &BrokenLinkError{ID: c.ID, Reason: "event from the signed checkpoint is missing, the log was truncated"}
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,216 @@
+package audit
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"ttavito/domain/entities"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var base = time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+// chain строит корректную цепочку из n записей
+func chain(t *testing.T, n int) []entities.AuditEvent {
+	var res []entities.AuditEvent
+	prev := ""
+	for i := range n {
+		e := entities.AuditEvent{
+			ID:        int64(i + 1),
+			Actor:     "alice",
+			Action:    entities.AuditTransferSend,
+			Target:    "bob",
+			Payload:   json.RawMessage(`{"amount": 10, "transferId": "t"}`),
+			RequestID: "req",
+			CreatedAt: base.Add(time.Duration(i) * time.Second),
+			PrevHash:  prev,
+		}
+		var err error
+		e.Hash, err = Hash(e)
+		assert.NoError(t, err)
+		prev = e.Hash
+		res = append(res, e)
+	}
+	return res
+}
+
+func scanOf(events []entities.AuditEvent) func(fn func(entities.AuditEvent) error) error {
+	return func(fn func(entities.AuditEvent) error) error {
+		for _, e := range events {
+			if err := fn(e); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func brokenAt(t *testing.T, err error) int64 {
+	var broken *BrokenLinkError
+	if !errors.As(err, &broken) {
+		t.Fatalf("expected broken link, got %v", err)
+	}
+	return broken.ID
+}
+
+func TestHashIgnoresPayloadFormatting(t *testing.T) {
+	e := chain(t, 1)[0]
+	e.Payload = json.RawMessage(`{"transferId":"t","amount":10}`)
+	hash, err := Hash(e)
+	assert.NoError(t, err)
+	assert.Equal(t, chain(t, 1)[0].Hash, hash)
+}
+
+func TestHashSeparatesFields(t *testing.T) {
+	a := entities.AuditEvent{Actor: "ab", Action: "c", Payload: json.RawMessage(`{}`)}
+	b := entities.AuditEvent{Actor: "a", Action: "bc", Payload: json.RawMessage(`{}`)}
+	ha, _ := Hash(a)
+	hb, _ := Hash(b)
+	assert.NotEqual(t, ha, hb)
+}
+
+func TestChainValid(t *testing.T) {
+	legacy := entities.AuditEvent{ID: 1, Payload: json.RawMessage(`{}`)}
+	events := chain(t, 3)
+	for i := range events {
+		events[i].ID += 1
+	}
+	// Хеш не зависит от id, поэтому сдвиг id цепочку не ломает
+	events = append([]entities.AuditEvent{legacy}, events...)
+
+	var c Chain
+	for _, e := range events {
+		assert.NoError(t, c.Add(e))
+	}
+	assert.Equal(t, 3, c.Checked)
+	assert.Equal(t, 1, c.Legacy)
+	assert.Equal(t, int64(4), c.LastID)
+}
+
+func TestChainBroken(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func([]entities.AuditEvent) []entities.AuditEvent
+		want   int64
+	}{
+		{
+			name: "changed payload",
+			tamper: func(e []entities.AuditEvent) []entities.AuditEvent {
+				e[1].Payload = json.RawMessage(`{"amount": 1000, "transferId": "t"}`)
+				return e
+			},
+			want: 2,
+		},
+		{
+			name: "removed event",
+			tamper: func(e []entities.AuditEvent) []entities.AuditEvent {
+				return append(e[:1], e[2:]...)
+			},
+			want: 3,
+		},
+		{
+			name: "rehashed event",
+			tamper: func(e []entities.AuditEvent) []entities.AuditEvent {
+				e[1].Actor = "mallory"
+				e[1].Hash, _ = Hash(e[1])
+				return e
+			},
+			want: 3,
+		},
+		{
+			name: "hash removed",
+			tamper: func(e []entities.AuditEvent) []entities.AuditEvent {
+				e[2].Hash = ""
+				return e
+			},
+			want: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var c Chain
+			var err error
+			for _, e := range tt.tamper(chain(t, 4)) {
+				if err = c.Add(e); err != nil {
+					break
+				}
+			}
+			assert.Equal(t, tt.want, brokenAt(t, err))
+		})
+	}
+}
+
+func TestVerifyCheckpoints(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	events := chain(t, 5)
+	checkpoints := []Checkpoint{
+		Sign(priv, 2, events[1].Hash, base),
+		Sign(priv, 5, events[4].Hash, base.Add(time.Hour)),
+	}
+
+	res, err := Verify(scanOf(events), checkpoints, pub)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, res.Checked)
+	assert.Equal(t, 2, res.Checkpoints)
+
+	// Хвост удалён: цепочка сходится, но подписанной записи нет
+	_, err = Verify(scanOf(events[:4]), checkpoints, pub)
+	assert.Equal(t, int64(5), brokenAt(t, err))
+
+	// Журнал переписан целиком с новыми хешами
+	rewritten := chain(t, 5)
+	for i := range rewritten {
+		rewritten[i].Target = "carol"
+		if i > 0 {
+			rewritten[i].PrevHash = rewritten[i-1].Hash
+		}
+		rewritten[i].Hash, _ = Hash(rewritten[i])
+	}
+	_, err = Verify(scanOf(rewritten), checkpoints, pub)
+	assert.Equal(t, int64(2), brokenAt(t, err))
+
+	forged := checkpoints[0]
+	forged.ID = 3
+	_, err = Verify(scanOf(events), []Checkpoint{forged}, pub)
+	assert.ErrorContains(t, err, "invalid signature")
+}
+
+func TestCheckpointFile(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	path := filepath.Join(t.TempDir(), "audit", "checkpoints.jsonl")
+
+	first := Sign(priv, 1, "aa", base)
+	second := Sign(priv, 7, "bb", base.Add(time.Hour))
+	assert.NoError(t, AppendCheckpoint(path, first))
+	assert.NoError(t, AppendCheckpoint(path, second))
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	got, err := ReadCheckpoints(bytes.NewReader(data))
+	assert.NoError(t, err)
+	if !assert.Len(t, got, 2) {
+		return
+	}
+	assert.True(t, got[0].Verify(pub))
+	assert.True(t, got[1].Verify(pub))
+	assert.Equal(t, int64(7), got[1].ID)
+}
+
+func TestParseKeys(t *testing.T) {
+	_, err := ParseSigningKey("short")
+	assert.Error(t, err)
+	_, err = ParsePublicKey("AAAA")
+	assert.Error(t, err)
+
+	key, err := ParseSigningKey("AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=")
+	assert.NoError(t, err)
+	assert.Len(t, key, ed25519.PrivateKeySize)
+}
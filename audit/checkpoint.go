@@ -0,0 +1,100 @@
+package audit
+
+import (
+	"bufio"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Checkpoint фиксирует последнюю запись цепочки подписью ed25519. Файл с контрольными
+// точками хранится отдельно от базы, поэтому переписать журнал целиком или обрезать его
+// хвост незаметно не получится
+type Checkpoint struct {
+	ID        int64     `json:"id"`
+	Hash      string    `json:"hash"`
+	SignedAt  time.Time `json:"signedAt"`
+	Signature string    `json:"signature"`
+}
+
+func (c Checkpoint) message() []byte {
+	return fmt.Appendf(nil, "ttavito-audit-checkpoint\n%d\n%s\n%d", c.ID, c.Hash, c.SignedAt.Unix())
+}
+
+func Sign(key ed25519.PrivateKey, id int64, hash string, now time.Time) Checkpoint {
+	c := Checkpoint{ID: id, Hash: hash, SignedAt: now.UTC().Truncate(time.Second)}
+	c.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(key, c.message()))
+	return c
+}
+
+func (c Checkpoint) Verify(key ed25519.PublicKey) bool {
+	sig, err := base64.StdEncoding.DecodeString(c.Signature)
+	if err != nil {
+		return false
+	}
+	return ed25519.Verify(key, c.message(), sig)
+}
+
+// ParseSigningKey принимает seed ed25519 в base64 (32 байта)
+func ParseSigningKey(s string) (ed25519.PrivateKey, error) {
+	seed, err := base64.StdEncoding.DecodeString(s)
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, errors.New("signing key must be a base64 encoded 32 byte ed25519 seed")
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+func ParsePublicKey(s string) (ed25519.PublicKey, error) {
+	key, err := base64.StdEncoding.DecodeString(s)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, errors.New("public key must be a base64 encoded 32 byte ed25519 key")
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// AppendCheckpoint дописывает контрольную точку строкой JSON в конец файла
+func AppendCheckpoint(path string, c Checkpoint) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create checkpoint directory: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open checkpoint file: %w", err)
+	}
+
+	line, _ := json.Marshal(c)
+	_, err = f.Write(append(line, '\n'))
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	return nil
+}
+
+func ReadCheckpoints(r io.Reader) ([]Checkpoint, error) {
+	var res []Checkpoint
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var c Checkpoint
+		if err := json.Unmarshal(scanner.Bytes(), &c); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		res = append(res, c)
+	}
+	return res, scanner.Err()
+}
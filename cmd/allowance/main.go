@@ -0,0 +1,52 @@
+// Ручная выдача пособия: go run ./cmd/allowance -period 2025-02 -amount 500
+// Без флагов выдаёт пособие за текущий период по настройкам из окружения.
+// Повторный запуск за тот же период начисляет монеты только тем, кто их ещё не получил.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"ttavito/config"
+	"ttavito/database"
+	"ttavito/repository"
+	"ttavito/usecase"
+)
+
+func main() {
+	cfg := config.LoadConfig()
+
+	period := flag.String("period", "", "period key, e.g. 2025-02, 2025-W07 or 2025-02-14 (default: current period)")
+	amount := flag.Int("amount", cfg.AllowanceAmount, "coins per user")
+	flag.Parse()
+
+	if *period == "" {
+		key, err := usecase.AllowancePeriodKey(cfg.AllowancePeriod, time.Now())
+		if err != nil {
+			slog.Error("Failed to resolve allowance period", "error", err)
+			os.Exit(1)
+		}
+		*period = key
+	}
+
+	pool, err := database.NewPostgresDB(cfg)
+	if err != nil {
+		slog.Error("Failed to create connection pool", "error", err)
+		os.Exit(1)
+	}
+	defer pool.Close()
+
+	api := usecase.NewUsecase(repository.NewEntityRepo(pool, repository.ConfigOptions(cfg)...))
+
+	count, err := api.IssueAllowanceForPeriod(context.Background(), *period, *amount)
+	if err != nil {
+		slog.Error("Failed to issue allowance", "error", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("period %s: issued %d coins to %d users\n", *period, *amount, count)
+}
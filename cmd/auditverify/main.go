@@ -0,0 +1,90 @@
+// Проверка журнала действий: go run ./cmd/auditverify -checkpoints ./data/audit-checkpoints.jsonl -public-key <base64>
+// Проходит цепочку хешей от первой записи и печатает первое расхождение. С файлом контрольных
+// точек также проверяет подписи и то, что подписанные записи на месте.
+// Новая пара ключей: go run ./cmd/auditverify -genkey
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"ttavito/audit"
+	"ttavito/config"
+	"ttavito/database"
+	"ttavito/repository"
+	"ttavito/usecase"
+)
+
+func main() {
+	checkpointsFile := flag.String("checkpoints", "", "file with signed checkpoints (default: chain only)")
+	publicKey := flag.String("public-key", os.Getenv("AUDIT_PUBLIC_KEY"), "base64 ed25519 public key of checkpoints")
+	genkey := flag.Bool("genkey", false, "print a new signing key and its public key")
+	flag.Parse()
+
+	if *genkey {
+		pub, priv, err := ed25519.GenerateKey(nil)
+		if err != nil {
+			slog.Error("Failed to generate key", "error", err)
+			os.Exit(1)
+		}
+		fmt.Printf("AUDIT_SIGNING_KEY=%s\n", base64.StdEncoding.EncodeToString(priv.Seed()))
+		fmt.Printf("AUDIT_PUBLIC_KEY=%s\n", base64.StdEncoding.EncodeToString(pub))
+		return
+	}
+
+	var checkpoints []audit.Checkpoint
+	var key ed25519.PublicKey
+	if *checkpointsFile != "" {
+		var err error
+		key, err = audit.ParsePublicKey(*publicKey)
+		if err != nil {
+			slog.Error("Invalid public key", "error", err)
+			os.Exit(1)
+		}
+
+		f, err := os.Open(*checkpointsFile)
+		if err != nil {
+			slog.Error("Failed to open checkpoints", "error", err)
+			os.Exit(1)
+		}
+		checkpoints, err = audit.ReadCheckpoints(f)
+		f.Close()
+		if err != nil {
+			slog.Error("Failed to read checkpoints", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	cfg := config.LoadConfig()
+	pool, err := database.NewPostgresDB(cfg)
+	if err != nil {
+		slog.Error("Failed to create connection pool", "error", err)
+		os.Exit(1)
+	}
+	defer pool.Close()
+
+	api := usecase.NewUsecase(repository.NewEntityRepo(pool, repository.ConfigOptions(cfg)...))
+
+	report, err := api.VerifyAuditChain(context.Background(), checkpoints, key)
+	var broken *audit.BrokenLinkError
+	if errors.As(err, &broken) {
+		fmt.Printf("FAILED: %v\n", broken)
+		fmt.Printf("verified %d events before the break (%d legacy events without hash)\n", report.Checked, report.Legacy)
+		pool.Close()
+		os.Exit(2)
+	}
+	if err != nil {
+		slog.Error("Failed to verify audit log", "error", err)
+		pool.Close()
+		os.Exit(1)
+	}
+
+	fmt.Printf("OK: %d events verified, last id %d, %d legacy events without hash, %d checkpoints matched\n",
+		report.Checked, report.LastID, report.Legacy, report.Checkpoints)
+}
@@ -1,21 +1,40 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"net/http"
 
+	"ttavito/audit"
 	"ttavito/config"
 	"ttavito/database"
 	myHttp "ttavito/delivery/http"
+	"ttavito/domain/interfaces"
+	"ttavito/events"
+	"ttavito/fraud"
+	"ttavito/internal"
+	"ttavito/mail"
+	"ttavito/realtime"
 	"ttavito/repository"
+	"ttavito/storage"
 	"ttavito/usecase"
+	"ttavito/webhook"
+	"ttavito/worker"
 )
 
 func main() {
 	cfg := config.LoadConfig()
 
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	pool, err := database.NewPostgresDB(cfg)
 	if err != nil {
 		slog.Error("Failed to create connection pool", "error", err)
@@ -23,8 +42,89 @@ func main() {
 	}
 	defer pool.Close()
 
-	repo := repository.NewEntityRepo(pool)
-	api := usecase.NewUsecase(repo)
+	repo := repository.NewEntityRepo(pool, repository.ConfigOptions(cfg)...)
+
+	avatars, err := newAvatarStore(cfg)
+	if err != nil {
+		slog.Error("Failed to create avatar storage", "error", err)
+		return
+	}
+
+	// Все события проходят через шину: на неё подписаны вебхуки и, если настроен, внешний брокер
+	bus := events.NewBus()
+	broker, err := newEventBroker(cfg)
+	if err != nil {
+		slog.Error("Failed to create event publisher", "error", err)
+		return
+	}
+	if broker != nil {
+		bus.Subscribe("", broker.Publish)
+	}
+
+	// События пользователей приходят через LISTEN/NOTIFY, поэтому доходят до клиента на любой реплике
+	hub := realtime.NewHub()
+	go database.Listen(ctx, pool, realtime.Channel, hub.Dispatch)
+	go database.Listen(ctx, pool, realtime.FeedChannel, hub.DispatchFeed)
+	go database.Listen(ctx, pool, realtime.UsersChannel, hub.DispatchUsers)
+
+	opts := []usecase.Option{
+		usecase.WithPendingTransferTTL(cfg.PendingTransferTTL),
+		usecase.WithAllowance(cfg.AllowanceAmount, cfg.AllowancePeriod),
+		usecase.WithAvatarStore(avatars),
+		usecase.WithFraudDetection(fraud.Rules{
+			CycleMaxLength:       cfg.FraudCycleMaxLength,
+			CycleMinAmount:       cfg.FraudCycleMinAmount,
+			FreshAccountAge:      cfg.FraudFreshAccountAge,
+			FanInMinSenders:      cfg.FraudFanInMinSenders,
+			VelocityWindow:       cfg.FraudVelocityWindow,
+			VelocityMaxTransfers: cfg.FraudVelocityMax,
+		}, cfg.FraudWindow),
+		usecase.WithEventPublisher(bus, cfg.OutboxRetention),
+		usecase.WithWebhooks(webhook.NewSender(cfg.WebhookTimeout), cfg.WebhookMaxAttempts),
+		usecase.WithUserEvents(hub),
+	}
+	if cfg.AuditSigningKey != "" {
+		key, err := audit.ParseSigningKey(cfg.AuditSigningKey)
+		if err != nil {
+			slog.Error("Invalid AUDIT_SIGNING_KEY", "error", err)
+			return
+		}
+		opts = append(opts, usecase.WithAuditCheckpoints(key, cfg.AuditCheckpointFile))
+	}
+	mailer, err := newMailer(cfg)
+	if err != nil {
+		slog.Error("Failed to create mailer", "error", err)
+		return
+	}
+	if mailer != nil {
+		opts = append(opts, usecase.WithDigests(mailer, cfg.MailDomain))
+	}
+	api := usecase.NewUsecase(repo, opts...)
+	bus.Subscribe("", api.EnqueueWebhooks)
+
+	// Токены уволенных сотрудников отклоняются AuthMiddleware
+	internal.SetSessionStore(api)
+
+	// Фоновые задачи
+	go worker.RunPeriodic(ctx, "pending-transfers-sweeper", cfg.PendingSweepInterval, api.ExpirePendingTransfers)
+	go worker.RunPeriodic(ctx, "scheduled-transfers", cfg.ScheduledTransfersInterval, api.RunScheduledTransfers)
+	if cfg.CoinTTL > 0 {
+		go worker.RunPeriodic(ctx, "coin-expiry", cfg.CoinExpiryInterval, api.ExpireCoinLots)
+	}
+	go worker.RunPeriodic(ctx, "fraud-detection", cfg.FraudDetectionInterval, api.DetectFraud)
+	go worker.RunPeriodic(ctx, "outbox-relay", cfg.OutboxRelayInterval, api.RelayOutbox)
+	go worker.RunPeriodic(ctx, "outbox-cleanup", time.Hour, api.CleanupOutbox)
+	go worker.RunPeriodic(ctx, "webhook-delivery", cfg.WebhookDeliveryInterval, api.DeliverWebhooks)
+	go worker.RunPeriodic(ctx, "audit-chainer", cfg.AuditChainInterval, api.ChainAuditEvents)
+	if cfg.AuditSigningKey != "" {
+		go worker.RunPeriodic(ctx, "audit-checkpoints", cfg.AuditCheckpointInterval, api.ExportAuditCheckpoint)
+	}
+	if mailer != nil {
+		go worker.RunPeriodic(ctx, "email-digests", cfg.DigestInterval, api.SendDigests)
+	}
+	if cfg.AllowanceAmount > 0 {
+		go worker.RunPeriodic(ctx, "allowance-issuer", cfg.AllowanceCheckInterval, api.IssueAllowance)
+	}
 
 	mux := http.NewServeMux()
 	myHttp.SetupRoutes(api, mux)
@@ -38,14 +138,79 @@ func main() {
 
 	server := &http.Server{
 		Addr:           ":" + port,
-		Handler:        mux,
+		Handler:        internal.RequestMetaMiddleware(cfg.TrustProxyHeaders)(mux),
 		ReadTimeout:    10 * time.Second,
 		WriteTimeout:   10 * time.Second,
 		MaxHeaderBytes: 1 << 20,
 	}
+	// Shutdown не ждёт открытые потоки событий и WebSocket-сессии, их нужно закрыть явно
+	server.RegisterOnShutdown(hub.Close)
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
 
-	if err := server.ListenAndServe(); err != nil {
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		slog.Error("Failed to start server: ", "error", err)
 		return
 	}
 }
+
+func newAvatarStore(cfg *config.Config) (interfaces.FileStore, error) {
+	switch cfg.AvatarStorage {
+	case "local":
+		return storage.NewLocalStore(cfg.AvatarDir)
+	case "s3":
+		if cfg.S3Endpoint == "" {
+			return nil, errors.New("S3_ENDPOINT is required for s3 avatar storage")
+		}
+		return storage.NewS3Store(storage.S3Config{
+			Endpoint:  cfg.S3Endpoint,
+			Bucket:    cfg.S3Bucket,
+			Region:    cfg.S3Region,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+		})
+	default:
+		return nil, fmt.Errorf("unknown AVATAR_STORAGE %q", cfg.AvatarStorage)
+	}
+}
+
+// newEventBroker возвращает внешний брокер событий или nil, если события остаются внутри сервиса
+func newEventBroker(cfg *config.Config) (interfaces.EventPublisher, error) {
+	switch cfg.EventPublisher {
+	case "inprocess":
+		return nil, nil
+	case "nats":
+		return events.NewNATSPublisher(cfg.NATSURL, cfg.NATSSubjectPrefix)
+	default:
+		return nil, fmt.Errorf("unknown EVENT_PUBLISHER %q", cfg.EventPublisher)
+	}
+}
+
+// newMailer возвращает отправителя писем или nil, если сводка выключена
+func newMailer(cfg *config.Config) (interfaces.Mailer, error) {
+	switch cfg.Mailer {
+	case "none":
+		return nil, nil
+	case "log", "smtp":
+		if cfg.MailDomain == "" {
+			return nil, errors.New("MAIL_DOMAIN is required to send digests")
+		}
+	default:
+		return nil, fmt.Errorf("unknown MAILER %q", cfg.Mailer)
+	}
+
+	if cfg.Mailer == "log" {
+		return mail.LogSender{}, nil
+	}
+	return mail.NewSMTPSender(mail.SMTPConfig{
+		Addr:     cfg.SMTPAddr,
+		Username: cfg.SMTPUsername,
+		Password: cfg.SMTPPassword,
+		From:     cfg.MailFrom,
+	}), nil
+}
@@ -0,0 +1,73 @@
+// Импорт оргструктуры: go run ./cmd/orgimport -file org.csv [-dry-run]
+// Формат файла описан в пакете directory. Все сотрудники и руководители из файла
+// должны уже существовать; при ошибке не применяется ни одна строка.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"ttavito/config"
+	"ttavito/database"
+	"ttavito/directory"
+	"ttavito/repository"
+	"ttavito/usecase"
+)
+
+func main() {
+	path := flag.String("file", "", "org chart file, .csv or .json")
+	format := flag.String("format", "", "csv or json (default: by file extension)")
+	dryRun := flag.Bool("dry-run", false, "validate and roll back without saving")
+	flag.Parse()
+
+	if *path == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if *format == "" {
+		var err error
+		*format, err = directory.FormatFromPath(*path)
+		if err != nil {
+			slog.Error("Failed to detect org chart format", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	file, err := os.Open(*path)
+	if err != nil {
+		slog.Error("Failed to open org chart", "error", err)
+		os.Exit(1)
+	}
+	entries, err := directory.Parse(file, *format)
+	file.Close()
+	if err != nil {
+		slog.Error("Failed to parse org chart", "error", err)
+		os.Exit(1)
+	}
+
+	cfg := config.LoadConfig()
+	pool, err := database.NewPostgresDB(cfg)
+	if err != nil {
+		slog.Error("Failed to create connection pool", "error", err)
+		os.Exit(1)
+	}
+	defer pool.Close()
+
+	api := usecase.NewUsecase(repository.NewEntityRepo(pool, repository.ConfigOptions(cfg)...))
+
+	res, err := api.ImportOrgChart(context.Background(), entries, *dryRun)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "org chart import failed:\n%v\n", err)
+		os.Exit(1)
+	}
+
+	mode := "imported"
+	if res.DryRun {
+		mode = "validated (dry run, nothing saved)"
+	}
+	fmt.Printf("%s: %d departments, %d teams, %d users\n", mode, res.Departments, res.Teams, res.Users)
+}
@@ -1,6 +1,10 @@
 package config
 
-import "os"
+import (
+	"os"
+	"strconv"
+	"time"
+)
 
 type Config struct {
 	Port       string
@@ -9,6 +13,83 @@ type Config struct {
 	DBHost     string
 	DBPort     string
 	DBName     string
+
+	// Брать адрес клиента для журнала действий из X-Forwarded-For. Включать только за своим прокси
+	TrustProxyHeaders bool
+
+	PendingTransferTTL   time.Duration
+	PendingSweepInterval time.Duration
+
+	ScheduledTransfersInterval time.Duration
+
+	// Лимиты переводов, 0 - без ограничения
+	TransferMaxAmount           int
+	TransferDailyLimit          int
+	TransferWeeklyLimit         int
+	TransferRecipientDailyLimit int
+	TransferRecipientCooldown   time.Duration
+
+	// Наибольшая сумма одного начисления администратором, 0 - без ограничения
+	GrantMaxAmount int
+
+	// Периодическое пособие, 0 - выдача выключена
+	AllowanceAmount        int
+	AllowancePeriod        string
+	AllowanceCheckInterval time.Duration
+
+	// Сгорание монет, 0 - монеты бессрочные
+	CoinTTL            time.Duration
+	CoinExpiryWarning  time.Duration
+	CoinExpiryInterval time.Duration
+
+	// Поиск схем отмывания монет, 0 - правило выключено
+	FraudDetectionInterval time.Duration
+	FraudWindow            time.Duration
+	FraudCycleMaxLength    int
+	FraudCycleMinAmount    int
+	FraudFreshAccountAge   time.Duration
+	FraudFanInMinSenders   int
+	FraudVelocityWindow    time.Duration
+	FraudVelocityMax       int
+
+	// Как часто новые записи журнала связываются в цепочку хешей
+	AuditChainInterval time.Duration
+
+	// Подписанные контрольные точки журнала действий, без ключа выключены
+	AuditSigningKey         string
+	AuditCheckpointFile     string
+	AuditCheckpointInterval time.Duration
+
+	// Публикация доменных событий: inprocess (только подписчики внутри сервиса) или nats
+	EventPublisher      string
+	NATSURL             string
+	NATSSubjectPrefix   string
+	OutboxRelayInterval time.Duration
+	OutboxRetention     time.Duration
+
+	// Доставка вебхуков подписчикам
+	WebhookDeliveryInterval time.Duration
+	WebhookTimeout          time.Duration
+	WebhookMaxAttempts      int
+
+	// Хранилище аватаров: local (каталог AvatarDir) или s3 (S3-совместимое хранилище)
+	AvatarStorage string
+	AvatarDir     string
+	S3Endpoint    string
+	S3Bucket      string
+	S3Region      string
+	S3AccessKey   string
+	S3SecretKey   string
+
+	// Еженедельная сводка на почту: none (выключена), log (письма в лог) или smtp.
+	// Адрес сотрудника - логин@MailDomain
+	Mailer         string
+	SMTPAddr       string
+	SMTPUsername   string
+	SMTPPassword   string
+	MailFrom       string
+	MailDomain     string
+	DigestInterval time.Duration
 }
 
 func GetEnvWithDefault(key string, defaultValue string) string {
@@ -22,6 +103,34 @@ func GetEnvWithDefault(key string, defaultValue string) string {
 	return value
 }
 
+// GetDurationWithDefault разбирает значение вида "90s", "24h".
+// Некорректное значение заменяется значением по умолчанию.
+func GetDurationWithDefault(key string, defaultValue time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return defaultValue
+	}
+	return value
+}
+
+// GetIntWithDefault разбирает целое неотрицательное число
+func GetIntWithDefault(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value < 0 {
+		return defaultValue
+	}
+	return value
+}
+
+// getOptionalDuration - как GetDurationWithDefault, но допускает 0 (правило выключено)
+func getOptionalDuration(key string) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || value < 0 {
+		return 0
+	}
+	return value
+}
+
 func LoadConfig() *Config {
 	return &Config{
 		Port:       "8080",
@@ -30,5 +139,69 @@ func LoadConfig() *Config {
 		DBHost:     GetEnvWithDefault("DB_HOST", "localhost"),
 		DBPort:     GetEnvWithDefault("DB_PORT", "5432"),
 		DBName:     GetEnvWithDefault("DB_NAME", "ttavito"),
+
+		TrustProxyHeaders: os.Getenv("TRUST_PROXY_HEADERS") == "true",
+
+		PendingTransferTTL:   GetDurationWithDefault("PENDING_TRANSFER_TTL", 72*time.Hour),
+		PendingSweepInterval: GetDurationWithDefault("PENDING_SWEEP_INTERVAL", time.Minute),
+
+		ScheduledTransfersInterval: GetDurationWithDefault("SCHEDULED_TRANSFERS_INTERVAL", 30*time.Second),
+
+		TransferMaxAmount:           GetIntWithDefault("TRANSFER_MAX_AMOUNT", 0),
+		TransferDailyLimit:          GetIntWithDefault("TRANSFER_DAILY_LIMIT", 0),
+		TransferWeeklyLimit:         GetIntWithDefault("TRANSFER_WEEKLY_LIMIT", 0),
+		TransferRecipientDailyLimit: GetIntWithDefault("TRANSFER_RECIPIENT_DAILY_LIMIT", 0),
+		TransferRecipientCooldown:   getOptionalDuration("TRANSFER_RECIPIENT_COOLDOWN"),
+
+		GrantMaxAmount: GetIntWithDefault("GRANT_MAX_AMOUNT", 10000),
+
+		AllowanceAmount:        GetIntWithDefault("ALLOWANCE_AMOUNT", 0),
+		AllowancePeriod:        GetEnvWithDefault("ALLOWANCE_PERIOD", "monthly"),
+		AllowanceCheckInterval: GetDurationWithDefault("ALLOWANCE_CHECK_INTERVAL", time.Hour),
+
+		CoinTTL:            getOptionalDuration("COIN_TTL"),
+		CoinExpiryWarning:  GetDurationWithDefault("COIN_EXPIRY_WARNING", 7*24*time.Hour),
+		CoinExpiryInterval: GetDurationWithDefault("COIN_EXPIRY_INTERVAL", 10*time.Minute),
+
+		FraudDetectionInterval: GetDurationWithDefault("FRAUD_DETECTION_INTERVAL", time.Hour),
+		FraudWindow:            GetDurationWithDefault("FRAUD_WINDOW", 7*24*time.Hour),
+		FraudCycleMaxLength:    GetIntWithDefault("FRAUD_CYCLE_MAX_LENGTH", 5),
+		FraudCycleMinAmount:    GetIntWithDefault("FRAUD_CYCLE_MIN_AMOUNT", 100),
+		FraudFreshAccountAge:   GetDurationWithDefault("FRAUD_FRESH_ACCOUNT_AGE", 72*time.Hour),
+		FraudFanInMinSenders:   GetIntWithDefault("FRAUD_FAN_IN_MIN_SENDERS", 5),
+		FraudVelocityWindow:    GetDurationWithDefault("FRAUD_VELOCITY_WINDOW", time.Hour),
+		FraudVelocityMax:       GetIntWithDefault("FRAUD_VELOCITY_MAX_TRANSFERS", 30),
+
+		AuditChainInterval: GetDurationWithDefault("AUDIT_CHAIN_INTERVAL", time.Second),
+
+		AuditSigningKey:         os.Getenv("AUDIT_SIGNING_KEY"),
+		AuditCheckpointFile:     GetEnvWithDefault("AUDIT_CHECKPOINT_FILE", "./data/audit-checkpoints.jsonl"),
+		AuditCheckpointInterval: GetDurationWithDefault("AUDIT_CHECKPOINT_INTERVAL", time.Hour),
+
+		EventPublisher:      GetEnvWithDefault("EVENT_PUBLISHER", "inprocess"),
+		NATSURL:             GetEnvWithDefault("NATS_URL", "nats://localhost:4222"),
+		NATSSubjectPrefix:   GetEnvWithDefault("NATS_SUBJECT_PREFIX", "ttavito"),
+		OutboxRelayInterval: GetDurationWithDefault("OUTBOX_RELAY_INTERVAL", time.Second),
+		OutboxRetention:     GetDurationWithDefault("OUTBOX_RETENTION", 7*24*time.Hour),
+
+		WebhookDeliveryInterval: GetDurationWithDefault("WEBHOOK_DELIVERY_INTERVAL", 5*time.Second),
+		WebhookTimeout:          GetDurationWithDefault("WEBHOOK_TIMEOUT", 10*time.Second),
+		WebhookMaxAttempts:      GetIntWithDefault("WEBHOOK_MAX_ATTEMPTS", 10),
+
+		AvatarStorage: GetEnvWithDefault("AVATAR_STORAGE", "local"),
+		AvatarDir:     GetEnvWithDefault("AVATAR_DIR", "./data/avatars"),
+		S3Endpoint:    os.Getenv("S3_ENDPOINT"),
+		S3Bucket:      GetEnvWithDefault("S3_BUCKET", "avatars"),
+		S3Region:      GetEnvWithDefault("S3_REGION", "us-east-1"),
+		S3AccessKey:   os.Getenv("S3_ACCESS_KEY"),
+		S3SecretKey:   os.Getenv("S3_SECRET_KEY"),
+
+		Mailer:         GetEnvWithDefault("MAILER", "none"),
+		SMTPAddr:       GetEnvWithDefault("SMTP_ADDR", "localhost:25"),
+		SMTPUsername:   os.Getenv("SMTP_USERNAME"),
+		SMTPPassword:   os.Getenv("SMTP_PASSWORD"),
+		MailFrom:       GetEnvWithDefault("MAIL_FROM", "Магазин мерча <merch@localhost>"),
+		MailDomain:     os.Getenv("MAIL_DOMAIN"),
+		DigestInterval: GetDurationWithDefault("DIGEST_INTERVAL", time.Hour),
 	}
 }
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -25,6 +26,48 @@ func TestGetEnvWithDefault(t *testing.T) {
 	})
 }
 
+func TestGetDurationWithDefault(t *testing.T) {
+	t.Run("parses duration from environment variable", func(t *testing.T) {
+		os.Setenv("TEST_DURATION", "90s")
+		defer os.Unsetenv("TEST_DURATION")
+
+		result := GetDurationWithDefault("TEST_DURATION", time.Minute)
+		assert.Equal(t, 90*time.Second, result)
+	})
+
+	t.Run("returns default value on invalid duration", func(t *testing.T) {
+		os.Setenv("TEST_DURATION", "soon")
+		defer os.Unsetenv("TEST_DURATION")
+
+		result := GetDurationWithDefault("TEST_DURATION", time.Minute)
+		assert.Equal(t, time.Minute, result)
+	})
+
+	t.Run("returns default value if environment variable is not set", func(t *testing.T) {
+		result := GetDurationWithDefault("NON_EXISTENT_VAR", time.Minute)
+		assert.Equal(t, time.Minute, result)
+	})
+}
+
+func TestGetIntWithDefault(t *testing.T) {
+	t.Run("parses int from environment variable", func(t *testing.T) {
+		os.Setenv("TEST_INT", "250")
+		defer os.Unsetenv("TEST_INT")
+
+		assert.Equal(t, 250, GetIntWithDefault("TEST_INT", 0))
+	})
+
+	t.Run("returns default value on negative or invalid value", func(t *testing.T) {
+		os.Setenv("TEST_INT", "-1")
+		defer os.Unsetenv("TEST_INT")
+
+		assert.Equal(t, 10, GetIntWithDefault("TEST_INT", 10))
+
+		os.Setenv("TEST_INT", "many")
+		assert.Equal(t, 10, GetIntWithDefault("TEST_INT", 10))
+	})
+}
+
 func TestLoadConfig(t *testing.T) {
 	t.Run("loads default config when environment variables are not set", func(t *testing.T) {
 		// Очищаем все переменные окружения
@@ -44,6 +87,20 @@ func TestLoadConfig(t *testing.T) {
 		assert.Equal(t, "localhost", config.DBHost)
 		assert.Equal(t, "5432", config.DBPort)
 		assert.Equal(t, "ttavito", config.DBName)
+		assert.Equal(t, 72*time.Hour, config.PendingTransferTTL)
+		assert.Equal(t, time.Minute, config.PendingSweepInterval)
+		assert.Equal(t, 30*time.Second, config.ScheduledTransfersInterval)
+		assert.Zero(t, config.TransferMaxAmount)
+		assert.Zero(t, config.TransferRecipientCooldown)
+		assert.Zero(t, config.AllowanceAmount)
+		assert.Equal(t, "monthly", config.AllowancePeriod)
+		assert.Equal(t, time.Hour, config.AllowanceCheckInterval)
+		assert.Zero(t, config.CoinTTL)
+		assert.Equal(t, 7*24*time.Hour, config.CoinExpiryWarning)
+		assert.Equal(t, "local", config.AvatarStorage)
+		assert.Equal(t, "./data/avatars", config.AvatarDir)
+		assert.Equal(t, "none", config.Mailer)
+		assert.Equal(t, time.Hour, config.DigestInterval)
 	})
 
 	t.Run("loads config from environment variables", func(t *testing.T) {
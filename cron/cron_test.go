@@ -0,0 +1,71 @@
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	valid := []string{
+		"* * * * *",
+		"0 9 1 * *",
+		"*/15 9-18 * * 1-5",
+		"0 0 1,15 * *",
+		"5/10 * * * 7",
+	}
+	for _, expr := range valid {
+		_, err := Parse(expr)
+		assert.NoError(t, err, expr)
+	}
+
+	invalid := []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+	}
+	for _, expr := range invalid {
+		_, err := Parse(expr)
+		assert.Error(t, err, expr)
+	}
+}
+
+func TestNext(t *testing.T) {
+	base := time.Date(2025, time.February, 14, 10, 30, 45, 0, time.UTC)
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2025, time.February, 14, 10, 31, 0, 0, time.UTC)},
+		{"0 9 1 * *", time.Date(2025, time.March, 1, 9, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2025, time.February, 14, 10, 45, 0, 0, time.UTC)},
+		// 14.02.2025 - пятница, следующий понедельник - 17.02
+		{"0 10 * * 1", time.Date(2025, time.February, 17, 10, 0, 0, 0, time.UTC)},
+		// 7 и 0 - воскресенье
+		{"0 0 * * 7", time.Date(2025, time.February, 16, 0, 0, 0, 0, time.UTC)},
+		// Ограничены оба поля: 15-е число или понедельник, что раньше
+		{"0 12 15 * 1", time.Date(2025, time.February, 15, 12, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		s, err := Parse(tt.expr)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.want, s.Next(base), tt.expr)
+	}
+}
+
+func TestNextImpossible(t *testing.T) {
+	s, err := Parse("0 0 30 2 *")
+	assert.NoError(t, err)
+	assert.True(t, s.Next(time.Now()).IsZero())
+}
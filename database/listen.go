@@ -0,0 +1,57 @@
+package database
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Пауза перед повторным подключением, если соединение с LISTEN оборвалось
+const listenRetryDelay = 2 * time.Second
+
+// Listen держит отдельное соединение с LISTEN channel и передаёт fn каждое уведомление,
+// пока не отменён ctx. После обрыва переподключается: уведомления за время обрыва теряются
+func Listen(ctx context.Context, pool *pgxpool.Pool, channel string, fn func(payload string)) {
+	slog.Info("Listener started", "channel", channel)
+	for {
+		err := listen(ctx, pool, channel, fn)
+		if ctx.Err() != nil {
+			slog.Info("Listener stopped", "channel", channel)
+			return
+		}
+		slog.Error("Listener failed, reconnecting", "channel", channel, "error", err)
+
+		select {
+		case <-ctx.Done():
+			slog.Info("Listener stopped", "channel", channel)
+			return
+		case <-time.After(listenRetryDelay):
+		}
+	}
+}
+
+func listen(ctx context.Context, pool *pgxpool.Pool, channel string, fn func(payload string)) error {
+	pooled, err := pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	// После LISTEN соединение нельзя возвращать в пул как обычное, поэтому оно забирается из пула
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background())
+
+	_, err = conn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize())
+	if err != nil {
+		return err
+	}
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		fn(n.Payload)
+	}
+}
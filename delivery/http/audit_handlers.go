@@ -0,0 +1,28 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"ttavito/domain/entities"
+	"ttavito/internal"
+)
+
+func AuditEventsHandler(uc UsecaseShop) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, ok := r.Context().Value(internal.ValidAuditQueryKey).(entities.AuditQuery)
+		if !ok {
+			http.Error(w, "Invalid request", http.StatusInternalServerError)
+			return
+		}
+
+		res, err := uc.GetAuditEvents(r.Context(), req)
+		if err != nil {
+			http.Error(w, "Can't get audit events", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(res)
+	}
+}
@@ -0,0 +1,38 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ttavito/domain/entities"
+	"ttavito/internal"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAuditEventsHandler(t *testing.T) {
+	mockUsecase := new(MockUsecase)
+	query := entities.AuditQuery{Actor: "admin", Limit: 1}
+	next := int64(7)
+	mockUsecase.On("GetAuditEvents", mock.Anything, query).Return(&entities.AuditEventsResponse{
+		Events: []entities.AuditEvent{{
+			ID: 7, Actor: "admin", Action: entities.AuditAdminGrant, Target: "bob",
+			Payload: json.RawMessage(`{"amount": 100}`), RequestID: "req-1", ClientIP: "192.0.2.1",
+		}},
+		NextBeforeID: &next,
+	}, nil)
+
+	req := httptest.NewRequest("GET", "/api/admin/audit?actor=admin&limit=1", nil)
+	ctx := context.WithValue(req.Context(), internal.ValidAuditQueryKey, query)
+	rr := httptest.NewRecorder()
+	AuditEventsHandler(mockUsecase).ServeHTTP(rr, req.WithContext(ctx))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"payload":{"amount":100}`)
+	assert.Contains(t, rr.Body.String(), `"nextBeforeId":7`)
+	mockUsecase.AssertExpectations(t)
+}
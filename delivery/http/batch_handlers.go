@@ -0,0 +1,40 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"ttavito/domain/entities"
+	"ttavito/internal"
+)
+
+func BatchSendCoinHandler(uc UsecaseShop) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, ok := r.Context().Value(internal.ValidBatchSendKey).(entities.BatchSendCoinRequest)
+		if !ok {
+			http.Error(w, "Invalid request", http.StatusInternalServerError)
+			return
+		}
+
+		username, ok := r.Context().Value(internal.UsernameContextKey).(string)
+		if !ok {
+			http.Error(w, "Can't grab username from JWT", http.StatusInternalServerError)
+			return
+		}
+
+		res, err := uc.BatchSendCoin(r.Context(), username, req)
+		if err != nil && res == nil {
+			http.Error(w, "Can't send coins", http.StatusInternalServerError)
+			return
+		}
+
+		status := http.StatusOK
+		if err != nil {
+			status = transferErrorStatus(err)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(res)
+	}
+}
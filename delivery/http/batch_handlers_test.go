@@ -0,0 +1,83 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ttavito/domain/entities"
+	"ttavito/internal"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestBatchSendCoinHandler_Success(t *testing.T) {
+	batchRequest := entities.BatchSendCoinRequest{Transfers: []entities.SendCoinRequest{
+		{ToUser: "user1", Amount: 10},
+		{ToUser: "user2", Amount: 10},
+	}}
+
+	mockUsecase := new(MockUsecase)
+	mockUsecase.On("BatchSendCoin", mock.Anything, "test_user", batchRequest).Return(&entities.BatchSendCoinResponse{
+		Results: []entities.BatchTransferResult{
+			{ToUser: "user1", Amount: 10, Status: entities.BatchStatusSent, TransferID: "0b6b1f8e-3f43-4a53-9a4c-2f0f6b0d8f11"},
+			{ToUser: "user2", Amount: 10, Status: entities.BatchStatusSent, TransferID: "5d1c2e64-8c5e-4f4a-bd0e-2b0c8a1f7c22"},
+		},
+		Total: 20,
+	}, nil)
+
+	req := httptest.NewRequest("POST", "/api/sendCoin/batch", nil)
+	req = req.WithContext(context.WithValue(req.Context(), internal.UsernameContextKey, "test_user"))
+	req = req.WithContext(context.WithValue(req.Context(), internal.ValidBatchSendKey, batchRequest))
+
+	rr := httptest.NewRecorder()
+	handler := BatchSendCoinHandler(mockUsecase)
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var response entities.BatchSendCoinResponse
+	err := json.NewDecoder(rr.Body).Decode(&response)
+	assert.NoError(t, err)
+	assert.Equal(t, 20, response.Total)
+	assert.Len(t, response.Results, 2)
+
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestBatchSendCoinHandler_RolledBack(t *testing.T) {
+	batchRequest := entities.BatchSendCoinRequest{Transfers: []entities.SendCoinRequest{
+		{ToUser: "user1", Amount: 10},
+		{ToUser: "ghost", Amount: 10},
+	}}
+
+	mockUsecase := new(MockUsecase)
+	mockUsecase.On("BatchSendCoin", mock.Anything, "test_user", batchRequest).Return(&entities.BatchSendCoinResponse{
+		Results: []entities.BatchTransferResult{
+			{ToUser: "user1", Amount: 10, Status: entities.BatchStatusRolledBack},
+			{ToUser: "ghost", Amount: 10, Status: entities.BatchStatusFailed, Error: entities.ErrUserNotFound.Error()},
+		},
+	}, fmt.Errorf("transfer to ghost failed: %w", entities.ErrUserNotFound))
+
+	req := httptest.NewRequest("POST", "/api/sendCoin/batch", nil)
+	req = req.WithContext(context.WithValue(req.Context(), internal.UsernameContextKey, "test_user"))
+	req = req.WithContext(context.WithValue(req.Context(), internal.ValidBatchSendKey, batchRequest))
+
+	rr := httptest.NewRecorder()
+	handler := BatchSendCoinHandler(mockUsecase)
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	var response entities.BatchSendCoinResponse
+	err := json.NewDecoder(rr.Body).Decode(&response)
+	assert.NoError(t, err)
+	assert.Equal(t, entities.BatchStatusRolledBack, response.Results[0].Status)
+	assert.Equal(t, entities.BatchStatusFailed, response.Results[1].Status)
+
+	mockUsecase.AssertExpectations(t)
+}
@@ -0,0 +1,162 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"ttavito/domain/entities"
+	"ttavito/internal"
+)
+
+func writeDepartmentError(w http.ResponseWriter, err error) {
+	if writeFrozenError(w, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, entities.ErrDepartmentNotFound), errors.Is(err, entities.ErrUserNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, entities.ErrNotDepartmentManager):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, entities.ErrDepartmentExists), errors.Is(err, entities.ErrBudgetExhausted):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, entities.ErrNotDepartmentMember), errors.Is(err, entities.ErrTransferToSelf),
+		errors.Is(err, entities.ErrUserDeactivated):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, "Can't process department request", http.StatusInternalServerError)
+	}
+}
+
+func CreateDepartmentHandler(uc UsecaseShop) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, ok := r.Context().Value(internal.ValidDepartmentKey).(entities.DepartmentRequest)
+		if !ok {
+			http.Error(w, "Invalid request", http.StatusInternalServerError)
+			return
+		}
+
+		admin, ok := r.Context().Value(internal.UsernameContextKey).(string)
+		if !ok {
+			http.Error(w, "Can't grab username from JWT", http.StatusInternalServerError)
+			return
+		}
+
+		res, err := uc.CreateDepartment(r.Context(), admin, req)
+		if err != nil {
+			writeDepartmentError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(res)
+	}
+}
+
+func AddDepartmentMembersHandler(uc UsecaseShop) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, ok := r.Context().Value(internal.ValidMembersKey).(entities.DepartmentMembersRequest)
+		if !ok {
+			http.Error(w, "Invalid request", http.StatusInternalServerError)
+			return
+		}
+
+		admin, ok := r.Context().Value(internal.UsernameContextKey).(string)
+		if !ok {
+			http.Error(w, "Can't grab username from JWT", http.StatusInternalServerError)
+			return
+		}
+
+		res, err := uc.AddDepartmentMembers(r.Context(), admin, req)
+		if err != nil {
+			writeDepartmentError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(res)
+	}
+}
+
+func AllocateDepartmentBudgetHandler(uc UsecaseShop) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, ok := r.Context().Value(internal.ValidAllocationKey).(entities.BudgetAllocationRequest)
+		if !ok {
+			http.Error(w, "Invalid request", http.StatusInternalServerError)
+			return
+		}
+
+		admin, ok := r.Context().Value(internal.UsernameContextKey).(string)
+		if !ok {
+			http.Error(w, "Can't grab username from JWT", http.StatusInternalServerError)
+			return
+		}
+
+		res, err := uc.AllocateDepartmentBudget(r.Context(), admin, req)
+		if err != nil {
+			writeDepartmentError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(res)
+	}
+}
+
+func RecognizeFromBudgetHandler(uc UsecaseShop) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, ok := r.Context().Value(internal.ValidRecognizeKey).(entities.RecognitionRequest)
+		if !ok {
+			http.Error(w, "Invalid request", http.StatusInternalServerError)
+			return
+		}
+
+		username, ok := r.Context().Value(internal.UsernameContextKey).(string)
+		if !ok {
+			http.Error(w, "Can't grab username from JWT", http.StatusInternalServerError)
+			return
+		}
+
+		res, err := uc.RecognizeFromBudget(r.Context(), username, req)
+		if err != nil {
+			writeDepartmentError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(res)
+	}
+}
+
+// DepartmentBudgetReportHandler отдаёт отчёт руководителю отдела и администраторам
+func DepartmentBudgetReportHandler(uc UsecaseShop) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, ok := r.Context().Value(internal.ValidReportKey).(entities.DepartmentReportRequest)
+		if !ok {
+			http.Error(w, "Invalid request", http.StatusInternalServerError)
+			return
+		}
+
+		username, ok := r.Context().Value(internal.UsernameContextKey).(string)
+		if !ok {
+			http.Error(w, "Can't grab username from JWT", http.StatusInternalServerError)
+			return
+		}
+
+		res, err := uc.GetDepartmentBudgetReport(r.Context(), req)
+		if err != nil {
+			writeDepartmentError(w, err)
+			return
+		}
+
+		if res.Manager != username && !internal.IsAdmin(username) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(res)
+	}
+}
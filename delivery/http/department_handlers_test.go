@@ -0,0 +1,94 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"ttavito/domain/entities"
+	"ttavito/internal"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRecognizeFromBudgetHandler_BudgetExhausted(t *testing.T) {
+	mockUsecase := new(MockUsecase)
+	recognitionReq := entities.RecognitionRequest{Department: "platform", ToUser: "dev", Amount: 500}
+	mockUsecase.On("RecognizeFromBudget", mock.Anything, "lead", recognitionReq).
+		Return((*entities.Recognition)(nil), entities.ErrBudgetExhausted)
+
+	req := httptest.NewRequest("POST", "/api/departments/platform/recognitions", nil)
+	req = req.WithContext(context.WithValue(req.Context(), internal.UsernameContextKey, "lead"))
+	req = req.WithContext(context.WithValue(req.Context(), internal.ValidRecognizeKey, recognitionReq))
+
+	rr := httptest.NewRecorder()
+	RecognizeFromBudgetHandler(mockUsecase).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestRecognizeFromBudgetHandler_RecipientFrozen(t *testing.T) {
+	mockUsecase := new(MockUsecase)
+	recognitionReq := entities.RecognitionRequest{Department: "platform", ToUser: "dev", Amount: 500}
+	mockUsecase.On("RecognizeFromBudget", mock.Anything, "lead", recognitionReq).
+		Return((*entities.Recognition)(nil), entities.ErrRecipientFrozen)
+
+	req := httptest.NewRequest("POST", "/api/departments/platform/recognitions", nil)
+	req = req.WithContext(context.WithValue(req.Context(), internal.UsernameContextKey, "lead"))
+	req = req.WithContext(context.WithValue(req.Context(), internal.ValidRecognizeKey, recognitionReq))
+
+	rr := httptest.NewRecorder()
+	RecognizeFromBudgetHandler(mockUsecase).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+
+	var response entities.ErrorResponse
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	assert.Equal(t, entities.FrozenCodeRecipient, response.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestDepartmentBudgetReportHandler(t *testing.T) {
+	reportReq := entities.DepartmentReportRequest{
+		Department: "platform",
+		Month:      time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC),
+	}
+	report := &entities.DepartmentBudgetReport{
+		Department: "platform",
+		Manager:    "lead",
+		Month:      "2025-02",
+		Allocated:  1000,
+		Spent:      300,
+		Remaining:  700,
+	}
+
+	tests := []struct {
+		username string
+		code     int
+	}{
+		{"lead", http.StatusOK},
+		{"dev", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.username, func(t *testing.T) {
+			mockUsecase := new(MockUsecase)
+			mockUsecase.On("GetDepartmentBudgetReport", mock.Anything, reportReq).Return(report, nil)
+
+			req := httptest.NewRequest("GET", "/api/departments/platform/report?month=2025-02", nil)
+			req = req.WithContext(context.WithValue(req.Context(), internal.UsernameContextKey, tt.username))
+			req = req.WithContext(context.WithValue(req.Context(), internal.ValidReportKey, reportReq))
+
+			rr := httptest.NewRecorder()
+			DepartmentBudgetReportHandler(mockUsecase).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.code, rr.Code)
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"ttavito/domain/entities"
+	"ttavito/internal"
+)
+
+func GetDigestSettingsHandler(uc UsecaseShop) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, ok := r.Context().Value(internal.UsernameContextKey).(string)
+		if !ok {
+			http.Error(w, "Can't grab username from JWT", http.StatusInternalServerError)
+			return
+		}
+
+		res, err := uc.GetDigestSettings(r.Context(), username)
+		if err != nil {
+			http.Error(w, "Can't get digest settings", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(res)
+	}
+}
+
+func UpdateDigestSettingsHandler(uc UsecaseShop) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, ok := r.Context().Value(internal.ValidDigestKey).(entities.DigestSettings)
+		if !ok {
+			http.Error(w, "Invalid request", http.StatusInternalServerError)
+			return
+		}
+
+		username, ok := r.Context().Value(internal.UsernameContextKey).(string)
+		if !ok {
+			http.Error(w, "Can't grab username from JWT", http.StatusInternalServerError)
+			return
+		}
+
+		res, err := uc.UpdateDigestSettings(r.Context(), username, req)
+		if err != nil {
+			http.Error(w, "Can't update digest settings", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(res)
+	}
+}
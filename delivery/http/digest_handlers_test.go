@@ -0,0 +1,30 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ttavito/domain/entities"
+	"ttavito/internal"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestUpdateDigestSettingsHandler(t *testing.T) {
+	mockUsecase := new(MockUsecase)
+	mockUsecase.On("UpdateDigestSettings", mock.Anything, "alice", entities.DigestSettings{Enabled: false}).
+		Return(&entities.DigestSettings{Enabled: false, Email: "alice@example.com"}, nil)
+
+	req := httptest.NewRequest("PUT", "/api/me/digest", nil)
+	ctx := context.WithValue(req.Context(), internal.ValidDigestKey, entities.DigestSettings{Enabled: false})
+	ctx = context.WithValue(ctx, internal.UsernameContextKey, "alice")
+	rr := httptest.NewRecorder()
+	UpdateDigestSettingsHandler(mockUsecase).ServeHTTP(rr, req.WithContext(ctx))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"enabled":false,"email":"alice@example.com"}`, rr.Body.String())
+	mockUsecase.AssertExpectations(t)
+}
@@ -0,0 +1,32 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"ttavito/domain/entities"
+)
+
+func GetTeamHandler(uc UsecaseShop) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		team := r.PathValue("team")
+		if team == "" {
+			http.Error(w, "Invalid input data", http.StatusBadRequest)
+			return
+		}
+
+		res, err := uc.GetTeam(r.Context(), team)
+		if err != nil {
+			if errors.Is(err, entities.ErrTeamNotFound) {
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			}
+			http.Error(w, "Can't get team", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(res)
+	}
+}
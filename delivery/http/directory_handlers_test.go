@@ -0,0 +1,39 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ttavito/domain/entities"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetTeamHandler(t *testing.T) {
+	mockUsecase := new(MockUsecase)
+	team := &entities.Team{Name: "core", Department: "platform", Lead: "bob", Members: []string{"bob", "carol"}}
+	mockUsecase.On("GetTeam", mock.Anything, "core").Return(team, nil)
+	mockUsecase.On("GetTeam", mock.Anything, "ghosts").Return((*entities.Team)(nil), entities.ErrTeamNotFound)
+
+	req := httptest.NewRequest("GET", "/api/teams/core", nil)
+	req.SetPathValue("team", "core")
+	rr := httptest.NewRecorder()
+	GetTeamHandler(mockUsecase).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var response entities.Team
+	err := json.NewDecoder(rr.Body).Decode(&response)
+	assert.NoError(t, err)
+	assert.Equal(t, *team, response)
+
+	req = httptest.NewRequest("GET", "/api/teams/ghosts", nil)
+	req.SetPathValue("team", "ghosts")
+	rr = httptest.NewRecorder()
+	GetTeamHandler(mockUsecase).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	mockUsecase.AssertExpectations(t)
+}
@@ -0,0 +1,96 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"ttavito/domain/entities"
+	"ttavito/internal"
+
+	"github.com/coder/websocket"
+)
+
+// Интервал комментария-пинга в потоке событий: не даёт прокси закрыть молчащее соединение
+var eventsHeartbeat = 25 * time.Second
+
+// EventsStreamHandler отдаёт события пользователя в формате Server-Sent Events.
+// Поток закрывается, если клиент не успевает читать, браузер сам переподключится
+func EventsStreamHandler(uc UsecaseShop) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, ok := r.Context().Value(internal.UsernameContextKey).(string)
+		if !ok {
+			http.Error(w, "Can't grab username from JWT", http.StatusInternalServerError)
+			return
+		}
+
+		events, cancel, err := uc.SubscribeUserEvents(username)
+		if err != nil {
+			switch {
+			case errors.Is(err, entities.ErrTooManyEventStreams):
+				http.Error(w, err.Error(), http.StatusTooManyRequests)
+			default:
+				http.Error(w, "Event stream is not available", http.StatusServiceUnavailable)
+			}
+			return
+		}
+		defer cancel()
+
+		// Общий WriteTimeout сервера оборвал бы поток
+		rc := http.NewResponseController(w)
+		rc.SetWriteDeadline(time.Time{})
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, "retry: 3000\n\n")
+		if err := rc.Flush(); err != nil {
+			return
+		}
+
+		heartbeat := time.NewTicker(eventsHeartbeat)
+		defer heartbeat.Stop()
+
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-heartbeat.C:
+				fmt.Fprint(w, ": ping\n\n")
+			case e, ok := <-events:
+				if !ok {
+					return
+				}
+				fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Type, e.Data)
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// EventsSocketHandler открывает WebSocket с подписками на кошелёк и ленту благодарностей.
+// Сессия закрывается, когда истекает токен
+func EventsSocketHandler(uc UsecaseShop) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, ok := r.Context().Value(internal.UsernameContextKey).(string)
+		if !ok {
+			http.Error(w, "Can't grab username from JWT", http.StatusInternalServerError)
+			return
+		}
+		expiresAt, _ := r.Context().Value(internal.TokenExpiresContextKey).(time.Time)
+
+		conn, err := websocket.Accept(w, r, &websocket.AcceptOptions{
+			Subprotocols: []string{internal.WebSocketTokenProtocol},
+		})
+		if err != nil {
+			return
+		}
+		if err := uc.ServeEventSocket(r.Context(), conn, username, expiresAt); err != nil {
+			conn.Close(websocket.StatusInternalError, "event stream is not available")
+		}
+	}
+}
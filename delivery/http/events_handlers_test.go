@@ -0,0 +1,98 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"ttavito/domain/entities"
+	"ttavito/internal"
+
+	"github.com/coder/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestEventsStreamHandler(t *testing.T) {
+	mockUsecase := new(MockUsecase)
+	events := make(chan entities.UserEvent, 1)
+	cancelled := false
+	mockUsecase.On("SubscribeUserEvents", "alice").Return(events, func() { cancelled = true }, nil)
+
+	events <- entities.UserEvent{
+		Username: "alice",
+		Type:     entities.UserEventBalanceUpdated,
+		Data:     json.RawMessage(`{"balance":900}`),
+	}
+	close(events)
+
+	req := httptest.NewRequest("GET", "/api/events", nil)
+	ctx := context.WithValue(req.Context(), internal.UsernameContextKey, "alice")
+	rr := httptest.NewRecorder()
+	EventsStreamHandler(mockUsecase).ServeHTTP(rr, req.WithContext(ctx))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "text/event-stream", rr.Header().Get("Content-Type"))
+	assert.Contains(t, rr.Body.String(), "event: balance.updated\ndata: {\"balance\":900}\n\n")
+	assert.True(t, cancelled)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestEventsStreamHandlerTooManyStreams(t *testing.T) {
+	mockUsecase := new(MockUsecase)
+	mockUsecase.On("SubscribeUserEvents", "alice").Return(nil, nil, entities.ErrTooManyEventStreams)
+
+	req := httptest.NewRequest("GET", "/api/events", nil)
+	ctx := context.WithValue(req.Context(), internal.UsernameContextKey, "alice")
+	rr := httptest.NewRecorder()
+	EventsStreamHandler(mockUsecase).ServeHTTP(rr, req.WithContext(ctx))
+
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestEventsSocketHandlerUnavailable(t *testing.T) {
+	mockUsecase := new(MockUsecase)
+	mockUsecase.On("ServeEventSocket", mock.Anything, mock.Anything, "alice", time.Time{}).
+		Return(entities.ErrEventStreamUnavailable)
+
+	// Мок читает соединение из аргументов, поэтому проверяем его после выхода из обработчика
+	handled := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer close(handled)
+		ctx := context.WithValue(r.Context(), internal.UsernameContextKey, "alice")
+		EventsSocketHandler(mockUsecase).ServeHTTP(w, r.WithContext(ctx))
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, _, err := websocket.Dial(ctx, server.URL+"/api/ws", nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer conn.CloseNow()
+
+	_, _, err = conn.Read(ctx)
+	var closeErr websocket.CloseError
+	if assert.ErrorAs(t, err, &closeErr) {
+		assert.Equal(t, websocket.StatusInternalError, closeErr.Code)
+	}
+	<-handled
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestEventsSocketHandlerRequiresUpgrade(t *testing.T) {
+	mockUsecase := new(MockUsecase)
+
+	req := httptest.NewRequest("GET", "/api/ws", nil)
+	ctx := context.WithValue(req.Context(), internal.UsernameContextKey, "alice")
+	rr := httptest.NewRecorder()
+	EventsSocketHandler(mockUsecase).ServeHTTP(rr, req.WithContext(ctx))
+
+	assert.Equal(t, http.StatusUpgradeRequired, rr.Code)
+	mockUsecase.AssertNotCalled(t, "ServeEventSocket", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
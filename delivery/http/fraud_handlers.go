@@ -0,0 +1,61 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"ttavito/domain/entities"
+	"ttavito/internal"
+)
+
+func FraudFindingsHandler(uc UsecaseShop) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, ok := r.Context().Value(internal.ValidFindingsKey).(entities.FraudFindingsRequest)
+		if !ok {
+			http.Error(w, "Invalid request", http.StatusInternalServerError)
+			return
+		}
+
+		res, err := uc.GetFraudFindings(r.Context(), req)
+		if err != nil {
+			http.Error(w, "Can't get fraud findings", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(res)
+	}
+}
+
+func ReviewFraudFindingHandler(uc UsecaseShop) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, ok := r.Context().Value(internal.ValidReviewKey).(entities.FraudReviewRequest)
+		if !ok {
+			http.Error(w, "Invalid request", http.StatusInternalServerError)
+			return
+		}
+
+		admin, ok := r.Context().Value(internal.UsernameContextKey).(string)
+		if !ok {
+			http.Error(w, "Can't grab username from JWT", http.StatusInternalServerError)
+			return
+		}
+
+		res, err := uc.ReviewFraudFinding(r.Context(), admin, req)
+		if err != nil {
+			switch {
+			case errors.Is(err, entities.ErrFraudFindingNotFound):
+				http.Error(w, err.Error(), http.StatusNotFound)
+			case errors.Is(err, entities.ErrFraudFindingReviewed):
+				http.Error(w, err.Error(), http.StatusConflict)
+			default:
+				http.Error(w, "Can't review fraud finding", http.StatusInternalServerError)
+			}
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(res)
+	}
+}
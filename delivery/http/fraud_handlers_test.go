@@ -0,0 +1,65 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ttavito/domain/entities"
+	"ttavito/internal"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestFraudFindingsHandler(t *testing.T) {
+	mockUsecase := new(MockUsecase)
+	filter := entities.FraudFindingsRequest{Status: entities.FraudStatusOpen, Limit: 50}
+	mockUsecase.On("GetFraudFindings", mock.Anything, filter).Return(&entities.FraudFindingsResponse{
+		Findings: []entities.FraudFinding{{ID: "f-1", Kind: entities.FraudKindFanIn, Username: "boss", Status: entities.FraudStatusOpen}},
+	}, nil)
+
+	req := httptest.NewRequest("GET", "/api/admin/fraud/findings?status=open", nil)
+	ctx := context.WithValue(req.Context(), internal.ValidFindingsKey, filter)
+	rr := httptest.NewRecorder()
+	FraudFindingsHandler(mockUsecase).ServeHTTP(rr, req.WithContext(ctx))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"kind":"fan_in"`)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestReviewFraudFindingHandler(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{"reviewed", nil, http.StatusOK},
+		{"not found", entities.ErrFraudFindingNotFound, http.StatusNotFound},
+		{"already reviewed", entities.ErrFraudFindingReviewed, http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			review := entities.FraudReviewRequest{ID: "f-1", Status: entities.FraudStatusDismissed}
+			var finding *entities.FraudFinding
+			if tt.err == nil {
+				finding = &entities.FraudFinding{ID: "f-1", Status: entities.FraudStatusDismissed, ReviewedBy: "admin"}
+			}
+
+			mockUsecase := new(MockUsecase)
+			mockUsecase.On("ReviewFraudFinding", mock.Anything, "admin", review).Return(finding, tt.err)
+
+			req := httptest.NewRequest("POST", "/api/admin/fraud/findings/f-1/review", nil)
+			ctx := context.WithValue(req.Context(), internal.ValidReviewKey, review)
+			ctx = context.WithValue(ctx, internal.UsernameContextKey, "admin")
+			rr := httptest.NewRecorder()
+			ReviewFraudFindingHandler(mockUsecase).ServeHTTP(rr, req.WithContext(ctx))
+
+			assert.Equal(t, tt.code, rr.Code)
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ttavito/domain/entities"
+	"ttavito/internal"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestFreezeAccountHandler(t *testing.T) {
+	mockUsecase := new(MockUsecase)
+	freeze := entities.FreezeRequest{Username: "suspect", Reason: "проверка"}
+	mockUsecase.On("FreezeAccount", mock.Anything, "admin", freeze).
+		Return(&entities.AccountFreeze{ID: "f-1", Username: "suspect", Reason: "проверка", FrozenBy: "admin"}, nil).Once()
+	mockUsecase.On("FreezeAccount", mock.Anything, "admin", freeze).
+		Return((*entities.AccountFreeze)(nil), entities.ErrAlreadyFrozen).Once()
+
+	send := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/admin/users/suspect/freeze", nil)
+		ctx := context.WithValue(req.Context(), internal.ValidFreezeKey, freeze)
+		ctx = context.WithValue(ctx, internal.UsernameContextKey, "admin")
+		rr := httptest.NewRecorder()
+		FreezeAccountHandler(mockUsecase).ServeHTTP(rr, req.WithContext(ctx))
+		return rr
+	}
+
+	assert.Equal(t, http.StatusCreated, send().Code)
+	assert.Equal(t, http.StatusConflict, send().Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestFrozenAccountErrors(t *testing.T) {
+	mockUsecase := new(MockUsecase)
+	mockUsecase.On("BuyItem", mock.Anything, "suspect", "cup").Return(entities.ErrAccountFrozen)
+	mockUsecase.On("SendCoin", mock.Anything, "bob", "suspect", 10, "").Return(entities.ErrRecipientFrozen)
+
+	req := httptest.NewRequest("GET", "/api/buy/cup", nil)
+	ctx := context.WithValue(req.Context(), internal.ValidBuyItemKey, "cup")
+	ctx = context.WithValue(ctx, internal.UsernameContextKey, "suspect")
+	rr := httptest.NewRecorder()
+	BuyItemHandler(mockUsecase).ServeHTTP(rr, req.WithContext(ctx))
+
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	assert.JSONEq(t, `{"errors": "account is frozen", "code": "account_frozen"}`, rr.Body.String())
+
+	req = httptest.NewRequest("POST", "/api/sendCoin", nil)
+	ctx = context.WithValue(req.Context(), internal.ValidSendCoinKey, entities.SendCoinRequest{ToUser: "suspect", Amount: 10})
+	ctx = context.WithValue(ctx, internal.UsernameContextKey, "bob")
+	rr = httptest.NewRecorder()
+	SendCoinHandler(mockUsecase).ServeHTTP(rr, req.WithContext(ctx))
+
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	assert.JSONEq(t, `{"errors": "recipient account is frozen", "code": "recipient_frozen"}`, rr.Body.String())
+	mockUsecase.AssertExpectations(t)
+}
@@ -0,0 +1,42 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"ttavito/domain/entities"
+	"ttavito/internal"
+)
+
+func GiftItemHandler(uc UsecaseShop) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, ok := r.Context().Value(internal.ValidGiftKey).(entities.GiftRequest)
+		if !ok {
+			http.Error(w, "Invalid request", http.StatusInternalServerError)
+			return
+		}
+
+		username, ok := r.Context().Value(internal.UsernameContextKey).(string)
+		if !ok {
+			http.Error(w, "Can't grab username from JWT", http.StatusInternalServerError)
+			return
+		}
+
+		err := uc.GiftItem(r.Context(), username, req)
+		if err != nil {
+			if writeFrozenError(w, err) {
+				return
+			}
+			switch {
+			case errors.Is(err, entities.ErrUserNotFound), errors.Is(err, entities.ErrProductNotFound):
+				http.Error(w, err.Error(), http.StatusNotFound)
+			case errors.Is(err, entities.ErrGiftToSelf), errors.Is(err, entities.ErrNotEnoughBalance),
+				errors.Is(err, entities.ErrUserDeactivated):
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			default:
+				http.Error(w, "Can't buy gift", http.StatusInternalServerError)
+			}
+			return
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ttavito/domain/entities"
+	"ttavito/internal"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGiftItemHandler_Success(t *testing.T) {
+	giftRequest := entities.GiftRequest{Item: "cup", ToUser: "recipient_user", Message: "Спасибо!"}
+
+	mockUsecase := new(MockUsecase)
+	mockUsecase.On("GiftItem", mock.Anything, "test_user", giftRequest).Return(nil)
+
+	req := httptest.NewRequest("POST", "/api/buy/cup/gift", nil)
+	req = req.WithContext(context.WithValue(req.Context(), internal.UsernameContextKey, "test_user"))
+	req = req.WithContext(context.WithValue(req.Context(), internal.ValidGiftKey, giftRequest))
+
+	rr := httptest.NewRecorder()
+	handler := GiftItemHandler(mockUsecase)
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestGiftItemHandler_UnknownRecipient(t *testing.T) {
+	giftRequest := entities.GiftRequest{Item: "cup", ToUser: "ghost"}
+
+	mockUsecase := new(MockUsecase)
+	mockUsecase.On("GiftItem", mock.Anything, "test_user", giftRequest).Return(entities.ErrUserNotFound)
+
+	req := httptest.NewRequest("POST", "/api/buy/cup/gift", nil)
+	req = req.WithContext(context.WithValue(req.Context(), internal.UsernameContextKey, "test_user"))
+	req = req.WithContext(context.WithValue(req.Context(), internal.ValidGiftKey, giftRequest))
+
+	rr := httptest.NewRecorder()
+	handler := GiftItemHandler(mockUsecase)
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	mockUsecase.AssertExpectations(t)
+}
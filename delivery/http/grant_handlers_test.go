@@ -0,0 +1,80 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ttavito/domain/entities"
+	"ttavito/internal"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGrantCoinsHandler_UserNotFound(t *testing.T) {
+	mockUsecase := new(MockUsecase)
+	grantReq := entities.GrantRequest{ToUser: "ghost", Amount: 100, Reason: "hackathon"}
+	mockUsecase.On("GrantCoins", mock.Anything, "admin", grantReq).
+		Return((*entities.Grant)(nil), entities.ErrUserNotFound)
+
+	req := httptest.NewRequest("POST", "/api/admin/grants", nil)
+	req = req.WithContext(context.WithValue(req.Context(), internal.UsernameContextKey, "admin"))
+	req = req.WithContext(context.WithValue(req.Context(), internal.ValidGrantKey, grantReq))
+
+	rr := httptest.NewRecorder()
+	GrantCoinsHandler(mockUsecase).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestGrantCoinsHandler_AmountTooLarge(t *testing.T) {
+	mockUsecase := new(MockUsecase)
+	grantReq := entities.GrantRequest{ToUser: "user1", Amount: 1000000, Reason: "typo"}
+	mockUsecase.On("GrantCoins", mock.Anything, "admin", grantReq).
+		Return((*entities.Grant)(nil), fmt.Errorf("%w of %d", entities.ErrGrantAmountTooLarge, 10000))
+
+	req := httptest.NewRequest("POST", "/api/admin/grants", nil)
+	req = req.WithContext(context.WithValue(req.Context(), internal.UsernameContextKey, "admin"))
+	req = req.WithContext(context.WithValue(req.Context(), internal.ValidGrantKey, grantReq))
+
+	rr := httptest.NewRecorder()
+	GrantCoinsHandler(mockUsecase).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), "grant amount exceeds the maximum of 10000")
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestBulkGrantCoinsHandler_InvalidRows(t *testing.T) {
+	mockUsecase := new(MockUsecase)
+	bulkReq := entities.BulkGrantRequest{Rows: []entities.BulkGrantRow{
+		{Line: 2, ToUser: "user1", Amount: 100, Reason: "release"},
+		{Line: 3, ToUser: "ghost", Amount: 50, Reason: "release"},
+	}}
+	report := &entities.BulkGrantResponse{Rows: []entities.BulkGrantRow{
+		{Line: 2, ToUser: "user1", Amount: 100, Reason: "release", Status: entities.GrantRowValid},
+		{Line: 3, ToUser: "ghost", Amount: 50, Reason: "release", Status: entities.GrantRowUnknownUser},
+	}}
+	mockUsecase.On("BulkGrantCoins", mock.Anything, "admin", bulkReq).Return(report, entities.ErrBulkGrantInvalid)
+
+	req := httptest.NewRequest("POST", "/api/admin/grants/bulk", nil)
+	req = req.WithContext(context.WithValue(req.Context(), internal.UsernameContextKey, "admin"))
+	req = req.WithContext(context.WithValue(req.Context(), internal.ValidBulkGrantKey, bulkReq))
+
+	rr := httptest.NewRecorder()
+	BulkGrantCoinsHandler(mockUsecase).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+
+	var response entities.BulkGrantResponse
+	err := json.NewDecoder(rr.Body).Decode(&response)
+	assert.NoError(t, err)
+	assert.Equal(t, entities.GrantRowUnknownUser, response.Rows[1].Status)
+
+	mockUsecase.AssertExpectations(t)
+}
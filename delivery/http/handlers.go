@@ -2,6 +2,7 @@ package http
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"ttavito/domain/entities"
@@ -38,14 +39,102 @@ func SendCoinHandler(uc UsecaseShop) http.HandlerFunc {
 			http.Error(w, "Can't grab username from JWT", http.StatusInternalServerError)
 			return
 		}
-		err := uc.SendCoin(r.Context(), username, req.ToUser, req.Amount)
+		if req.RequireAcceptance {
+			res, err := uc.SendPendingCoin(r.Context(), username, req)
+			if err != nil {
+				writePendingTransferError(w, err)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusAccepted)
+			json.NewEncoder(w).Encode(res)
+			return
+		}
+
+		err := uc.SendCoin(r.Context(), username, req.ToUser, req.Amount, req.Message)
 		if err != nil {
-			http.Error(w, "Can't send coins", http.StatusInternalServerError)
+			writeTransferError(w, err, "Can't send coins")
 			return
 		}
 	}
 }
 
+// writePolicyViolation отдаёт код нарушенного правила, чтобы клиент мог показать понятную причину
+func writePolicyViolation(w http.ResponseWriter, violation *entities.PolicyViolation) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(policyViolationStatus(violation))
+	json.NewEncoder(w).Encode(entities.ErrorResponse{
+		Errors: violation.Message,
+		Code:   violation.Code,
+	})
+}
+
+// writeFrozenError отвечает 403 с отдельным кодом, чтобы клиент отличал заморозку от прочих отказов
+func writeFrozenError(w http.ResponseWriter, err error) bool {
+	code := ""
+	switch {
+	case errors.Is(err, entities.ErrAccountFrozen):
+		code = entities.FrozenCodeAccount
+	case errors.Is(err, entities.ErrRecipientFrozen):
+		code = entities.FrozenCodeRecipient
+	default:
+		return false
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusForbidden)
+	json.NewEncoder(w).Encode(entities.ErrorResponse{Errors: err.Error(), Code: code})
+	return true
+}
+
+// transferErrorStatus - HTTP-статус ошибки перевода, общий для всех способов отправить монеты
+func transferErrorStatus(err error) int {
+	var violation *entities.PolicyViolation
+	switch {
+	case errors.As(err, &violation):
+		return policyViolationStatus(violation)
+	case errors.Is(err, entities.ErrAccountFrozen), errors.Is(err, entities.ErrRecipientFrozen):
+		return http.StatusForbidden
+	case errors.Is(err, entities.ErrPendingTransferNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, entities.ErrPendingTransferResolved), errors.Is(err, entities.ErrPendingTransferExpired):
+		return http.StatusConflict
+	case errors.Is(err, entities.ErrNotEnoughBalance),
+		errors.Is(err, entities.ErrUserNotFound),
+		errors.Is(err, entities.ErrUserDeactivated),
+		errors.Is(err, entities.ErrTransferToSelf):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// writeTransferError отвечает на ошибку перевода. Внутренние ошибки не раскрываются, вместо них отдаётся fallback
+func writeTransferError(w http.ResponseWriter, err error, fallback string) {
+	if writeFrozenError(w, err) {
+		return
+	}
+	var violation *entities.PolicyViolation
+	if errors.As(err, &violation) {
+		writePolicyViolation(w, violation)
+		return
+	}
+
+	status := transferErrorStatus(err)
+	if status == http.StatusInternalServerError {
+		http.Error(w, fallback, status)
+		return
+	}
+	http.Error(w, err.Error(), status)
+}
+
+func policyViolationStatus(violation *entities.PolicyViolation) int {
+	if violation.Code == entities.PolicyRecipientCooldown {
+		return http.StatusTooManyRequests
+	}
+	return http.StatusUnprocessableEntity
+}
+
 func BuyItemHandler(uc UsecaseShop) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		item, ok := r.Context().Value(internal.ValidBuyItemKey).(string)
@@ -62,6 +151,9 @@ func BuyItemHandler(uc UsecaseShop) http.HandlerFunc {
 
 		err := uc.BuyItem(r.Context(), username, item)
 		if err != nil {
+			if writeFrozenError(w, err) {
+				return
+			}
 			http.Error(w, "Can't buy item", http.StatusInternalServerError)
 			return
 		}
@@ -78,6 +170,10 @@ func AuthHandler(uc UsecaseShop) http.HandlerFunc {
 		err := uc.Auth(r.Context(), req.Username, req.Password)
 
 		if err != nil {
+			if errors.Is(err, entities.ErrUserDeactivated) {
+				http.Error(w, err.Error(), http.StatusForbidden)
+				return
+			}
 			http.Error(w, "Could not generate token", http.StatusUnauthorized)
 			return
 		}
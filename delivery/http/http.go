@@ -3,16 +3,62 @@ package http
 import (
 	"context"
 	"net/http"
+	"time"
 
 	"ttavito/domain/entities"
 	"ttavito/internal"
+
+	"github.com/coder/websocket"
 )
 
 type UsecaseShop interface {
 	GetInfo(ctx context.Context, username string) (*entities.InfoResponse, error)
 	BuyItem(ctx context.Context, username, item string) error
-	SendCoin(ctx context.Context, senderUsername string, recipientUsername string, amount int) error
+	GiftItem(ctx context.Context, buyer string, req entities.GiftRequest) error
+	SendCoin(ctx context.Context, senderUsername string, recipientUsername string, amount int, message string) error
+	BatchSendCoin(ctx context.Context, senderUsername string, req entities.BatchSendCoinRequest) (*entities.BatchSendCoinResponse, error)
+	SendPendingCoin(ctx context.Context, senderUsername string, req entities.SendCoinRequest) (*entities.PendingTransfer, error)
+	GetPendingTransfers(ctx context.Context, username string) (*entities.PendingTransfersResponse, error)
+	AcceptPendingTransfer(ctx context.Context, username, id string) (*entities.PendingTransfer, error)
+	DeclinePendingTransfer(ctx context.Context, username, id string) (*entities.PendingTransfer, error)
+	CreateScheduledTransfer(ctx context.Context, senderUsername string, req entities.ScheduledTransferRequest) (*entities.ScheduledTransfer, error)
+	GetScheduledTransfers(ctx context.Context, username string) ([]entities.ScheduledTransfer, error)
+	PauseScheduledTransfer(ctx context.Context, username, id string) (*entities.ScheduledTransfer, error)
+	ResumeScheduledTransfer(ctx context.Context, username, id string) (*entities.ScheduledTransfer, error)
+	CancelScheduledTransfer(ctx context.Context, username, id string) (*entities.ScheduledTransfer, error)
 	Auth(ctx context.Context, username, password string) error
+	ReverseTransfer(ctx context.Context, admin string, req entities.ReverseTransferRequest) (*entities.ReversalResponse, error)
+	GrantCoins(ctx context.Context, admin string, req entities.GrantRequest) (*entities.Grant, error)
+	BulkGrantCoins(ctx context.Context, admin string, req entities.BulkGrantRequest) (*entities.BulkGrantResponse, error)
+	CreateDepartment(ctx context.Context, admin string, req entities.DepartmentRequest) (*entities.Department, error)
+	AddDepartmentMembers(ctx context.Context, admin string, req entities.DepartmentMembersRequest) (*entities.Department, error)
+	AllocateDepartmentBudget(ctx context.Context, admin string, req entities.BudgetAllocationRequest) (*entities.Department, error)
+	RecognizeFromBudget(ctx context.Context, manager string, req entities.RecognitionRequest) (*entities.Recognition, error)
+	GetDepartmentBudgetReport(ctx context.Context, req entities.DepartmentReportRequest) (*entities.DepartmentBudgetReport, error)
+	GetTeam(ctx context.Context, name string) (*entities.Team, error)
+	GetProfile(ctx context.Context, username string) (*entities.Profile, error)
+	UpdateProfile(ctx context.Context, username string, req entities.UpdateProfileRequest) (*entities.Profile, error)
+	UploadAvatar(ctx context.Context, username string, avatar entities.Avatar) (*entities.Profile, error)
+	GetAvatar(ctx context.Context, username string) (*entities.Avatar, error)
+	SearchUsers(ctx context.Context, requester string, req entities.UserSearchRequest) (*entities.UserSearchResponse, error)
+	OffboardUser(ctx context.Context, admin string, req entities.OffboardRequest) (*entities.Offboarding, error)
+	FreezeAccount(ctx context.Context, admin string, req entities.FreezeRequest) (*entities.AccountFreeze, error)
+	UnfreezeAccount(ctx context.Context, admin string, req entities.UnfreezeRequest) (*entities.AccountFreeze, error)
+	GetFraudFindings(ctx context.Context, req entities.FraudFindingsRequest) (*entities.FraudFindingsResponse, error)
+	ReviewFraudFinding(ctx context.Context, admin string, req entities.FraudReviewRequest) (*entities.FraudFinding, error)
+	GetAuditEvents(ctx context.Context, req entities.AuditQuery) (*entities.AuditEventsResponse, error)
+	CreateWebhook(ctx context.Context, admin string, req entities.WebhookRequest) (*entities.WebhookSubscription, error)
+	GetWebhooks(ctx context.Context) (*entities.WebhooksResponse, error)
+	DeleteWebhook(ctx context.Context, admin, id string) error
+	GetWebhookDeliveries(ctx context.Context, req entities.WebhookDeliveriesRequest) (*entities.WebhookDeliveriesResponse, error)
+	RetryWebhookDelivery(ctx context.Context, admin, id string) (*entities.WebhookDelivery, error)
+	GetNotifications(ctx context.Context, username string, req entities.NotificationsRequest) (*entities.NotificationsResponse, error)
+	MarkNotificationRead(ctx context.Context, username string, id int64) (*entities.Notification, error)
+	MarkNotificationsRead(ctx context.Context, username string, upToID int64) (*entities.ReadNotificationsResponse, error)
+	GetDigestSettings(ctx context.Context, username string) (*entities.DigestSettings, error)
+	UpdateDigestSettings(ctx context.Context, username string, req entities.DigestSettings) (*entities.DigestSettings, error)
+	SubscribeUserEvents(username string) (<-chan entities.UserEvent, func(), error)
+	ServeEventSocket(ctx context.Context, conn *websocket.Conn, username string, expiresAt time.Time) error
 }
 
 func SetupRoutes(api UsecaseShop, mux *http.ServeMux) {
@@ -24,6 +70,13 @@ func SetupRoutes(api UsecaseShop, mux *http.ServeMux) {
 		internal.ValidateBuyItemMiddleware,
 	)
 
+	giftItemCompleteHandler := internal.ChainMiddleware(
+		GiftItemHandler(api),
+		internal.PostMethodMiddleware,
+		internal.AuthMiddleware,
+		internal.ValidateGiftMiddleware,
+	)
+
 	authUserCompleteHandler := internal.ChainMiddleware(
 		AuthHandler(api),
 		internal.PostMethodMiddleware,
@@ -37,14 +90,369 @@ func SetupRoutes(api UsecaseShop, mux *http.ServeMux) {
 		internal.AuthMiddleware,
 	)
 
+	batchSendCoinCompleteHandler := internal.ChainMiddleware(
+		BatchSendCoinHandler(api),
+		internal.PostMethodMiddleware,
+		internal.AuthMiddleware,
+		internal.ValidateBatchSendCoinMiddleware,
+	)
+
+	getPendingTransfersCompleteHandler := internal.ChainMiddleware(
+		GetPendingTransfersHandler(api),
+		internal.GetMethodMiddleware,
+		internal.AuthMiddleware,
+	)
+
+	acceptPendingTransferCompleteHandler := internal.ChainMiddleware(
+		AcceptPendingTransferHandler(api),
+		internal.PostMethodMiddleware,
+		internal.AuthMiddleware,
+		internal.ValidatePathIDMiddleware,
+	)
+
+	declinePendingTransferCompleteHandler := internal.ChainMiddleware(
+		DeclinePendingTransferHandler(api),
+		internal.PostMethodMiddleware,
+		internal.AuthMiddleware,
+		internal.ValidatePathIDMiddleware,
+	)
+
+	createScheduledTransferCompleteHandler := internal.ChainMiddleware(
+		CreateScheduledTransferHandler(api),
+		internal.PostMethodMiddleware,
+		internal.AuthMiddleware,
+		internal.ValidateScheduledTransferMiddleware,
+	)
+
+	getScheduledTransfersCompleteHandler := internal.ChainMiddleware(
+		GetScheduledTransfersHandler(api),
+		internal.GetMethodMiddleware,
+		internal.AuthMiddleware,
+	)
+
+	pauseScheduledTransferCompleteHandler := internal.ChainMiddleware(
+		PauseScheduledTransferHandler(api),
+		internal.PostMethodMiddleware,
+		internal.AuthMiddleware,
+		internal.ValidatePathIDMiddleware,
+	)
+
+	resumeScheduledTransferCompleteHandler := internal.ChainMiddleware(
+		ResumeScheduledTransferHandler(api),
+		internal.PostMethodMiddleware,
+		internal.AuthMiddleware,
+		internal.ValidatePathIDMiddleware,
+	)
+
+	cancelScheduledTransferCompleteHandler := internal.ChainMiddleware(
+		CancelScheduledTransferHandler(api),
+		internal.PostMethodMiddleware,
+		internal.AuthMiddleware,
+		internal.ValidatePathIDMiddleware,
+	)
+
+	eventsStreamCompleteHandler := internal.ChainMiddleware(
+		EventsStreamHandler(api),
+		internal.GetMethodMiddleware,
+		internal.AuthMiddleware,
+	)
+
+	eventsSocketCompleteHandler := internal.ChainMiddleware(
+		EventsSocketHandler(api),
+		internal.GetMethodMiddleware,
+		internal.WebSocketTokenMiddleware,
+		internal.AuthMiddleware,
+	)
+
+	notificationsCompleteHandler := internal.ChainMiddleware(
+		NotificationsHandler(api),
+		internal.GetMethodMiddleware,
+		internal.AuthMiddleware,
+		internal.ValidateNotificationsMiddleware,
+	)
+
+	readNotificationCompleteHandler := internal.ChainMiddleware(
+		ReadNotificationHandler(api),
+		internal.PostMethodMiddleware,
+		internal.AuthMiddleware,
+		internal.ValidateNotificationIDMiddleware,
+	)
+
+	readAllNotificationsCompleteHandler := internal.ChainMiddleware(
+		ReadAllNotificationsHandler(api),
+		internal.PostMethodMiddleware,
+		internal.AuthMiddleware,
+		internal.ValidateReadNotificationsMiddleware,
+	)
+
 	getInfoCompleteHandler := internal.ChainMiddleware(
 		GetInfoHandler(api),
 		internal.GetMethodMiddleware,
 		internal.AuthMiddleware,
 	)
 
-	mux.Handle("/api/buy/{item}", buyItemCompleteHandler) // get
-	mux.Handle("/api/auth", authUserCompleteHandler)      // post
-	mux.Handle("/api/sendCoin", sendCoinCompleteHandler)  // post
-	mux.Handle("/api/info", getInfoCompleteHandler)       // get
+	reverseTransferCompleteHandler := internal.ChainMiddleware(
+		ReverseTransferHandler(api),
+		internal.PostMethodMiddleware,
+		internal.AuthMiddleware,
+		internal.AdminMiddleware,
+		internal.ValidateReverseTransferMiddleware,
+	)
+
+	grantCoinsCompleteHandler := internal.ChainMiddleware(
+		GrantCoinsHandler(api),
+		internal.PostMethodMiddleware,
+		internal.AuthMiddleware,
+		internal.AdminMiddleware,
+		internal.ValidateGrantMiddleware,
+	)
+
+	bulkGrantCoinsCompleteHandler := internal.ChainMiddleware(
+		BulkGrantCoinsHandler(api),
+		internal.PostMethodMiddleware,
+		internal.AuthMiddleware,
+		internal.AdminMiddleware,
+		internal.ValidateBulkGrantMiddleware,
+	)
+
+	recognizeCompleteHandler := internal.ChainMiddleware(
+		RecognizeFromBudgetHandler(api),
+		internal.PostMethodMiddleware,
+		internal.AuthMiddleware,
+		internal.ValidateRecognitionMiddleware,
+	)
+
+	departmentReportCompleteHandler := internal.ChainMiddleware(
+		DepartmentBudgetReportHandler(api),
+		internal.GetMethodMiddleware,
+		internal.AuthMiddleware,
+		internal.ValidateDepartmentReportMiddleware,
+	)
+
+	getTeamCompleteHandler := internal.ChainMiddleware(
+		GetTeamHandler(api),
+		internal.GetMethodMiddleware,
+		internal.AuthMiddleware,
+	)
+
+	getMyProfileCompleteHandler := internal.ChainMiddleware(
+		GetMyProfileHandler(api),
+		internal.GetMethodMiddleware,
+		internal.AuthMiddleware,
+	)
+
+	updateProfileCompleteHandler := internal.ChainMiddleware(
+		UpdateProfileHandler(api),
+		internal.PutMethodMiddleware,
+		internal.AuthMiddleware,
+		internal.ValidateProfileMiddleware,
+	)
+
+	getDigestSettingsCompleteHandler := internal.ChainMiddleware(
+		GetDigestSettingsHandler(api),
+		internal.GetMethodMiddleware,
+		internal.AuthMiddleware,
+	)
+
+	updateDigestSettingsCompleteHandler := internal.ChainMiddleware(
+		UpdateDigestSettingsHandler(api),
+		internal.PutMethodMiddleware,
+		internal.AuthMiddleware,
+		internal.ValidateDigestSettingsMiddleware,
+	)
+
+	uploadAvatarCompleteHandler := internal.ChainMiddleware(
+		UploadAvatarHandler(api),
+		internal.PutMethodMiddleware,
+		internal.AuthMiddleware,
+		internal.ValidateAvatarMiddleware,
+	)
+
+	getUserProfileCompleteHandler := internal.ChainMiddleware(
+		GetUserProfileHandler(api),
+		internal.GetMethodMiddleware,
+		internal.AuthMiddleware,
+	)
+
+	// Автодополнение дёргают на каждый ввод символа, поэтому у поиска свой лимит запросов
+	searchLimiter := internal.NewRateLimiter(internal.SearchRateLimit, time.Minute)
+	searchUsersCompleteHandler := internal.ChainMiddleware(
+		SearchUsersHandler(api),
+		internal.GetMethodMiddleware,
+		internal.AuthMiddleware,
+		searchLimiter.Middleware,
+		internal.ValidateUserSearchMiddleware,
+	)
+
+	// Без авторизации: ссылку на аватар вставляют в <img>, который не передаёт токен
+	getAvatarCompleteHandler := internal.ChainMiddleware(
+		GetAvatarHandler(api),
+		internal.GetMethodMiddleware,
+	)
+
+	createDepartmentCompleteHandler := internal.ChainMiddleware(
+		CreateDepartmentHandler(api),
+		internal.PostMethodMiddleware,
+		internal.AuthMiddleware,
+		internal.AdminMiddleware,
+		internal.ValidateDepartmentMiddleware,
+	)
+
+	addDepartmentMembersCompleteHandler := internal.ChainMiddleware(
+		AddDepartmentMembersHandler(api),
+		internal.PostMethodMiddleware,
+		internal.AuthMiddleware,
+		internal.AdminMiddleware,
+		internal.ValidateDepartmentMembersMiddleware,
+	)
+
+	allocateBudgetCompleteHandler := internal.ChainMiddleware(
+		AllocateDepartmentBudgetHandler(api),
+		internal.PostMethodMiddleware,
+		internal.AuthMiddleware,
+		internal.AdminMiddleware,
+		internal.ValidateBudgetAllocationMiddleware,
+	)
+
+	offboardUserCompleteHandler := internal.ChainMiddleware(
+		OffboardUserHandler(api),
+		internal.PostMethodMiddleware,
+		internal.AuthMiddleware,
+		internal.AdminMiddleware,
+		internal.ValidateOffboardMiddleware,
+	)
+
+	freezeAccountCompleteHandler := internal.ChainMiddleware(
+		FreezeAccountHandler(api),
+		internal.PostMethodMiddleware,
+		internal.AuthMiddleware,
+		internal.AdminMiddleware,
+		internal.ValidateFreezeMiddleware,
+	)
+
+	unfreezeAccountCompleteHandler := internal.ChainMiddleware(
+		UnfreezeAccountHandler(api),
+		internal.PostMethodMiddleware,
+		internal.AuthMiddleware,
+		internal.AdminMiddleware,
+		internal.ValidateUnfreezeMiddleware,
+	)
+
+	fraudFindingsCompleteHandler := internal.ChainMiddleware(
+		FraudFindingsHandler(api),
+		internal.GetMethodMiddleware,
+		internal.AuthMiddleware,
+		internal.AdminMiddleware,
+		internal.ValidateFraudFindingsMiddleware,
+	)
+
+	reviewFraudFindingCompleteHandler := internal.ChainMiddleware(
+		ReviewFraudFindingHandler(api),
+		internal.PostMethodMiddleware,
+		internal.AuthMiddleware,
+		internal.AdminMiddleware,
+		internal.ValidateFraudReviewMiddleware,
+	)
+
+	auditEventsCompleteHandler := internal.ChainMiddleware(
+		AuditEventsHandler(api),
+		internal.GetMethodMiddleware,
+		internal.AuthMiddleware,
+		internal.AdminMiddleware,
+		internal.ValidateAuditQueryMiddleware,
+	)
+
+	createWebhookCompleteHandler := internal.ChainMiddleware(
+		CreateWebhookHandler(api),
+		internal.PostMethodMiddleware,
+		internal.AuthMiddleware,
+		internal.AdminMiddleware,
+		internal.ValidateWebhookMiddleware,
+	)
+
+	getWebhooksCompleteHandler := internal.ChainMiddleware(
+		GetWebhooksHandler(api),
+		internal.GetMethodMiddleware,
+		internal.AuthMiddleware,
+		internal.AdminMiddleware,
+	)
+
+	deleteWebhookCompleteHandler := internal.ChainMiddleware(
+		DeleteWebhookHandler(api),
+		internal.DeleteMethodMiddleware,
+		internal.AuthMiddleware,
+		internal.AdminMiddleware,
+		internal.ValidatePathIDMiddleware,
+	)
+
+	webhookDeliveriesCompleteHandler := internal.ChainMiddleware(
+		WebhookDeliveriesHandler(api),
+		internal.GetMethodMiddleware,
+		internal.AuthMiddleware,
+		internal.AdminMiddleware,
+		internal.ValidateWebhookDeliveriesMiddleware,
+	)
+
+	retryWebhookDeliveryCompleteHandler := internal.ChainMiddleware(
+		RetryWebhookDeliveryHandler(api),
+		internal.PostMethodMiddleware,
+		internal.AuthMiddleware,
+		internal.AdminMiddleware,
+		internal.ValidatePathIDMiddleware,
+	)
+
+	mux.Handle("/api/buy/{item}", buyItemCompleteHandler)           // get
+	mux.Handle("/api/buy/{item}/gift", giftItemCompleteHandler)     // post
+	mux.Handle("/api/auth", authUserCompleteHandler)                // post
+	mux.Handle("/api/sendCoin", sendCoinCompleteHandler)            // post
+	mux.Handle("/api/sendCoin/batch", batchSendCoinCompleteHandler) // post
+	mux.Handle("/api/info", getInfoCompleteHandler)                 // get
+	mux.Handle("/api/events", eventsStreamCompleteHandler)          // get, text/event-stream
+	mux.Handle("/api/ws", eventsSocketCompleteHandler)              // get, websocket
+
+	mux.Handle("/api/notifications", notificationsCompleteHandler)              // get, ?unread=true&limit=&beforeId=
+	mux.Handle("/api/notifications/{id}/read", readNotificationCompleteHandler) // post
+	mux.Handle("/api/notifications/read", readAllNotificationsCompleteHandler)  // post, {"upToId": 42}
+
+	mux.Handle("/api/pendingTransfers", getPendingTransfersCompleteHandler)                 // get
+	mux.Handle("/api/pendingTransfers/{id}/accept", acceptPendingTransferCompleteHandler)   // post
+	mux.Handle("/api/pendingTransfers/{id}/decline", declinePendingTransferCompleteHandler) // post
+
+	// Один путь для списка и создания, поэтому метод указан в шаблоне
+	mux.Handle("GET /api/scheduledTransfers", getScheduledTransfersCompleteHandler)
+	mux.Handle("POST /api/scheduledTransfers", createScheduledTransferCompleteHandler)
+	mux.Handle("/api/scheduledTransfers/{id}/pause", pauseScheduledTransferCompleteHandler)   // post
+	mux.Handle("/api/scheduledTransfers/{id}/resume", resumeScheduledTransferCompleteHandler) // post
+	mux.Handle("/api/scheduledTransfers/{id}/cancel", cancelScheduledTransferCompleteHandler) // post
+
+	mux.Handle("/api/departments/{department}/recognitions", recognizeCompleteHandler)  // post
+	mux.Handle("/api/departments/{department}/report", departmentReportCompleteHandler) // get, ?month=2025-02
+	mux.Handle("/api/teams/{team}", getTeamCompleteHandler)                             // get
+
+	mux.Handle("GET /api/me", getMyProfileCompleteHandler)
+	mux.Handle("PUT /api/me", updateProfileCompleteHandler)
+	mux.Handle("/api/me/avatar", uploadAvatarCompleteHandler) // put
+	mux.Handle("GET /api/me/digest", getDigestSettingsCompleteHandler)
+	mux.Handle("PUT /api/me/digest", updateDigestSettingsCompleteHandler) // {"enabled": false}
+	mux.Handle("/api/users/search", searchUsersCompleteHandler)           // get, ?q=&limit=&offset=
+	mux.Handle("/api/users/{username}", getUserProfileCompleteHandler)    // get
+	mux.Handle("/api/users/{username}/avatar", getAvatarCompleteHandler)  // get
+
+	mux.Handle("/api/admin/transfers/{id}/reverse", reverseTransferCompleteHandler)                // post
+	mux.Handle("/api/admin/grants", grantCoinsCompleteHandler)                                     // post
+	mux.Handle("/api/admin/grants/bulk", bulkGrantCoinsCompleteHandler)                            // post, ?dryRun=true
+	mux.Handle("/api/admin/departments", createDepartmentCompleteHandler)                          // post
+	mux.Handle("/api/admin/departments/{department}/members", addDepartmentMembersCompleteHandler) // post
+	mux.Handle("/api/admin/departments/{department}/budget", allocateBudgetCompleteHandler)        // post
+	mux.Handle("/api/admin/users/{username}/offboard", offboardUserCompleteHandler)                // post
+	mux.Handle("/api/admin/users/{username}/freeze", freezeAccountCompleteHandler)                 // post
+	mux.Handle("/api/admin/users/{username}/unfreeze", unfreezeAccountCompleteHandler)             // post
+	mux.Handle("/api/admin/fraud/findings", fraudFindingsCompleteHandler)                          // get
+	mux.Handle("/api/admin/fraud/findings/{id}/review", reviewFraudFindingCompleteHandler)         // post
+	mux.Handle("/api/admin/audit", auditEventsCompleteHandler)                                     // get
+	mux.Handle("GET /api/admin/webhooks", getWebhooksCompleteHandler)
+	mux.Handle("POST /api/admin/webhooks", createWebhookCompleteHandler)
+	mux.Handle("/api/admin/webhooks/{id}", deleteWebhookCompleteHandler)                         // delete
+	mux.Handle("/api/admin/webhooks/{id}/deliveries", webhookDeliveriesCompleteHandler)          // get, ?status=&limit=
+	mux.Handle("/api/admin/webhooks/deliveries/{id}/retry", retryWebhookDeliveryCompleteHandler) // post
 }
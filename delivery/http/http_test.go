@@ -4,13 +4,17 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"ttavito/domain/entities"
 	"ttavito/internal"
 
+	"github.com/coder/websocket"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -24,21 +28,239 @@ func (m *MockUsecase) GetInfo(ctx context.Context, username string) (*entities.I
 	return args.Get(0).(*entities.InfoResponse), args.Error(1)
 }
 
-func (m *MockUsecase) SendCoin(ctx context.Context, username, toUser string, amount int) error {
-	args := m.Called(ctx, username, toUser, amount)
+func (m *MockUsecase) SendCoin(ctx context.Context, username, toUser string, amount int, message string) error {
+	args := m.Called(ctx, username, toUser, amount, message)
 	return args.Error(0)
 }
 
+func (m *MockUsecase) BatchSendCoin(ctx context.Context, username string, req entities.BatchSendCoinRequest) (*entities.BatchSendCoinResponse, error) {
+	args := m.Called(ctx, username, req)
+	return args.Get(0).(*entities.BatchSendCoinResponse), args.Error(1)
+}
+
+func (m *MockUsecase) SendPendingCoin(ctx context.Context, username string, req entities.SendCoinRequest) (*entities.PendingTransfer, error) {
+	args := m.Called(ctx, username, req)
+	return args.Get(0).(*entities.PendingTransfer), args.Error(1)
+}
+
+func (m *MockUsecase) GetPendingTransfers(ctx context.Context, username string) (*entities.PendingTransfersResponse, error) {
+	args := m.Called(ctx, username)
+	return args.Get(0).(*entities.PendingTransfersResponse), args.Error(1)
+}
+
+func (m *MockUsecase) AcceptPendingTransfer(ctx context.Context, username, id string) (*entities.PendingTransfer, error) {
+	args := m.Called(ctx, username, id)
+	return args.Get(0).(*entities.PendingTransfer), args.Error(1)
+}
+
+func (m *MockUsecase) DeclinePendingTransfer(ctx context.Context, username, id string) (*entities.PendingTransfer, error) {
+	args := m.Called(ctx, username, id)
+	return args.Get(0).(*entities.PendingTransfer), args.Error(1)
+}
+
+func (m *MockUsecase) CreateScheduledTransfer(ctx context.Context, username string, req entities.ScheduledTransferRequest) (*entities.ScheduledTransfer, error) {
+	args := m.Called(ctx, username, req)
+	return args.Get(0).(*entities.ScheduledTransfer), args.Error(1)
+}
+
+func (m *MockUsecase) GetScheduledTransfers(ctx context.Context, username string) ([]entities.ScheduledTransfer, error) {
+	args := m.Called(ctx, username)
+	return args.Get(0).([]entities.ScheduledTransfer), args.Error(1)
+}
+
+func (m *MockUsecase) PauseScheduledTransfer(ctx context.Context, username, id string) (*entities.ScheduledTransfer, error) {
+	args := m.Called(ctx, username, id)
+	return args.Get(0).(*entities.ScheduledTransfer), args.Error(1)
+}
+
+func (m *MockUsecase) ResumeScheduledTransfer(ctx context.Context, username, id string) (*entities.ScheduledTransfer, error) {
+	args := m.Called(ctx, username, id)
+	return args.Get(0).(*entities.ScheduledTransfer), args.Error(1)
+}
+
+func (m *MockUsecase) CancelScheduledTransfer(ctx context.Context, username, id string) (*entities.ScheduledTransfer, error) {
+	args := m.Called(ctx, username, id)
+	return args.Get(0).(*entities.ScheduledTransfer), args.Error(1)
+}
+
 func (m *MockUsecase) BuyItem(ctx context.Context, username, item string) error {
 	args := m.Called(ctx, username, item)
 	return args.Error(0)
 }
 
+func (m *MockUsecase) GiftItem(ctx context.Context, buyer string, req entities.GiftRequest) error {
+	args := m.Called(ctx, buyer, req)
+	return args.Error(0)
+}
+
 func (m *MockUsecase) Auth(ctx context.Context, username, password string) error {
 	args := m.Called(ctx, username, password)
 	return args.Error(0)
 }
 
+func (m *MockUsecase) ReverseTransfer(ctx context.Context, admin string, req entities.ReverseTransferRequest) (*entities.ReversalResponse, error) {
+	args := m.Called(ctx, admin, req)
+	return args.Get(0).(*entities.ReversalResponse), args.Error(1)
+}
+
+func (m *MockUsecase) CreateDepartment(ctx context.Context, admin string, req entities.DepartmentRequest) (*entities.Department, error) {
+	args := m.Called(ctx, admin, req)
+	return args.Get(0).(*entities.Department), args.Error(1)
+}
+
+func (m *MockUsecase) AddDepartmentMembers(ctx context.Context, admin string, req entities.DepartmentMembersRequest) (*entities.Department, error) {
+	args := m.Called(ctx, admin, req)
+	return args.Get(0).(*entities.Department), args.Error(1)
+}
+
+func (m *MockUsecase) AllocateDepartmentBudget(ctx context.Context, admin string, req entities.BudgetAllocationRequest) (*entities.Department, error) {
+	args := m.Called(ctx, admin, req)
+	return args.Get(0).(*entities.Department), args.Error(1)
+}
+
+func (m *MockUsecase) RecognizeFromBudget(ctx context.Context, manager string, req entities.RecognitionRequest) (*entities.Recognition, error) {
+	args := m.Called(ctx, manager, req)
+	return args.Get(0).(*entities.Recognition), args.Error(1)
+}
+
+func (m *MockUsecase) GetDepartmentBudgetReport(ctx context.Context, req entities.DepartmentReportRequest) (*entities.DepartmentBudgetReport, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(*entities.DepartmentBudgetReport), args.Error(1)
+}
+
+func (m *MockUsecase) GetTeam(ctx context.Context, name string) (*entities.Team, error) {
+	args := m.Called(ctx, name)
+	return args.Get(0).(*entities.Team), args.Error(1)
+}
+
+func (m *MockUsecase) GetProfile(ctx context.Context, username string) (*entities.Profile, error) {
+	args := m.Called(ctx, username)
+	return args.Get(0).(*entities.Profile), args.Error(1)
+}
+
+func (m *MockUsecase) UpdateProfile(ctx context.Context, username string, req entities.UpdateProfileRequest) (*entities.Profile, error) {
+	args := m.Called(ctx, username, req)
+	return args.Get(0).(*entities.Profile), args.Error(1)
+}
+
+func (m *MockUsecase) UploadAvatar(ctx context.Context, username string, avatar entities.Avatar) (*entities.Profile, error) {
+	args := m.Called(ctx, username, avatar)
+	return args.Get(0).(*entities.Profile), args.Error(1)
+}
+
+func (m *MockUsecase) GetAvatar(ctx context.Context, username string) (*entities.Avatar, error) {
+	args := m.Called(ctx, username)
+	return args.Get(0).(*entities.Avatar), args.Error(1)
+}
+
+func (m *MockUsecase) SearchUsers(ctx context.Context, requester string, req entities.UserSearchRequest) (*entities.UserSearchResponse, error) {
+	args := m.Called(ctx, requester, req)
+	return args.Get(0).(*entities.UserSearchResponse), args.Error(1)
+}
+
+func (m *MockUsecase) OffboardUser(ctx context.Context, admin string, req entities.OffboardRequest) (*entities.Offboarding, error) {
+	args := m.Called(ctx, admin, req)
+	return args.Get(0).(*entities.Offboarding), args.Error(1)
+}
+
+func (m *MockUsecase) FreezeAccount(ctx context.Context, admin string, req entities.FreezeRequest) (*entities.AccountFreeze, error) {
+	args := m.Called(ctx, admin, req)
+	return args.Get(0).(*entities.AccountFreeze), args.Error(1)
+}
+
+func (m *MockUsecase) UnfreezeAccount(ctx context.Context, admin string, req entities.UnfreezeRequest) (*entities.AccountFreeze, error) {
+	args := m.Called(ctx, admin, req)
+	return args.Get(0).(*entities.AccountFreeze), args.Error(1)
+}
+
+func (m *MockUsecase) GetFraudFindings(ctx context.Context, req entities.FraudFindingsRequest) (*entities.FraudFindingsResponse, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(*entities.FraudFindingsResponse), args.Error(1)
+}
+
+func (m *MockUsecase) ReviewFraudFinding(ctx context.Context, admin string, req entities.FraudReviewRequest) (*entities.FraudFinding, error) {
+	args := m.Called(ctx, admin, req)
+	return args.Get(0).(*entities.FraudFinding), args.Error(1)
+}
+
+func (m *MockUsecase) GetAuditEvents(ctx context.Context, req entities.AuditQuery) (*entities.AuditEventsResponse, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(*entities.AuditEventsResponse), args.Error(1)
+}
+
+func (m *MockUsecase) CreateWebhook(ctx context.Context, admin string, req entities.WebhookRequest) (*entities.WebhookSubscription, error) {
+	args := m.Called(ctx, admin, req)
+	return args.Get(0).(*entities.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockUsecase) GetWebhooks(ctx context.Context) (*entities.WebhooksResponse, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(*entities.WebhooksResponse), args.Error(1)
+}
+
+func (m *MockUsecase) DeleteWebhook(ctx context.Context, admin, id string) error {
+	args := m.Called(ctx, admin, id)
+	return args.Error(0)
+}
+
+func (m *MockUsecase) GetWebhookDeliveries(ctx context.Context, req entities.WebhookDeliveriesRequest) (*entities.WebhookDeliveriesResponse, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(*entities.WebhookDeliveriesResponse), args.Error(1)
+}
+
+func (m *MockUsecase) RetryWebhookDelivery(ctx context.Context, admin, id string) (*entities.WebhookDelivery, error) {
+	args := m.Called(ctx, admin, id)
+	return args.Get(0).(*entities.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockUsecase) SubscribeUserEvents(username string) (<-chan entities.UserEvent, func(), error) {
+	args := m.Called(username)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).(chan entities.UserEvent), args.Get(1).(func()), args.Error(2)
+}
+
+func (m *MockUsecase) ServeEventSocket(ctx context.Context, conn *websocket.Conn, username string, expiresAt time.Time) error {
+	args := m.Called(ctx, conn, username, expiresAt)
+	return args.Error(0)
+}
+
+func (m *MockUsecase) GetNotifications(ctx context.Context, username string, req entities.NotificationsRequest) (*entities.NotificationsResponse, error) {
+	args := m.Called(ctx, username, req)
+	return args.Get(0).(*entities.NotificationsResponse), args.Error(1)
+}
+
+func (m *MockUsecase) MarkNotificationRead(ctx context.Context, username string, id int64) (*entities.Notification, error) {
+	args := m.Called(ctx, username, id)
+	return args.Get(0).(*entities.Notification), args.Error(1)
+}
+
+func (m *MockUsecase) MarkNotificationsRead(ctx context.Context, username string, upToID int64) (*entities.ReadNotificationsResponse, error) {
+	args := m.Called(ctx, username, upToID)
+	return args.Get(0).(*entities.ReadNotificationsResponse), args.Error(1)
+}
+
+func (m *MockUsecase) GetDigestSettings(ctx context.Context, username string) (*entities.DigestSettings, error) {
+	args := m.Called(ctx, username)
+	return args.Get(0).(*entities.DigestSettings), args.Error(1)
+}
+
+func (m *MockUsecase) UpdateDigestSettings(ctx context.Context, username string, req entities.DigestSettings) (*entities.DigestSettings, error) {
+	args := m.Called(ctx, username, req)
+	return args.Get(0).(*entities.DigestSettings), args.Error(1)
+}
+
+func (m *MockUsecase) GrantCoins(ctx context.Context, admin string, req entities.GrantRequest) (*entities.Grant, error) {
+	args := m.Called(ctx, admin, req)
+	return args.Get(0).(*entities.Grant), args.Error(1)
+}
+
+func (m *MockUsecase) BulkGrantCoins(ctx context.Context, admin string, req entities.BulkGrantRequest) (*entities.BulkGrantResponse, error) {
+	args := m.Called(ctx, admin, req)
+	return args.Get(0).(*entities.BulkGrantResponse), args.Error(1)
+}
+
 func TestGetInfoHandler_Success(t *testing.T) {
 	mockUsecase := new(MockUsecase)
 	mockUsecase.On("GetInfo", mock.Anything, "test_user").Return(&entities.InfoResponse{
@@ -79,11 +301,12 @@ func TestGetInfoHandler_Success(t *testing.T) {
 
 func TestSendCoinHandler_Success(t *testing.T) {
 	mockUsecase := new(MockUsecase)
-	mockUsecase.On("SendCoin", mock.Anything, "test_user", "recipient_user", 50).Return(nil)
+	mockUsecase.On("SendCoin", mock.Anything, "test_user", "recipient_user", 50, "за помощь с релизом").Return(nil)
 
 	sendCoinRequest := entities.SendCoinRequest{
-		ToUser: "recipient_user",
-		Amount: 50,
+		ToUser:  "recipient_user",
+		Amount:  50,
+		Message: "за помощь с релизом",
 	}
 	sendCoinRequestBody, _ := json.Marshal(sendCoinRequest)
 
@@ -150,3 +373,107 @@ func TestAuthHandler_Success(t *testing.T) {
 
 	mockUsecase.AssertExpectations(t)
 }
+
+func TestAuthHandler_Deactivated(t *testing.T) {
+	mockUsecase := new(MockUsecase)
+	mockUsecase.On("Auth", mock.Anything, "leaver", "test_pass").Return(entities.ErrUserDeactivated)
+
+	authRequest := entities.AuthRequest{Username: "leaver", Password: "test_pass"}
+	req := httptest.NewRequest("POST", "/api/auth", nil)
+	req = req.WithContext(context.WithValue(req.Context(), internal.ValidAuthReqKey, authRequest))
+
+	rr := httptest.NewRecorder()
+	AuthHandler(mockUsecase).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestSendCoinHandler_PolicyViolation(t *testing.T) {
+	tests := []struct {
+		code   string
+		status int
+	}{
+		{entities.PolicyDailyLimitExceeded, http.StatusUnprocessableEntity},
+		{entities.PolicyRecipientCooldown, http.StatusTooManyRequests},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			mockUsecase := new(MockUsecase)
+			mockUsecase.On("SendCoin", mock.Anything, "test_user", "recipient_user", 50, "").
+				Return(&entities.PolicyViolation{Code: tt.code, Message: "limit"})
+
+			sendCoinRequest := entities.SendCoinRequest{ToUser: "recipient_user", Amount: 50}
+
+			req := httptest.NewRequest("POST", "/api/sendCoin", nil)
+			req = req.WithContext(context.WithValue(req.Context(), internal.UsernameContextKey, "test_user"))
+			req = req.WithContext(context.WithValue(req.Context(), internal.ValidSendCoinKey, sendCoinRequest))
+
+			rr := httptest.NewRecorder()
+			handler := SendCoinHandler(mockUsecase)
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.status, rr.Code)
+
+			var response entities.ErrorResponse
+			err := json.NewDecoder(rr.Body).Decode(&response)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.code, response.Code)
+
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSendCoinHandler_Errors(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+	}{
+		{fmt.Errorf("failed to send coin: %w", entities.ErrNotEnoughBalance), http.StatusBadRequest},
+		{entities.ErrUserNotFound, http.StatusBadRequest},
+		{entities.ErrUserDeactivated, http.StatusBadRequest},
+		{entities.ErrTransferToSelf, http.StatusBadRequest},
+		{entities.ErrRecipientFrozen, http.StatusForbidden},
+		{errors.New("connection reset"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			sendCoinRequest := entities.SendCoinRequest{ToUser: "recipient_user", Amount: 50}
+			batchRequest := entities.BatchSendCoinRequest{Transfers: []entities.SendCoinRequest{sendCoinRequest}}
+
+			mockUsecase := new(MockUsecase)
+			mockUsecase.On("SendCoin", mock.Anything, "test_user", "recipient_user", 50, "").Return(tt.err)
+			mockUsecase.On("SendPendingCoin", mock.Anything, "test_user", mock.Anything).
+				Return((*entities.PendingTransfer)(nil), tt.err)
+			mockUsecase.On("BatchSendCoin", mock.Anything, "test_user", batchRequest).
+				Return(&entities.BatchSendCoinResponse{}, tt.err)
+
+			pendingRequest := sendCoinRequest
+			pendingRequest.RequireAcceptance = true
+
+			// Обычный, с подтверждением и пакетный перевод отвечают на одну ошибку одинаково
+			for _, ctx := range []context.Context{
+				context.WithValue(context.Background(), internal.ValidSendCoinKey, sendCoinRequest),
+				context.WithValue(context.Background(), internal.ValidSendCoinKey, pendingRequest),
+			} {
+				req := httptest.NewRequest("POST", "/api/sendCoin", nil)
+				req = req.WithContext(context.WithValue(ctx, internal.UsernameContextKey, "test_user"))
+				rr := httptest.NewRecorder()
+				SendCoinHandler(mockUsecase).ServeHTTP(rr, req)
+				assert.Equal(t, tt.status, rr.Code)
+			}
+
+			req := httptest.NewRequest("POST", "/api/sendCoin/batch", nil)
+			req = req.WithContext(context.WithValue(req.Context(), internal.UsernameContextKey, "test_user"))
+			req = req.WithContext(context.WithValue(req.Context(), internal.ValidBatchSendKey, batchRequest))
+			rr := httptest.NewRecorder()
+			BatchSendCoinHandler(mockUsecase).ServeHTTP(rr, req)
+			assert.Equal(t, tt.status, rr.Code)
+
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ttavito/domain/entities"
+	"ttavito/internal"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestNotificationsHandler(t *testing.T) {
+	mockUsecase := new(MockUsecase)
+	req := entities.NotificationsRequest{UnreadOnly: true, Limit: 20}
+	next := int64(7)
+	mockUsecase.On("GetNotifications", mock.Anything, "alice", req).Return(&entities.NotificationsResponse{
+		Notifications: []entities.Notification{{ID: 8, Type: entities.NotificationTransferReceived, Data: json.RawMessage(`{"amount":10}`)}},
+		UnreadCount:   3,
+		NextBeforeID:  &next,
+	}, nil)
+
+	httpReq := httptest.NewRequest("GET", "/api/notifications?unread=true", nil)
+	ctx := context.WithValue(httpReq.Context(), internal.ValidNotificationsKey, req)
+	ctx = context.WithValue(ctx, internal.UsernameContextKey, "alice")
+	rr := httptest.NewRecorder()
+	NotificationsHandler(mockUsecase).ServeHTTP(rr, httpReq.WithContext(ctx))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var res entities.NotificationsResponse
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+	assert.Equal(t, 3, res.UnreadCount)
+	assert.Len(t, res.Notifications, 1)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestReadNotificationHandler(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{"read", nil, http.StatusOK},
+		{"not found", entities.ErrNotificationNotFound, http.StatusNotFound},
+		{"error", errors.New("db down"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := new(MockUsecase)
+			var res *entities.Notification
+			if tt.err == nil {
+				res = &entities.Notification{ID: 42}
+			}
+			mockUsecase.On("MarkNotificationRead", mock.Anything, "alice", int64(42)).Return(res, tt.err)
+
+			req := httptest.NewRequest("POST", "/api/notifications/42/read", nil)
+			ctx := context.WithValue(req.Context(), internal.ValidNotificationIDKey, int64(42))
+			ctx = context.WithValue(ctx, internal.UsernameContextKey, "alice")
+			rr := httptest.NewRecorder()
+			ReadNotificationHandler(mockUsecase).ServeHTTP(rr, req.WithContext(ctx))
+
+			assert.Equal(t, tt.code, rr.Code)
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
+
+func TestReadAllNotificationsHandler(t *testing.T) {
+	mockUsecase := new(MockUsecase)
+	mockUsecase.On("MarkNotificationsRead", mock.Anything, "alice", int64(42)).
+		Return(&entities.ReadNotificationsResponse{Updated: 5}, nil)
+
+	req := httptest.NewRequest("POST", "/api/notifications/read", nil)
+	ctx := context.WithValue(req.Context(), internal.ValidReadAllKey, entities.ReadNotificationsRequest{UpToID: 42})
+	ctx = context.WithValue(ctx, internal.UsernameContextKey, "alice")
+	rr := httptest.NewRecorder()
+	ReadAllNotificationsHandler(mockUsecase).ServeHTTP(rr, req.WithContext(ctx))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"updated":5}`, rr.Body.String())
+	mockUsecase.AssertExpectations(t)
+}
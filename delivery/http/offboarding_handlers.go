@@ -0,0 +1,45 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"ttavito/domain/entities"
+	"ttavito/internal"
+)
+
+func OffboardUserHandler(uc UsecaseShop) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, ok := r.Context().Value(internal.ValidOffboardKey).(entities.OffboardRequest)
+		if !ok {
+			http.Error(w, "Invalid request", http.StatusInternalServerError)
+			return
+		}
+
+		admin, ok := r.Context().Value(internal.UsernameContextKey).(string)
+		if !ok {
+			http.Error(w, "Can't grab username from JWT", http.StatusInternalServerError)
+			return
+		}
+
+		res, err := uc.OffboardUser(r.Context(), admin, req)
+		if err != nil {
+			switch {
+			case errors.Is(err, entities.ErrInvalidOffboardRecipient),
+				errors.Is(err, entities.ErrInvalidBalancePolicy):
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			case errors.Is(err, entities.ErrUserNotFound):
+				http.Error(w, err.Error(), http.StatusNotFound)
+			case errors.Is(err, entities.ErrUserDeactivated):
+				http.Error(w, err.Error(), http.StatusConflict)
+			default:
+				http.Error(w, "Can't offboard user", http.StatusInternalServerError)
+			}
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(res)
+	}
+}
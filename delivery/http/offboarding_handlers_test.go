@@ -0,0 +1,51 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ttavito/domain/entities"
+	"ttavito/internal"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestOffboardUserHandler(t *testing.T) {
+	mockUsecase := new(MockUsecase)
+	forfeit := entities.OffboardRequest{Username: "leaver", BalancePolicy: entities.BalancePolicyForfeit, Reason: "увольнение"}
+	mockUsecase.On("OffboardUser", mock.Anything, "admin", forfeit).
+		Return(&entities.Offboarding{ID: "off-1", Username: "leaver", BalancePolicy: entities.BalancePolicyForfeit, BalanceAmount: 700}, nil)
+	again := entities.OffboardRequest{Username: "gone", BalancePolicy: entities.BalancePolicyForfeit, Reason: "увольнение"}
+	mockUsecase.On("OffboardUser", mock.Anything, "admin", again).
+		Return((*entities.Offboarding)(nil), entities.ErrUserDeactivated)
+	badRecipient := entities.OffboardRequest{Username: "leaver", BalancePolicy: entities.BalancePolicyRedistribute, Recipients: []string{"gone"}, Reason: "увольнение"}
+	mockUsecase.On("OffboardUser", mock.Anything, "admin", badRecipient).
+		Return((*entities.Offboarding)(nil), fmt.Errorf("%w: gone", entities.ErrInvalidOffboardRecipient))
+
+	tests := []struct {
+		name string
+		req  entities.OffboardRequest
+		code int
+	}{
+		{"success", forfeit, http.StatusOK},
+		{"already offboarded", again, http.StatusConflict},
+		{"deactivated recipient", badRecipient, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/admin/users/"+tt.req.Username+"/offboard", nil)
+			ctx := context.WithValue(req.Context(), internal.ValidOffboardKey, tt.req)
+			ctx = context.WithValue(ctx, internal.UsernameContextKey, "admin")
+			rr := httptest.NewRecorder()
+			OffboardUserHandler(mockUsecase).ServeHTTP(rr, req.WithContext(ctx))
+
+			assert.Equal(t, tt.code, rr.Code)
+		})
+	}
+	mockUsecase.AssertExpectations(t)
+}
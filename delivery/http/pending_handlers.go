@@ -0,0 +1,64 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"ttavito/domain/entities"
+	"ttavito/internal"
+)
+
+func GetPendingTransfersHandler(uc UsecaseShop) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, ok := r.Context().Value(internal.UsernameContextKey).(string)
+		if !ok {
+			http.Error(w, "Can't grab username from JWT", http.StatusInternalServerError)
+			return
+		}
+
+		res, err := uc.GetPendingTransfers(r.Context(), username)
+		if err != nil {
+			http.Error(w, "Can't get pending transfers", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(res)
+	}
+}
+
+func AcceptPendingTransferHandler(uc UsecaseShop) http.HandlerFunc {
+	return resolvePendingTransferHandler(uc.AcceptPendingTransfer)
+}
+
+func DeclinePendingTransferHandler(uc UsecaseShop) http.HandlerFunc {
+	return resolvePendingTransferHandler(uc.DeclinePendingTransfer)
+}
+
+func resolvePendingTransferHandler(resolve func(ctx context.Context, username, id string) (*entities.PendingTransfer, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := r.Context().Value(internal.ValidPathIDKey).(string)
+		if !ok {
+			http.Error(w, "Invalid request", http.StatusInternalServerError)
+			return
+		}
+
+		username, ok := r.Context().Value(internal.UsernameContextKey).(string)
+		if !ok {
+			http.Error(w, "Can't grab username from JWT", http.StatusInternalServerError)
+			return
+		}
+
+		res, err := resolve(r.Context(), username, id)
+		if err != nil {
+			writePendingTransferError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(res)
+	}
+}
+
+func writePendingTransferError(w http.ResponseWriter, err error) {
+	writeTransferError(w, err, "Can't process pending transfer")
+}
@@ -0,0 +1,100 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ttavito/domain/entities"
+	"ttavito/internal"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSendCoinHandler_RequireAcceptance(t *testing.T) {
+	sendCoinRequest := entities.SendCoinRequest{
+		ToUser:            "recipient_user",
+		Amount:            50,
+		RequireAcceptance: true,
+	}
+
+	mockUsecase := new(MockUsecase)
+	mockUsecase.On("SendPendingCoin", mock.Anything, "test_user", sendCoinRequest).Return(&entities.PendingTransfer{
+		ID:       "0b6b1f8e-3f43-4a53-9a4c-2f0f6b0d8f11",
+		FromUser: "test_user",
+		ToUser:   "recipient_user",
+		Amount:   50,
+		Status:   entities.PendingStatusPending,
+	}, nil)
+
+	req := httptest.NewRequest("POST", "/api/sendCoin", nil)
+	req = req.WithContext(context.WithValue(req.Context(), internal.UsernameContextKey, "test_user"))
+	req = req.WithContext(context.WithValue(req.Context(), internal.ValidSendCoinKey, sendCoinRequest))
+
+	rr := httptest.NewRecorder()
+	handler := SendCoinHandler(mockUsecase)
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusAccepted, rr.Code)
+
+	var response entities.PendingTransfer
+	err := json.NewDecoder(rr.Body).Decode(&response)
+	assert.NoError(t, err)
+	assert.Equal(t, entities.PendingStatusPending, response.Status)
+
+	mockUsecase.AssertNotCalled(t, "SendCoin", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestAcceptPendingTransferHandler_Success(t *testing.T) {
+	id := "0b6b1f8e-3f43-4a53-9a4c-2f0f6b0d8f11"
+
+	mockUsecase := new(MockUsecase)
+	mockUsecase.On("AcceptPendingTransfer", mock.Anything, "test_user", id).Return(&entities.PendingTransfer{
+		ID:         id,
+		FromUser:   "sender_user",
+		ToUser:     "test_user",
+		Amount:     50,
+		Status:     entities.PendingStatusAccepted,
+		TransferID: "5d1c2e64-8c5e-4f4a-bd0e-2b0c8a1f7c22",
+	}, nil)
+
+	req := httptest.NewRequest("POST", "/api/pendingTransfers/"+id+"/accept", nil)
+	req = req.WithContext(context.WithValue(req.Context(), internal.UsernameContextKey, "test_user"))
+	req = req.WithContext(context.WithValue(req.Context(), internal.ValidPathIDKey, id))
+
+	rr := httptest.NewRecorder()
+	handler := AcceptPendingTransferHandler(mockUsecase)
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var response entities.PendingTransfer
+	err := json.NewDecoder(rr.Body).Decode(&response)
+	assert.NoError(t, err)
+	assert.Equal(t, entities.PendingStatusAccepted, response.Status)
+
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestDeclinePendingTransferHandler_AlreadyResolved(t *testing.T) {
+	id := "0b6b1f8e-3f43-4a53-9a4c-2f0f6b0d8f11"
+
+	mockUsecase := new(MockUsecase)
+	mockUsecase.On("DeclinePendingTransfer", mock.Anything, "test_user", id).Return((*entities.PendingTransfer)(nil), entities.ErrPendingTransferResolved)
+
+	req := httptest.NewRequest("POST", "/api/pendingTransfers/"+id+"/decline", nil)
+	req = req.WithContext(context.WithValue(req.Context(), internal.UsernameContextKey, "test_user"))
+	req = req.WithContext(context.WithValue(req.Context(), internal.ValidPathIDKey, id))
+
+	rr := httptest.NewRecorder()
+	handler := DeclinePendingTransferHandler(mockUsecase)
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusConflict, rr.Code)
+
+	mockUsecase.AssertExpectations(t)
+}
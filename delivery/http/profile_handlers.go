@@ -0,0 +1,132 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"ttavito/domain/entities"
+	"ttavito/internal"
+)
+
+func GetMyProfileHandler(uc UsecaseShop) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, ok := r.Context().Value(internal.UsernameContextKey).(string)
+		if !ok {
+			http.Error(w, "Can't grab username from JWT", http.StatusInternalServerError)
+			return
+		}
+
+		res, err := uc.GetProfile(r.Context(), username)
+		if err != nil {
+			writeProfileError(w, err, "Can't get profile")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(res)
+	}
+}
+
+func UpdateProfileHandler(uc UsecaseShop) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, ok := r.Context().Value(internal.ValidProfileKey).(entities.UpdateProfileRequest)
+		if !ok {
+			http.Error(w, "Invalid request", http.StatusInternalServerError)
+			return
+		}
+
+		username, ok := r.Context().Value(internal.UsernameContextKey).(string)
+		if !ok {
+			http.Error(w, "Can't grab username from JWT", http.StatusInternalServerError)
+			return
+		}
+
+		res, err := uc.UpdateProfile(r.Context(), username, req)
+		if err != nil {
+			writeProfileError(w, err, "Can't update profile")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(res)
+	}
+}
+
+func UploadAvatarHandler(uc UsecaseShop) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		avatar, ok := r.Context().Value(internal.ValidAvatarKey).(entities.Avatar)
+		if !ok {
+			http.Error(w, "Invalid request", http.StatusInternalServerError)
+			return
+		}
+
+		username, ok := r.Context().Value(internal.UsernameContextKey).(string)
+		if !ok {
+			http.Error(w, "Can't grab username from JWT", http.StatusInternalServerError)
+			return
+		}
+
+		res, err := uc.UploadAvatar(r.Context(), username, avatar)
+		if err != nil {
+			writeProfileError(w, err, "Can't upload avatar")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(res)
+	}
+}
+
+func GetUserProfileHandler(uc UsecaseShop) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username := r.PathValue("username")
+		if username == "" {
+			http.Error(w, "Invalid input data", http.StatusBadRequest)
+			return
+		}
+
+		res, err := uc.GetProfile(r.Context(), username)
+		if err != nil {
+			writeProfileError(w, err, "Can't get profile")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(res)
+	}
+}
+
+// GetAvatarHandler отдаёт файл аватара. Ссылка из профиля содержит версию,
+// поэтому ответ можно кешировать надолго
+func GetAvatarHandler(uc UsecaseShop) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username := r.PathValue("username")
+		if username == "" {
+			http.Error(w, "Invalid input data", http.StatusBadRequest)
+			return
+		}
+
+		avatar, err := uc.GetAvatar(r.Context(), username)
+		if err != nil {
+			writeProfileError(w, err, "Can't get avatar")
+			return
+		}
+
+		w.Header().Set("Content-Type", avatar.ContentType)
+		w.Header().Set("Cache-Control", "public, max-age=86400")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Write(avatar.Data)
+	}
+}
+
+func writeProfileError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, entities.ErrUserNotFound), errors.Is(err, entities.ErrAvatarNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, entities.ErrAvatarStorageDisabled):
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+	default:
+		http.Error(w, fallback, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,79 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ttavito/domain/entities"
+	"ttavito/internal"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestUpdateProfileHandler(t *testing.T) {
+	mockUsecase := new(MockUsecase)
+	update := entities.UpdateProfileRequest{DisplayName: "Bob", Title: "QA"}
+	profile := &entities.Profile{Username: "test_user", DisplayName: "Bob", Title: "QA", Department: "platform"}
+	mockUsecase.On("UpdateProfile", mock.Anything, "test_user", update).Return(profile, nil)
+
+	req := httptest.NewRequest("PUT", "/api/me", nil)
+	ctx := context.WithValue(req.Context(), internal.ValidProfileKey, update)
+	ctx = context.WithValue(ctx, internal.UsernameContextKey, "test_user")
+	rr := httptest.NewRecorder()
+	UpdateProfileHandler(mockUsecase).ServeHTTP(rr, req.WithContext(ctx))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var response entities.Profile
+	err := json.NewDecoder(rr.Body).Decode(&response)
+	assert.NoError(t, err)
+	assert.Equal(t, *profile, response)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestGetUserProfileHandler(t *testing.T) {
+	mockUsecase := new(MockUsecase)
+	mockUsecase.On("GetProfile", mock.Anything, "bob").Return(&entities.Profile{Username: "bob", DisplayName: "Bob"}, nil)
+	mockUsecase.On("GetProfile", mock.Anything, "ghost").Return((*entities.Profile)(nil), entities.ErrUserNotFound)
+
+	req := httptest.NewRequest("GET", "/api/users/bob", nil)
+	req.SetPathValue("username", "bob")
+	rr := httptest.NewRecorder()
+	GetUserProfileHandler(mockUsecase).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	req = httptest.NewRequest("GET", "/api/users/ghost", nil)
+	req.SetPathValue("username", "ghost")
+	rr = httptest.NewRecorder()
+	GetUserProfileHandler(mockUsecase).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestGetAvatarHandler(t *testing.T) {
+	mockUsecase := new(MockUsecase)
+	mockUsecase.On("GetAvatar", mock.Anything, "bob").Return(&entities.Avatar{Data: []byte("png"), ContentType: "image/png"}, nil)
+	mockUsecase.On("GetAvatar", mock.Anything, "carol").Return((*entities.Avatar)(nil), entities.ErrAvatarNotFound)
+
+	req := httptest.NewRequest("GET", "/api/users/bob/avatar", nil)
+	req.SetPathValue("username", "bob")
+	rr := httptest.NewRecorder()
+	GetAvatarHandler(mockUsecase).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "image/png", rr.Header().Get("Content-Type"))
+	assert.Equal(t, "png", rr.Body.String())
+
+	req = httptest.NewRequest("GET", "/api/users/carol/avatar", nil)
+	req.SetPathValue("username", "carol")
+	rr = httptest.NewRecorder()
+	GetAvatarHandler(mockUsecase).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	mockUsecase.AssertExpectations(t)
+}
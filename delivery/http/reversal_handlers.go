@@ -0,0 +1,48 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"ttavito/domain/entities"
+	"ttavito/internal"
+)
+
+func ReverseTransferHandler(uc UsecaseShop) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, ok := r.Context().Value(internal.ValidReverseKey).(entities.ReverseTransferRequest)
+		if !ok {
+			http.Error(w, "Invalid request", http.StatusInternalServerError)
+			return
+		}
+
+		admin, ok := r.Context().Value(internal.UsernameContextKey).(string)
+		if !ok {
+			http.Error(w, "Can't grab username from JWT", http.StatusInternalServerError)
+			return
+		}
+
+		res, err := uc.ReverseTransfer(r.Context(), admin, req)
+		if err != nil {
+			if writeFrozenError(w, err) {
+				return
+			}
+			switch {
+			case errors.Is(err, entities.ErrTransferNotFound):
+				http.Error(w, err.Error(), http.StatusNotFound)
+			case errors.Is(err, entities.ErrTransferAlreadyReversed),
+				errors.Is(err, entities.ErrCannotReverseReversal),
+				errors.Is(err, entities.ErrReversalInsufficientFunds),
+				errors.Is(err, entities.ErrUserDeactivated):
+				http.Error(w, err.Error(), http.StatusConflict)
+			default:
+				http.Error(w, "Can't reverse transfer", http.StatusInternalServerError)
+			}
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(res)
+	}
+}
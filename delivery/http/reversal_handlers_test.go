@@ -0,0 +1,82 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ttavito/domain/entities"
+	"ttavito/internal"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestReverseTransferHandler_Success(t *testing.T) {
+	reverseRequest := entities.ReverseTransferRequest{TransferID: "0b6b1f8e-3f43-4a53-9a4c-2f0f6b0d8f11"}
+
+	mockUsecase := new(MockUsecase)
+	mockUsecase.On("ReverseTransfer", mock.Anything, "admin", reverseRequest).Return(&entities.ReversalResponse{
+		ID:         "5d1c2e64-8c5e-4f4a-bd0e-2b0c8a1f7c22",
+		ReversalOf: reverseRequest.TransferID,
+		FromUser:   "recipient_user",
+		ToUser:     "test_user",
+		Amount:     50,
+	}, nil)
+
+	req := httptest.NewRequest("POST", "/api/admin/transfers/"+reverseRequest.TransferID+"/reverse", nil)
+	ctx := context.WithValue(req.Context(), internal.ValidReverseKey, reverseRequest)
+	req = req.WithContext(context.WithValue(ctx, internal.UsernameContextKey, "admin"))
+
+	rr := httptest.NewRecorder()
+	handler := ReverseTransferHandler(mockUsecase)
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var response entities.ReversalResponse
+	err := json.NewDecoder(rr.Body).Decode(&response)
+	assert.NoError(t, err)
+	assert.Equal(t, reverseRequest.TransferID, response.ReversalOf)
+	assert.Equal(t, 50, response.Amount)
+
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestReverseTransferHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{"not found", entities.ErrTransferNotFound, http.StatusNotFound},
+		{"already reversed", entities.ErrTransferAlreadyReversed, http.StatusConflict},
+		{"already spent", entities.ErrReversalInsufficientFunds, http.StatusConflict},
+		{"sender deactivated", entities.ErrUserDeactivated, http.StatusConflict},
+		{"sender frozen", entities.ErrRecipientFrozen, http.StatusForbidden},
+		{"internal", errors.New("db is down"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reverseRequest := entities.ReverseTransferRequest{TransferID: "0b6b1f8e-3f43-4a53-9a4c-2f0f6b0d8f11"}
+
+			mockUsecase := new(MockUsecase)
+			mockUsecase.On("ReverseTransfer", mock.Anything, "admin", reverseRequest).Return((*entities.ReversalResponse)(nil), tt.err)
+
+			req := httptest.NewRequest("POST", "/api/admin/transfers/"+reverseRequest.TransferID+"/reverse", nil)
+			ctx := context.WithValue(req.Context(), internal.ValidReverseKey, reverseRequest)
+			req = req.WithContext(context.WithValue(ctx, internal.UsernameContextKey, "admin"))
+
+			rr := httptest.NewRecorder()
+			handler := ReverseTransferHandler(mockUsecase)
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.code, rr.Code)
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
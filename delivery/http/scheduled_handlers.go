@@ -0,0 +1,110 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"ttavito/domain/entities"
+	"ttavito/internal"
+)
+
+func CreateScheduledTransferHandler(uc UsecaseShop) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, ok := r.Context().Value(internal.ValidScheduleKey).(entities.ScheduledTransferRequest)
+		if !ok {
+			http.Error(w, "Invalid request", http.StatusInternalServerError)
+			return
+		}
+
+		username, ok := r.Context().Value(internal.UsernameContextKey).(string)
+		if !ok {
+			http.Error(w, "Can't grab username from JWT", http.StatusInternalServerError)
+			return
+		}
+
+		res, err := uc.CreateScheduledTransfer(r.Context(), username, req)
+		if err != nil {
+			writeScheduledTransferError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(res)
+	}
+}
+
+func GetScheduledTransfersHandler(uc UsecaseShop) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, ok := r.Context().Value(internal.UsernameContextKey).(string)
+		if !ok {
+			http.Error(w, "Can't grab username from JWT", http.StatusInternalServerError)
+			return
+		}
+
+		res, err := uc.GetScheduledTransfers(r.Context(), username)
+		if err != nil {
+			http.Error(w, "Can't get scheduled transfers", http.StatusInternalServerError)
+			return
+		}
+		if res == nil {
+			res = []entities.ScheduledTransfer{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(res)
+	}
+}
+
+func PauseScheduledTransferHandler(uc UsecaseShop) http.HandlerFunc {
+	return changeScheduledTransferHandler(uc.PauseScheduledTransfer)
+}
+
+func ResumeScheduledTransferHandler(uc UsecaseShop) http.HandlerFunc {
+	return changeScheduledTransferHandler(uc.ResumeScheduledTransfer)
+}
+
+func CancelScheduledTransferHandler(uc UsecaseShop) http.HandlerFunc {
+	return changeScheduledTransferHandler(uc.CancelScheduledTransfer)
+}
+
+func changeScheduledTransferHandler(change func(ctx context.Context, username, id string) (*entities.ScheduledTransfer, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := r.Context().Value(internal.ValidPathIDKey).(string)
+		if !ok {
+			http.Error(w, "Invalid request", http.StatusInternalServerError)
+			return
+		}
+
+		username, ok := r.Context().Value(internal.UsernameContextKey).(string)
+		if !ok {
+			http.Error(w, "Can't grab username from JWT", http.StatusInternalServerError)
+			return
+		}
+
+		res, err := change(r.Context(), username, id)
+		if err != nil {
+			writeScheduledTransferError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(res)
+	}
+}
+
+func writeScheduledTransferError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, entities.ErrScheduledTransferNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, entities.ErrInvalidScheduleTransition):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, entities.ErrInvalidSchedule),
+		errors.Is(err, entities.ErrScheduleNeverRuns),
+		errors.Is(err, entities.ErrUserNotFound),
+		errors.Is(err, entities.ErrUserDeactivated),
+		errors.Is(err, entities.ErrTransferToSelf):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, "Can't process scheduled transfer", http.StatusInternalServerError)
+	}
+}
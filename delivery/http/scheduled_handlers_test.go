@@ -0,0 +1,99 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ttavito/domain/entities"
+	"ttavito/internal"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateScheduledTransferHandler_Success(t *testing.T) {
+	scheduleRequest := entities.ScheduledTransferRequest{ToUser: "recipient_user", Amount: 10, Cron: "0 9 1 * *"}
+
+	mockUsecase := new(MockUsecase)
+	mockUsecase.On("CreateScheduledTransfer", mock.Anything, "test_user", scheduleRequest).Return(&entities.ScheduledTransfer{
+		ID:       "0b6b1f8e-3f43-4a53-9a4c-2f0f6b0d8f11",
+		FromUser: "test_user",
+		ToUser:   "recipient_user",
+		Amount:   10,
+		Cron:     "0 9 1 * *",
+		Status:   entities.ScheduleStatusActive,
+	}, nil)
+
+	req := httptest.NewRequest("POST", "/api/scheduledTransfers", nil)
+	req = req.WithContext(context.WithValue(req.Context(), internal.UsernameContextKey, "test_user"))
+	req = req.WithContext(context.WithValue(req.Context(), internal.ValidScheduleKey, scheduleRequest))
+
+	rr := httptest.NewRecorder()
+	handler := CreateScheduledTransferHandler(mockUsecase)
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+
+	var response entities.ScheduledTransfer
+	err := json.NewDecoder(rr.Body).Decode(&response)
+	assert.NoError(t, err)
+	assert.Equal(t, entities.ScheduleStatusActive, response.Status)
+
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestGetScheduledTransfersHandler_ShowsFailures(t *testing.T) {
+	mockUsecase := new(MockUsecase)
+	mockUsecase.On("GetScheduledTransfers", mock.Anything, "test_user").Return([]entities.ScheduledTransfer{
+		{
+			ID:           "0b6b1f8e-3f43-4a53-9a4c-2f0f6b0d8f11",
+			FromUser:     "test_user",
+			ToUser:       "recipient_user",
+			Amount:       10,
+			Cron:         "0 9 1 * *",
+			Status:       entities.ScheduleStatusActive,
+			LastStatus:   entities.ScheduleRunInsufficientBalance,
+			LastError:    entities.ErrNotEnoughBalance.Error(),
+			FailureCount: 1,
+		},
+	}, nil)
+
+	req := httptest.NewRequest("GET", "/api/scheduledTransfers", nil)
+	req = req.WithContext(context.WithValue(req.Context(), internal.UsernameContextKey, "test_user"))
+
+	rr := httptest.NewRecorder()
+	handler := GetScheduledTransfersHandler(mockUsecase)
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var response []entities.ScheduledTransfer
+	err := json.NewDecoder(rr.Body).Decode(&response)
+	assert.NoError(t, err)
+	assert.Len(t, response, 1)
+	assert.Equal(t, entities.ScheduleRunInsufficientBalance, response[0].LastStatus)
+
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestCancelScheduledTransferHandler_NotFound(t *testing.T) {
+	id := "0b6b1f8e-3f43-4a53-9a4c-2f0f6b0d8f11"
+
+	mockUsecase := new(MockUsecase)
+	mockUsecase.On("CancelScheduledTransfer", mock.Anything, "test_user", id).Return((*entities.ScheduledTransfer)(nil), entities.ErrScheduledTransferNotFound)
+
+	req := httptest.NewRequest("POST", "/api/scheduledTransfers/"+id+"/cancel", nil)
+	req = req.WithContext(context.WithValue(req.Context(), internal.UsernameContextKey, "test_user"))
+	req = req.WithContext(context.WithValue(req.Context(), internal.ValidPathIDKey, id))
+
+	rr := httptest.NewRecorder()
+	handler := CancelScheduledTransferHandler(mockUsecase)
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	mockUsecase.AssertExpectations(t)
+}
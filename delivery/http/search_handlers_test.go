@@ -0,0 +1,35 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ttavito/domain/entities"
+	"ttavito/internal"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSearchUsersHandler(t *testing.T) {
+	mockUsecase := new(MockUsecase)
+	search := entities.UserSearchRequest{Query: "bo", Limit: 1}
+	next := 1
+	res := &entities.UserSearchResponse{
+		Users:      []entities.UserSearchResult{{Username: "bob", DisplayName: "Bob"}},
+		NextOffset: &next,
+	}
+	mockUsecase.On("SearchUsers", mock.Anything, "test_user", search).Return(res, nil)
+
+	req := httptest.NewRequest("GET", "/api/users/search?q=bo&limit=1", nil)
+	ctx := context.WithValue(req.Context(), internal.ValidSearchKey, search)
+	ctx = context.WithValue(ctx, internal.UsernameContextKey, "test_user")
+	rr := httptest.NewRecorder()
+	SearchUsersHandler(mockUsecase).ServeHTTP(rr, req.WithContext(ctx))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"users": [{"username": "bob", "displayName": "Bob"}], "nextOffset": 1}`, rr.Body.String())
+	mockUsecase.AssertExpectations(t)
+}
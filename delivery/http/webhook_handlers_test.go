@@ -0,0 +1,93 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ttavito/domain/entities"
+	"ttavito/internal"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateWebhookHandler(t *testing.T) {
+	mockUsecase := new(MockUsecase)
+	hook := entities.WebhookRequest{URL: "https://hooks.example.com/coins", EventTypes: []string{entities.EventCoinsTransferred}}
+	mockUsecase.On("CreateWebhook", mock.Anything, "admin", hook).Return(&entities.WebhookSubscription{
+		ID: "11111111-1111-1111-1111-111111111111", URL: hook.URL, EventTypes: hook.EventTypes, Secret: "generated",
+	}, nil)
+
+	req := httptest.NewRequest("POST", "/api/admin/webhooks", nil)
+	ctx := context.WithValue(req.Context(), internal.ValidWebhookKey, hook)
+	ctx = context.WithValue(ctx, internal.UsernameContextKey, "admin")
+	rr := httptest.NewRecorder()
+	CreateWebhookHandler(mockUsecase).ServeHTTP(rr, req.WithContext(ctx))
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"secret":"generated"`)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestDeleteWebhookHandler(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{"deleted", nil, http.StatusNoContent},
+		{"not found", entities.ErrWebhookNotFound, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := new(MockUsecase)
+			id := "11111111-1111-1111-1111-111111111111"
+			mockUsecase.On("DeleteWebhook", mock.Anything, "admin", id).Return(tt.err)
+
+			req := httptest.NewRequest("DELETE", "/api/admin/webhooks/"+id, nil)
+			ctx := context.WithValue(req.Context(), internal.ValidPathIDKey, id)
+			ctx = context.WithValue(ctx, internal.UsernameContextKey, "admin")
+			rr := httptest.NewRecorder()
+			DeleteWebhookHandler(mockUsecase).ServeHTTP(rr, req.WithContext(ctx))
+
+			assert.Equal(t, tt.code, rr.Code)
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRetryWebhookDeliveryHandler(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{"retried", nil, http.StatusOK},
+		{"not found", entities.ErrWebhookDeliveryNotFound, http.StatusNotFound},
+		{"not dead", entities.ErrWebhookDeliveryNotDead, http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := new(MockUsecase)
+			id := "22222222-2222-2222-2222-222222222222"
+			var res *entities.WebhookDelivery
+			if tt.err == nil {
+				res = &entities.WebhookDelivery{ID: id, Status: entities.WebhookDeliveryPending}
+			}
+			mockUsecase.On("RetryWebhookDelivery", mock.Anything, "admin", id).Return(res, tt.err)
+
+			req := httptest.NewRequest("POST", "/api/admin/webhooks/deliveries/"+id+"/retry", nil)
+			ctx := context.WithValue(req.Context(), internal.ValidPathIDKey, id)
+			ctx = context.WithValue(ctx, internal.UsernameContextKey, "admin")
+			rr := httptest.NewRecorder()
+			RetryWebhookDeliveryHandler(mockUsecase).ServeHTTP(rr, req.WithContext(ctx))
+
+			assert.Equal(t, tt.code, rr.Code)
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
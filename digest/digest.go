@@ -0,0 +1,58 @@
+package digest
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"text/template"
+	"time"
+
+	"ttavito/domain/entities"
+)
+
+//go:embed templates
+var templates embed.FS
+
+var funcs = map[string]any{
+	"date": func(t time.Time) string { return t.Format("02.01.2006") },
+}
+
+var (
+	textTemplate = template.Must(template.New("digest.txt.tmpl").Funcs(funcs).ParseFS(templates, "templates/digest.txt.tmpl"))
+	htmlTemplate = htmltemplate.Must(htmltemplate.New("digest.html.tmpl").Funcs(funcs).ParseFS(templates, "templates/digest.html.tmpl"))
+)
+
+// WeekStart возвращает начало последней завершившейся недели: понедельник 00:00 UTC
+func WeekStart(now time.Time) time.Time {
+	now = now.UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	// Воскресенье - седьмой день недели
+	sinceMonday := (int(today.Weekday()) + 6) % 7
+	return today.AddDate(0, 0, -sinceMonday-7)
+}
+
+type view struct {
+	entities.DigestActivity
+	LastDay time.Time
+}
+
+// Render готовит письмо-сводку на адрес to
+func Render(to string, a entities.DigestActivity) (entities.EmailMessage, error) {
+	v := view{DigestActivity: a, LastDay: a.WeekEnd.AddDate(0, 0, -1)}
+
+	var text, html bytes.Buffer
+	if err := textTemplate.Execute(&text, v); err != nil {
+		return entities.EmailMessage{}, err
+	}
+	if err := htmlTemplate.Execute(&html, v); err != nil {
+		return entities.EmailMessage{}, err
+	}
+
+	return entities.EmailMessage{
+		To:      to,
+		Subject: fmt.Sprintf("Ваша неделя: получено %d, потрачено %d монет", a.Received, a.Spent),
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
@@ -0,0 +1,61 @@
+package digest
+
+import (
+	"testing"
+	"time"
+
+	"ttavito/domain/entities"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWeekStart(t *testing.T) {
+	monday := time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want time.Time
+	}{
+		{"monday morning", time.Date(2025, 3, 10, 0, 5, 0, 0, time.UTC), monday},
+		{"wednesday", time.Date(2025, 3, 12, 15, 0, 0, 0, time.UTC), monday},
+		{"sunday night", time.Date(2025, 3, 16, 23, 59, 0, 0, time.UTC), monday},
+		{"next monday", time.Date(2025, 3, 17, 0, 0, 0, 0, time.UTC), monday.AddDate(0, 0, 7)},
+		// В Москве уже понедельник, а по UTC ещё воскресенье
+		{"other timezone", time.Date(2025, 3, 17, 1, 0, 0, 0, time.FixedZone("MSK", 3*3600)), monday},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, WeekStart(tt.now))
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	weekStart := time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)
+	msg, err := Render("alice@example.com", entities.DigestActivity{
+		Username:      "alice",
+		DisplayName:   "Алиса <admin>",
+		WeekStart:     weekStart,
+		WeekEnd:       weekStart.AddDate(0, 0, 7),
+		Received:      150,
+		ReceivedCount: 2,
+		Spent:         80,
+		PurchaseCount: 1,
+		Balance:       1070,
+		TopSenders:    []entities.DigestCounterparty{{Username: "bob", Amount: 100}, {Username: "carol", Amount: 50}},
+		Items:         []entities.DigestItem{{Item: "cup", Count: 1}},
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, "alice@example.com", msg.To)
+	assert.Equal(t, "Ваша неделя: получено 150, потрачено 80 монет", msg.Subject)
+	assert.Contains(t, msg.Text, "03.03.2025 - 09.03.2025")
+	assert.Contains(t, msg.Text, "bob: 100")
+	assert.Contains(t, msg.Text, "cup x1")
+	assert.Contains(t, msg.Text, "1070")
+	assert.Contains(t, msg.HTML, "bob")
+	// Имя задаёт сам пользователь, в HTML-версии оно экранируется
+	assert.Contains(t, msg.HTML, "Алиса &lt;admin&gt;")
+	assert.NotContains(t, msg.HTML, "<admin>")
+}
@@ -0,0 +1,222 @@
+// Package directory разбирает и проверяет файл оргструктуры перед импортом.
+// Файл - список сотрудников с отделом, командой, руководителем и ролью:
+//
+//	CSV:  username,department,team,manager,role (заголовок обязателен, team/manager/role можно опустить)
+//	JSON: {"employees": [{"username": "...", "department": "...", "team": "...", "manager": "...", "role": "..."}]}
+package directory
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"ttavito/domain/entities"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+)
+
+var csvColumns = []string{"username", "department", "team", "manager", "role"}
+
+// FormatFromPath определяет формат по расширению файла
+func FormatFromPath(path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return FormatCSV, nil
+	case ".json":
+		return FormatJSON, nil
+	}
+	return "", fmt.Errorf("unknown org chart format of %q, use .csv or .json", path)
+}
+
+func Parse(r io.Reader, format string) ([]entities.OrgChartEntry, error) {
+	var entries []entities.OrgChartEntry
+	var err error
+
+	switch format {
+	case FormatCSV:
+		entries, err = parseCSV(r)
+	case FormatJSON:
+		entries, err = parseJSON(r)
+	default:
+		return nil, fmt.Errorf("unknown org chart format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range entries {
+		e := &entries[i]
+		e.Username = strings.TrimSpace(e.Username)
+		e.Department = strings.TrimSpace(e.Department)
+		e.Team = strings.TrimSpace(e.Team)
+		e.Manager = strings.TrimSpace(e.Manager)
+		e.Role = strings.TrimSpace(e.Role)
+	}
+	return entries, nil
+}
+
+func parseCSV(r io.Reader) ([]entities.OrgChartEntry, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range csvColumns[:2] {
+		if _, ok := index[required]; !ok {
+			return nil, fmt.Errorf("CSV header must contain %q column", required)
+		}
+	}
+
+	column := func(record []string, name string) string {
+		if i, ok := index[name]; ok {
+			return record[i]
+		}
+		return ""
+	}
+
+	var entries []entities.OrgChartEntry
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, entities.OrgChartEntry{
+			Username:   column(record, "username"),
+			Department: column(record, "department"),
+			Team:       column(record, "team"),
+			Manager:    column(record, "manager"),
+			Role:       column(record, "role"),
+		})
+	}
+}
+
+func parseJSON(r io.Reader) ([]entities.OrgChartEntry, error) {
+	var chart struct {
+		Employees []entities.OrgChartEntry `json:"employees"`
+	}
+
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&chart); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	return chart.Employees, nil
+}
+
+// Validate проверяет файл целиком и возвращает все найденные ошибки сразу.
+// Существование пользователей проверяется при импорте, в базе.
+func Validate(entries []entities.OrgChartEntry) error {
+	if len(entries) == 0 {
+		return errors.New("org chart is empty")
+	}
+
+	var errs []error
+	byUsername := make(map[string]entities.OrgChartEntry, len(entries))
+	teamDepartment := make(map[string]string)
+	departmentHead := make(map[string]string)
+	teamLead := make(map[string]string)
+
+	for i, e := range entries {
+		// Номер записи, для CSV - номер строки без заголовка
+		n := i + 1
+
+		if e.Username == "" || e.Department == "" {
+			errs = append(errs, fmt.Errorf("entry %d: username and department are required", n))
+			continue
+		}
+		if _, ok := byUsername[e.Username]; ok {
+			errs = append(errs, fmt.Errorf("entry %d: %s is listed twice", n, e.Username))
+			continue
+		}
+		byUsername[e.Username] = e
+
+		if e.Manager == e.Username {
+			errs = append(errs, fmt.Errorf("entry %d: %s cannot be their own manager", n, e.Username))
+		}
+
+		if e.Team != "" {
+			if dep, ok := teamDepartment[e.Team]; ok && dep != e.Department {
+				errs = append(errs, fmt.Errorf("entry %d: team %s belongs to departments %s and %s", n, e.Team, dep, e.Department))
+			}
+			teamDepartment[e.Team] = e.Department
+		}
+
+		switch e.Role {
+		case "":
+		case entities.OrgRoleDepartmentHead:
+			if head, ok := departmentHead[e.Department]; ok {
+				errs = append(errs, fmt.Errorf("entry %d: department %s already has head %s", n, e.Department, head))
+			}
+			departmentHead[e.Department] = e.Username
+		case entities.OrgRoleTeamLead:
+			if e.Team == "" {
+				errs = append(errs, fmt.Errorf("entry %d: team_lead %s has no team", n, e.Username))
+				break
+			}
+			if lead, ok := teamLead[e.Team]; ok {
+				errs = append(errs, fmt.Errorf("entry %d: team %s already has lead %s", n, e.Team, lead))
+			}
+			teamLead[e.Team] = e.Username
+		default:
+			errs = append(errs, fmt.Errorf("entry %d: unknown role %q", n, e.Role))
+		}
+	}
+
+	errs = append(errs, findManagerCycles(byUsername)...)
+	return errors.Join(errs...)
+}
+
+// findManagerCycles ищет циклы в цепочках руководителей внутри файла
+func findManagerCycles(byUsername map[string]entities.OrgChartEntry) []error {
+	const (
+		unvisited = iota
+		inProgress
+		done
+	)
+
+	state := make(map[string]int, len(byUsername))
+	var errs []error
+
+	for start := range byUsername {
+		var path []string
+		for username := start; ; {
+			e, inFile := byUsername[username]
+			if !inFile || state[username] == done {
+				break
+			}
+			if state[username] == inProgress {
+				errs = append(errs, fmt.Errorf("manager cycle: %s", strings.Join(append(path, username), " -> ")))
+				break
+			}
+			state[username] = inProgress
+			path = append(path, username)
+			// Сам себе руководитель - отдельная ошибка в Validate
+			if e.Manager == "" || e.Manager == username {
+				break
+			}
+			username = e.Manager
+		}
+		for _, username := range path {
+			state[username] = done
+		}
+	}
+	return errs
+}
@@ -0,0 +1,114 @@
+package directory
+
+import (
+	"strings"
+	"testing"
+
+	"ttavito/domain/entities"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatFromPath(t *testing.T) {
+	format, err := FormatFromPath("org/chart.CSV")
+	assert.NoError(t, err)
+	assert.Equal(t, FormatCSV, format)
+
+	format, err = FormatFromPath("chart.json")
+	assert.NoError(t, err)
+	assert.Equal(t, FormatJSON, format)
+
+	_, err = FormatFromPath("chart.xlsx")
+	assert.Error(t, err)
+}
+
+func TestParseCSV(t *testing.T) {
+	data := "username,department,team,manager,role\n" +
+		"alice,platform,,,department_head\n" +
+		"bob, platform,core,alice,team_lead\n"
+
+	entries, err := Parse(strings.NewReader(data), FormatCSV)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []entities.OrgChartEntry{
+		{Username: "alice", Department: "platform", Role: entities.OrgRoleDepartmentHead},
+		{Username: "bob", Department: "platform", Team: "core", Manager: "alice", Role: entities.OrgRoleTeamLead},
+	}, entries)
+}
+
+func TestParseCSVOptionalColumns(t *testing.T) {
+	entries, err := Parse(strings.NewReader("department,username\nplatform,carol\n"), FormatCSV)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []entities.OrgChartEntry{{Username: "carol", Department: "platform"}}, entries)
+
+	_, err = Parse(strings.NewReader("username,team\ncarol,core\n"), FormatCSV)
+	assert.Error(t, err)
+}
+
+func TestParseJSON(t *testing.T) {
+	data := `{"employees": [{"username": "alice", "department": "platform", "role": "department_head"}]}`
+
+	entries, err := Parse(strings.NewReader(data), FormatJSON)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []entities.OrgChartEntry{
+		{Username: "alice", Department: "platform", Role: entities.OrgRoleDepartmentHead},
+	}, entries)
+
+	_, err = Parse(strings.NewReader(`{"people": []}`), FormatJSON)
+	assert.Error(t, err)
+}
+
+func TestValidate(t *testing.T) {
+	valid := []entities.OrgChartEntry{
+		{Username: "alice", Department: "platform", Role: entities.OrgRoleDepartmentHead},
+		{Username: "bob", Department: "platform", Team: "core", Manager: "alice", Role: entities.OrgRoleTeamLead},
+		{Username: "carol", Department: "platform", Team: "core", Manager: "bob"},
+		// Руководитель может быть не из файла, его наличие проверяется в базе
+		{Username: "dave", Department: "sales", Manager: "eve"},
+	}
+	assert.NoError(t, Validate(valid))
+
+	tests := []struct {
+		name    string
+		entries []entities.OrgChartEntry
+		want    string
+	}{
+		{"empty", nil, "empty"},
+		{"missing department", []entities.OrgChartEntry{{Username: "alice"}}, "required"},
+		{"duplicate user", []entities.OrgChartEntry{
+			{Username: "alice", Department: "platform"},
+			{Username: "alice", Department: "sales"},
+		}, "listed twice"},
+		{"team in two departments", []entities.OrgChartEntry{
+			{Username: "alice", Department: "platform", Team: "core"},
+			{Username: "bob", Department: "sales", Team: "core"},
+		}, "belongs to departments"},
+		{"two heads", []entities.OrgChartEntry{
+			{Username: "alice", Department: "platform", Role: entities.OrgRoleDepartmentHead},
+			{Username: "bob", Department: "platform", Role: entities.OrgRoleDepartmentHead},
+		}, "already has head"},
+		{"lead without team", []entities.OrgChartEntry{
+			{Username: "alice", Department: "platform", Role: entities.OrgRoleTeamLead},
+		}, "has no team"},
+		{"unknown role", []entities.OrgChartEntry{
+			{Username: "alice", Department: "platform", Role: "ceo"},
+		}, "unknown role"},
+		{"self manager", []entities.OrgChartEntry{
+			{Username: "alice", Department: "platform", Manager: "alice"},
+		}, "own manager"},
+		{"manager cycle", []entities.OrgChartEntry{
+			{Username: "alice", Department: "platform", Manager: "bob"},
+			{Username: "bob", Department: "platform", Manager: "carol"},
+			{Username: "carol", Department: "platform", Manager: "alice"},
+		}, "manager cycle"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.entries)
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
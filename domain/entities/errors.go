@@ -0,0 +1,92 @@
+package entities
+
+import "errors"
+
+var (
+	ErrUserNotFound     = errors.New("user not found")
+	ErrUserDeactivated  = errors.New("user is deactivated")
+	ErrProductNotFound  = errors.New("product not found")
+	ErrNotEnoughBalance = errors.New("not enough balance")
+	ErrGiftToSelf       = errors.New("cannot gift an item to yourself")
+	ErrTransferToSelf   = errors.New("cannot send coins to yourself")
+
+	ErrTransferNotFound          = errors.New("transfer not found")
+	ErrTransferAlreadyReversed   = errors.New("transfer already reversed")
+	ErrCannotReverseReversal     = errors.New("reversal transfer cannot be reversed")
+	ErrReversalInsufficientFunds = errors.New("recipient has already spent the coins")
+
+	ErrPendingTransferNotFound = errors.New("pending transfer not found")
+	ErrPendingTransferResolved = errors.New("pending transfer already resolved")
+	ErrPendingTransferExpired  = errors.New("pending transfer expired")
+
+	ErrScheduledTransferNotFound = errors.New("scheduled transfer not found")
+	ErrInvalidScheduleTransition = errors.New("scheduled transfer cannot change to this status")
+	ErrScheduleNeverRuns         = errors.New("schedule has no upcoming runs")
+	ErrInvalidSchedule           = errors.New("invalid schedule")
+
+	ErrInvalidAllowancePeriod = errors.New("allowance period must be monthly, weekly or daily")
+
+	ErrBulkGrantInvalid    = errors.New("bulk grant contains invalid rows, nothing was granted")
+	ErrGrantAmountTooLarge = errors.New("grant amount exceeds the maximum")
+
+	ErrDepartmentNotFound   = errors.New("department not found")
+	ErrDepartmentExists     = errors.New("department already exists")
+	ErrNotDepartmentManager = errors.New("only the department manager can spend its budget")
+	ErrNotDepartmentMember  = errors.New("recipient is not a member of the department")
+	ErrBudgetExhausted      = errors.New("department budget exhausted")
+
+	ErrInvalidBalancePolicy     = errors.New("balance policy must be forfeit or redistribute")
+	ErrInvalidOffboardRecipient = errors.New("recipient must be another active user")
+
+	ErrAccountFrozen    = errors.New("account is frozen")
+	ErrRecipientFrozen  = errors.New("recipient account is frozen")
+	ErrAlreadyFrozen    = errors.New("account is already frozen")
+	ErrAccountNotFrozen = errors.New("account is not frozen")
+
+	ErrFraudFindingNotFound = errors.New("fraud finding not found")
+	ErrFraudFindingReviewed = errors.New("fraud finding already reviewed")
+
+	ErrWebhookNotFound         = errors.New("webhook not found")
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+	ErrWebhookDeliveryNotDead  = errors.New("only dead deliveries can be retried")
+
+	ErrNotificationNotFound = errors.New("notification not found")
+
+	// Письмо по такому адресу не уйдёт и при повторной попытке
+	ErrInvalidEmailAddress = errors.New("invalid email address")
+	ErrEmailRejected       = errors.New("email recipient rejected")
+
+	ErrTooManyEventStreams    = errors.New("too many open event streams")
+	ErrEventStreamUnavailable = errors.New("event stream is not available")
+
+	ErrAvatarNotFound        = errors.New("avatar not found")
+	ErrAvatarStorageDisabled = errors.New("avatar storage is not configured")
+
+	ErrTeamNotFound             = errors.New("team not found")
+	ErrOrgChartUnknownUsers     = errors.New("org chart references unknown users")
+	ErrOrgChartNoDepartmentHead = errors.New("new department has no department_head")
+)
+
+const (
+	PolicyAmountExceedsMax       = "amount_exceeds_max"
+	PolicyDailyLimitExceeded     = "daily_limit_exceeded"
+	PolicyWeeklyLimitExceeded    = "weekly_limit_exceeded"
+	PolicyRecipientLimitExceeded = "recipient_limit_exceeded"
+	PolicyRecipientCooldown      = "recipient_cooldown"
+)
+
+// Коды ответа 403 для замороженных счетов
+const (
+	FrozenCodeAccount   = "account_frozen"
+	FrozenCodeRecipient = "recipient_frozen"
+)
+
+// PolicyViolation - перевод отклонён правилами лимитов, Code отдаётся клиенту
+type PolicyViolation struct {
+	Code    string
+	Message string
+}
+
+func (e *PolicyViolation) Error() string {
+	return e.Message
+}
@@ -1,9 +1,30 @@
 package entities
 
+import (
+	"encoding/json"
+	"time"
+)
+
 type InfoResponse struct {
 	Coins       int                 `json:"coins"`
 	Inventory   []ItemResponse      `json:"inventory"`
 	CoinHistory CoinHistoryResponse `json:"coinHistory"`
+	Gifts       GiftHistoryResponse `json:"gifts"`
+	// Монеты, которые скоро сгорят, по дате сгорания
+	ExpiringSoon []ExpiringCoins `json:"expiringSoon,omitempty"`
+	// Есть, пока счёт заморожен
+	Frozen *FreezeStatus `json:"frozen,omitempty"`
+}
+
+type FreezeStatus struct {
+	Reason        string    `json:"reason"`
+	BlockIncoming bool      `json:"blockIncoming"`
+	FrozenAt      time.Time `json:"frozenAt"`
+}
+
+type ExpiringCoins struct {
+	Amount    int       `json:"amount"`
+	ExpiresAt time.Time `json:"expiresAt"`
 }
 
 type ItemResponse struct {
@@ -16,17 +37,165 @@ type CoinHistoryResponse struct {
 	Sent     []SentResponse     `json:"sent"`
 }
 
-type ReceivedResponse struct {
+type GiftHistoryResponse struct {
+	Received []GiftReceivedResponse `json:"received"`
+	Sent     []GiftSentResponse     `json:"sent"`
+}
+
+type GiftReceivedResponse struct {
 	FromUser string `json:"fromUser"`
-	Amount   int    `json:"amount"`
+	Item     string `json:"item"`
+	Message  string `json:"message,omitempty"`
+}
+
+type GiftSentResponse struct {
+	ToUser  string `json:"toUser"`
+	Item    string `json:"item"`
+	Message string `json:"message,omitempty"`
+}
+
+type ReceivedResponse struct {
+	ID              string `json:"id,omitempty"`
+	FromUser        string `json:"fromUser"`
+	FromDisplayName string `json:"fromDisplayName,omitempty"`
+	Amount          int    `json:"amount"`
+	Message         string `json:"message,omitempty"`
+	ReversalOf      string `json:"reversalOf,omitempty"`
 }
 
 type SentResponse struct {
-	ToUser string `json:"toUser"`
-	Amount int    `json:"amount"`
+	ID            string `json:"id,omitempty"`
+	ToUser        string `json:"toUser"`
+	ToDisplayName string `json:"toDisplayName,omitempty"`
+	Amount        int    `json:"amount"`
+	Message       string `json:"message,omitempty"`
+	ReversalOf    string `json:"reversalOf,omitempty"`
+}
+
+type SendCoinRequest struct {
+	ToUser  string `json:"toUser"`
+	Amount  int    `json:"amount"`
+	Message string `json:"message,omitempty"`
+	// Монеты замораживаются у отправителя до подтверждения получателем
+	RequireAcceptance bool `json:"requireAcceptance,omitempty"`
+}
+
+const (
+	PendingStatusPending  = "pending"
+	PendingStatusAccepted = "accepted"
+	PendingStatusDeclined = "declined"
+	PendingStatusExpired  = "expired"
+)
+
+type PendingTransfer struct {
+	ID         string    `json:"id"`
+	FromUser   string    `json:"fromUser"`
+	ToUser     string    `json:"toUser"`
+	Amount     int       `json:"amount"`
+	Message    string    `json:"message,omitempty"`
+	Status     string    `json:"status"`
+	TransferID string    `json:"transferId,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+}
+
+type PendingTransfersResponse struct {
+	Incoming []PendingTransfer `json:"incoming"`
+	Outgoing []PendingTransfer `json:"outgoing"`
+}
+
+type BatchSendCoinRequest struct {
+	Transfers []SendCoinRequest `json:"transfers"`
 }
 
-type SendCoinRequest SentResponse
+const (
+	BatchStatusSent       = "sent"
+	BatchStatusFailed     = "failed"
+	BatchStatusRolledBack = "rolledBack"
+)
+
+// Ошибка строки пакета, если причина внутренняя
+const BatchErrorTransferFailed = "transfer failed"
+
+type BatchTransferResult struct {
+	ToUser     string `json:"toUser"`
+	Amount     int    `json:"amount"`
+	Status     string `json:"status"`
+	TransferID string `json:"transferId,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+type BatchSendCoinResponse struct {
+	Results []BatchTransferResult `json:"results"`
+	Total   int                   `json:"total"`
+}
+
+const (
+	ScheduleStatusActive    = "active"
+	ScheduleStatusPaused    = "paused"
+	ScheduleStatusCancelled = "cancelled"
+	ScheduleStatusCompleted = "completed"
+	ScheduleStatusFailed    = "failed"
+
+	ScheduleRunSuccess             = "success"
+	ScheduleRunInsufficientBalance = "insufficient_balance"
+	ScheduleRunPolicyViolation     = "policy_violation"
+	ScheduleRunAccountFrozen       = "account_frozen"
+	ScheduleRunFailed              = "failed"
+)
+
+// ScheduledTransferRequest задаёт либо разовый перевод (RunAt), либо регулярный (Cron)
+type ScheduledTransferRequest struct {
+	ToUser  string     `json:"toUser"`
+	Amount  int        `json:"amount"`
+	Message string     `json:"message,omitempty"`
+	RunAt   *time.Time `json:"runAt,omitempty"`
+	Cron    string     `json:"cron,omitempty"`
+}
+
+type ScheduledTransfer struct {
+	ID           string     `json:"id"`
+	FromUser     string     `json:"fromUser"`
+	ToUser       string     `json:"toUser"`
+	Amount       int        `json:"amount"`
+	Message      string     `json:"message,omitempty"`
+	Cron         string     `json:"cron,omitempty"`
+	Status       string     `json:"status"`
+	NextRunAt    *time.Time `json:"nextRunAt,omitempty"`
+	LastRunAt    *time.Time `json:"lastRunAt,omitempty"`
+	LastStatus   string     `json:"lastStatus,omitempty"`
+	LastError    string     `json:"lastError,omitempty"`
+	FailureCount int        `json:"failureCount"`
+	CreatedAt    time.Time  `json:"createdAt"`
+}
+
+type ScheduledTransferRun struct {
+	ScheduleID string    `json:"scheduleId"`
+	RunAt      time.Time `json:"runAt"`
+	Status     string    `json:"status"`
+	TransferID string    `json:"transferId,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+type GiftRequest struct {
+	Item    string `json:"-"`
+	ToUser  string `json:"toUser"`
+	Message string `json:"message"`
+}
+
+type ReverseTransferRequest struct {
+	TransferID   string `json:"-"`
+	AllowPartial bool   `json:"allowPartial"`
+}
+
+type ReversalResponse struct {
+	ID         string `json:"id"`
+	ReversalOf string `json:"reversalOf"`
+	FromUser   string `json:"fromUser"`
+	ToUser     string `json:"toUser"`
+	Amount     int    `json:"amount"`
+	Partial    bool   `json:"partial"`
+}
 
 type AuthRequest struct {
 	Username string `json:"username"`
@@ -39,4 +208,619 @@ type AuthResponse struct {
 
 type ErrorResponse struct {
 	Errors string `json:"errors"`
+	Code   string `json:"code,omitempty"`
+}
+
+// Периодичность выдачи пособия
+const (
+	AllowanceMonthly = "monthly"
+	AllowanceWeekly  = "weekly"
+	AllowanceDaily   = "daily"
+)
+
+// GrantRequest - начисление монет администратором одному пользователю
+type GrantRequest struct {
+	ToUser string `json:"toUser"`
+	Amount int    `json:"amount"`
+	Reason string `json:"reason"`
+}
+
+type Grant struct {
+	ID        string    `json:"id"`
+	BatchID   string    `json:"batchId,omitempty"`
+	GrantedBy string    `json:"grantedBy"`
+	ToUser    string    `json:"toUser"`
+	Amount    int       `json:"amount"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+const (
+	GrantRowValid       = "valid"
+	GrantRowGranted     = "granted"
+	GrantRowInvalid     = "invalid"
+	GrantRowUnknownUser = "unknown_user"
+)
+
+// BulkGrantRow - строка загруженного CSV, Line - номер строки в файле
+type BulkGrantRow struct {
+	Line    int    `json:"line"`
+	ToUser  string `json:"toUser"`
+	Amount  int    `json:"amount"`
+	Reason  string `json:"reason"`
+	Status  string `json:"status"`
+	Error   string `json:"error,omitempty"`
+	GrantID string `json:"grantId,omitempty"`
+}
+
+type BulkGrantRequest struct {
+	DryRun bool
+	Rows   []BulkGrantRow
+}
+
+// BulkGrantResponse возвращается и для пробного запуска, и при ошибках в строках
+type BulkGrantResponse struct {
+	DryRun  bool           `json:"dryRun"`
+	Valid   bool           `json:"valid"`
+	BatchID string         `json:"batchId,omitempty"`
+	Total   int            `json:"total"`
+	Rows    []BulkGrantRow `json:"rows"`
+}
+
+// DepartmentRequest - создание отдела администратором, Budget - начальный бюджет
+type DepartmentRequest struct {
+	Name    string `json:"name"`
+	Manager string `json:"manager"`
+	Budget  int    `json:"budget"`
+}
+
+type Department struct {
+	Name      string    `json:"name"`
+	Manager   string    `json:"manager"`
+	Budget    int       `json:"budget"`
+	Members   []string  `json:"members"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type DepartmentMembersRequest struct {
+	Department string   `json:"-"`
+	Usernames  []string `json:"usernames"`
+}
+
+type BudgetAllocationRequest struct {
+	Department string `json:"-"`
+	Amount     int    `json:"amount"`
+}
+
+// RecognitionRequest - поощрение сотрудника из бюджета отдела
+type RecognitionRequest struct {
+	Department string `json:"-"`
+	ToUser     string `json:"toUser"`
+	Amount     int    `json:"amount"`
+	Message    string `json:"message,omitempty"`
+}
+
+type Recognition struct {
+	ID         string    `json:"id"`
+	Department string    `json:"department"`
+	FromUser   string    `json:"fromUser"`
+	ToUser     string    `json:"toUser"`
+	Amount     int       `json:"amount"`
+	Message    string    `json:"message,omitempty"`
+	BudgetLeft int       `json:"budgetLeft"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+type DepartmentReportRequest struct {
+	Department string
+	Month      time.Time // первое число месяца, UTC
+}
+
+// DepartmentBudgetReport - движение бюджета отдела за месяц
+type DepartmentBudgetReport struct {
+	Department   string               `json:"department"`
+	Manager      string               `json:"manager"`
+	Month        string               `json:"month"`
+	Allocated    int                  `json:"allocated"`
+	Spent        int                  `json:"spent"`
+	Remaining    int                  `json:"remaining"`
+	Recognitions int                  `json:"recognitions"`
+	Recipients   []RecognitionSummary `json:"recipients"`
+}
+
+type RecognitionSummary struct {
+	ToUser string `json:"toUser"`
+	Amount int    `json:"amount"`
+	Count  int    `json:"count"`
+}
+
+// Роль сотрудника в оргструктуре
+const (
+	OrgRoleDepartmentHead = "department_head"
+	OrgRoleTeamLead       = "team_lead"
+)
+
+// OrgChartEntry - строка файла оргструктуры: один сотрудник
+type OrgChartEntry struct {
+	Username   string `json:"username"`
+	Department string `json:"department"`
+	Team       string `json:"team,omitempty"`
+	Manager    string `json:"manager,omitempty"`
+	Role       string `json:"role,omitempty"`
+}
+
+type OrgChartImportResult struct {
+	DryRun      bool `json:"dryRun"`
+	Departments int  `json:"departments"`
+	Teams       int  `json:"teams"`
+	Users       int  `json:"users"`
+}
+
+type Team struct {
+	Name       string   `json:"name"`
+	Department string   `json:"department"`
+	Lead       string   `json:"lead,omitempty"`
+	Members    []string `json:"members"`
+}
+
+// Profile - публичные данные сотрудника. Отдел, команда и руководитель - из справочника
+type Profile struct {
+	Username    string `json:"username"`
+	DisplayName string `json:"displayName"`
+	Title       string `json:"title,omitempty"`
+	Department  string `json:"department,omitempty"`
+	Team        string `json:"team,omitempty"`
+	Manager     string `json:"manager,omitempty"`
+	AvatarURL   string `json:"avatarUrl,omitempty"`
+
+	AvatarUpdatedAt *time.Time `json:"-"`
+}
+
+// UpdateProfileRequest заменяет отображаемое имя и должность, пустая строка очищает поле
+type UpdateProfileRequest struct {
+	DisplayName string `json:"displayName"`
+	Title       string `json:"title"`
+}
+
+type Avatar struct {
+	Data        []byte
+	ContentType string
+}
+
+type UserSearchRequest struct {
+	Query  string
+	Limit  int
+	Offset int
+}
+
+type UserSearchResult struct {
+	Username    string `json:"username"`
+	DisplayName string `json:"displayName"`
+}
+
+// UserSearchResponse - страница результатов, nextOffset есть, если результаты не закончились
+type UserSearchResponse struct {
+	Users      []UserSearchResult `json:"users"`
+	NextOffset *int               `json:"nextOffset,omitempty"`
+}
+
+// Что делать с остатком баланса уволенного сотрудника
+const (
+	BalancePolicyForfeit      = "forfeit"      // монеты сгорают в пользу магазина
+	BalancePolicyRedistribute = "redistribute" // делятся поровну между recipients
+)
+
+type OffboardRequest struct {
+	Username      string   `json:"-"`
+	BalancePolicy string   `json:"balancePolicy"`
+	Recipients    []string `json:"recipients,omitempty"`
+	Reason        string   `json:"reason"`
+}
+
+type Offboarding struct {
+	ID                 string                `json:"id"`
+	Username           string                `json:"username"`
+	OffboardedBy       string                `json:"offboardedBy"`
+	Reason             string                `json:"reason"`
+	BalancePolicy      string                `json:"balancePolicy"`
+	BalanceAmount      int                   `json:"balanceAmount"`
+	Transfers          []OffboardingTransfer `json:"transfers,omitempty"`
+	PendingRefunded    int                   `json:"pendingRefunded"`
+	SchedulesCancelled int                   `json:"schedulesCancelled"`
+	CreatedAt          time.Time             `json:"createdAt"`
+}
+
+type OffboardingTransfer struct {
+	ToUser     string `json:"toUser"`
+	Amount     int    `json:"amount"`
+	TransferID string `json:"transferId"`
+}
+
+type FreezeRequest struct {
+	Username      string `json:"-"`
+	Reason        string `json:"reason"`
+	BlockIncoming bool   `json:"blockIncoming"`
+}
+
+type UnfreezeRequest struct {
+	Username string `json:"-"`
+	Reason   string `json:"reason"`
+}
+
+type AccountFreeze struct {
+	ID             string     `json:"id"`
+	Username       string     `json:"username"`
+	Reason         string     `json:"reason"`
+	BlockIncoming  bool       `json:"blockIncoming"`
+	FrozenBy       string     `json:"frozenBy"`
+	FrozenAt       time.Time  `json:"frozenAt"`
+	UnfrozenBy     string     `json:"unfrozenBy,omitempty"`
+	UnfrozenAt     *time.Time `json:"unfrozenAt,omitempty"`
+	UnfreezeReason string     `json:"unfreezeReason,omitempty"`
+}
+
+// Виды подозрительных схем переводов
+const (
+	FraudKindCycle    = "cycle"    // монеты ходят по кругу между несколькими пользователями
+	FraudKindFanIn    = "fan_in"   // много новых аккаунтов переводят одному получателю
+	FraudKindVelocity = "velocity" // слишком много переводов за короткое время
+)
+
+const (
+	FraudStatusOpen      = "open"
+	FraudStatusConfirmed = "confirmed"
+	FraudStatusDismissed = "dismissed"
+)
+
+// FraudTransfer - перевод, который разбирает поиск схем.
+// SenderRegisteredAt - nil, если момент регистрации отправителя неизвестен
+type FraudTransfer struct {
+	ID                 string
+	From               string
+	To                 string
+	Amount             int
+	CreatedAt          time.Time
+	SenderRegisteredAt *time.Time
+}
+
+type FraudFinding struct {
+	ID           string     `json:"id"`
+	Fingerprint  string     `json:"-"`
+	Kind         string     `json:"kind"`
+	Username     string     `json:"username"`
+	Participants []string   `json:"participants"`
+	TransferIDs  []string   `json:"transferIds"`
+	Amount       int        `json:"amount"`
+	Details      string     `json:"details"`
+	Status       string     `json:"status"`
+	DetectedAt   time.Time  `json:"detectedAt"`
+	ReviewedBy   string     `json:"reviewedBy,omitempty"`
+	ReviewedAt   *time.Time `json:"reviewedAt,omitempty"`
+	ReviewNote   string     `json:"reviewNote,omitempty"`
+}
+
+type FraudFindingsRequest struct {
+	Status string
+	Kind   string
+	Limit  int
+}
+
+type FraudFindingsResponse struct {
+	Findings []FraudFinding `json:"findings"`
+}
+
+type FraudReviewRequest struct {
+	ID     string `json:"-"`
+	Status string `json:"status"`
+	Note   string `json:"note"`
+}
+
+// Действия в журнале audit_events
+const (
+	AuditAuthRegister    = "auth.register"
+	AuditAuthLogin       = "auth.login"
+	AuditAuthLoginFailed = "auth.login_failed"
+
+	AuditTransferSend           = "transfer.send"
+	AuditTransferBatch          = "transfer.batch"
+	AuditTransferPendingCreate  = "transfer.pending_create"
+	AuditTransferPendingAccept  = "transfer.pending_accept"
+	AuditTransferPendingDecline = "transfer.pending_decline"
+	AuditTransferScheduledRun   = "transfer.scheduled_run"
+	AuditTransferRecognition    = "transfer.recognition"
+	AuditTransferPendingExpire  = "transfer.pending_expire"
+
+	AuditCoinsAllowance = "coins.allowance"
+	AuditCoinsExpire    = "coins.expire"
+
+	AuditPurchaseBuy  = "purchase.buy"
+	AuditPurchaseGift = "purchase.gift"
+
+	AuditAdminReverse          = "admin.transfer_reverse"
+	AuditAdminGrant            = "admin.grant"
+	AuditAdminBulkGrant        = "admin.bulk_grant"
+	AuditAdminDepartmentCreate = "admin.department_create"
+	AuditAdminDepartmentMember = "admin.department_members"
+	AuditAdminDepartmentBudget = "admin.department_budget"
+	AuditAdminOrgChartImport   = "admin.orgchart_import"
+	AuditAdminOffboard         = "admin.offboard"
+	AuditAdminFreeze           = "admin.freeze"
+	AuditAdminUnfreeze         = "admin.unfreeze"
+	AuditAdminFraudReview      = "admin.fraud_review"
+	AuditAdminWebhookCreate    = "admin.webhook_create"
+	AuditAdminWebhookDelete    = "admin.webhook_delete"
+	AuditAdminWebhookRetry     = "admin.webhook_retry"
+)
+
+// AuditActorSystem - автор действий фоновых задач и CLI
+const AuditActorSystem = "system"
+
+type AuditEvent struct {
+	ID        int64           `json:"id"`
+	Actor     string          `json:"actor"`
+	Action    string          `json:"action"`
+	Target    string          `json:"target"`
+	Payload   json.RawMessage `json:"payload"`
+	RequestID string          `json:"requestId,omitempty"`
+	ClientIP  string          `json:"clientIp,omitempty"`
+	CreatedAt time.Time       `json:"createdAt"`
+	PrevHash  string          `json:"prevHash,omitempty"`
+	Hash      string          `json:"hash,omitempty"`
+}
+
+// AuditHead - последняя запись цепочки журнала
+// AuditHead - последняя связанная запись журнала. Seq - её место в цепочке
+type AuditHead struct {
+	ID   int64
+	Seq  int64
+	Hash string
+}
+
+// AuditQuery - фильтры журнала. Action с * на конце ищет по префиксу, например admin.*
+type AuditQuery struct {
+	Actor     string
+	Action    string
+	Target    string
+	RequestID string
+	From      *time.Time
+	To        *time.Time
+	BeforeID  int64
+	Limit     int
+}
+
+// AuditEventsResponse - страница журнала, новые события сверху. nextBeforeId есть, если события не закончились
+type AuditEventsResponse struct {
+	Events       []AuditEvent `json:"events"`
+	NextBeforeID *int64       `json:"nextBeforeId,omitempty"`
+}
+
+// Типы доменных событий outbox
+const (
+	EventCoinsTransferred = "coins.transferred"
+	EventItemPurchased    = "item.purchased"
+)
+
+// OutboxEvent - доменное событие для внешних систем. Key - пользователь, к которому относится
+// событие, по нему брокер может раскладывать события по партициям
+type OutboxEvent struct {
+	ID        int64           `json:"id"`
+	Type      string          `json:"type"`
+	Key       string          `json:"key"`
+	Payload   json.RawMessage `json:"payload"`
+	CreatedAt time.Time       `json:"createdAt"`
+	Attempts  int             `json:"-"`
+}
+
+// CoinsTransferredEvent - содержимое события coins.transferred
+type CoinsTransferredEvent struct {
+	TransferID string `json:"transferId"`
+	FromUser   string `json:"fromUser"`
+	ToUser     string `json:"toUser"`
+	Amount     int    `json:"amount"`
+	Message    string `json:"message,omitempty"`
+}
+
+// ItemPurchasedEvent - содержимое события item.purchased. Для подарка Owner - получатель
+type ItemPurchasedEvent struct {
+	Buyer string `json:"buyer"`
+	Owner string `json:"owner"`
+	Item  string `json:"item"`
+	Price int    `json:"price"`
+}
+
+// Статусы доставки вебхука
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryDead      = "dead"
+)
+
+type WebhookSubscription struct {
+	ID         string   `json:"id"`
+	URL        string   `json:"url"`
+	EventTypes []string `json:"eventTypes"`
+	// Секрет отдаётся только в ответе на создание подписки
+	Secret    string    `json:"secret,omitempty"`
+	CreatedBy string    `json:"createdBy"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// WebhookRequest - новая подписка. Без secret он генерируется
+type WebhookRequest struct {
+	URL        string   `json:"url"`
+	EventTypes []string `json:"eventTypes"`
+	Secret     string   `json:"secret"`
+}
+
+type WebhooksResponse struct {
+	Webhooks []WebhookSubscription `json:"webhooks"`
+}
+
+type WebhookDelivery struct {
+	ID             string     `json:"id"`
+	SubscriptionID string     `json:"subscriptionId"`
+	EventID        int64      `json:"eventId"`
+	EventType      string     `json:"eventType"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  *time.Time `json:"nextAttemptAt,omitempty"`
+	LastStatusCode int        `json:"lastStatusCode,omitempty"`
+	LastError      string     `json:"lastError,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	DeliveredAt    *time.Time `json:"deliveredAt,omitempty"`
+
+	// Заполняются только для отправки
+	URL     string          `json:"-"`
+	Secret  string          `json:"-"`
+	Payload json.RawMessage `json:"-"`
+}
+
+type WebhookDeliveriesRequest struct {
+	SubscriptionID string
+	Status         string
+	Limit          int
+}
+
+type WebhookDeliveriesResponse struct {
+	Deliveries []WebhookDelivery `json:"deliveries"`
+}
+
+// Типы событий потока /api/events
+const (
+	UserEventCoinsReceived  = "coins.received"
+	UserEventPurchaseStatus = "purchase.status"
+	UserEventBalanceUpdated = "balance.updated"
+)
+
+// UserEvent - событие для подключённого пользователя, приходит из канала user_events
+type UserEvent struct {
+	Username string          `json:"username"`
+	Type     string          `json:"type"`
+	Data     json.RawMessage `json:"data"`
+}
+
+// Ленты WebSocket /api/ws: wallet - события своего кошелька (как в /api/events), feed - общая лента благодарностей
+const (
+	SocketTopicWallet = "wallet"
+	SocketTopicFeed   = "feed"
+
+	// Событие ленты feed: перевод с сообщением
+	FeedEventKudos = "kudos"
+)
+
+// Сообщения клиента и сервера в /api/ws
+const (
+	SocketSubscribe    = "subscribe"
+	SocketUnsubscribe  = "unsubscribe"
+	SocketSubscribed   = "subscribed"
+	SocketUnsubscribed = "unsubscribed"
+	SocketEvent        = "event"
+	SocketError        = "error"
+)
+
+// SocketRequest - сообщение клиента: {"type": "subscribe", "topic": "wallet"}
+type SocketRequest struct {
+	Type  string `json:"type"`
+	Topic string `json:"topic"`
+}
+
+// SocketMessage - сообщение сервера. Для событий event - тип события, data - содержимое
+type SocketMessage struct {
+	Type  string          `json:"type"`
+	Topic string          `json:"topic,omitempty"`
+	Event string          `json:"event,omitempty"`
+	Data  json.RawMessage `json:"data,omitempty"`
+	Error string          `json:"error,omitempty"`
+}
+
+// Типы уведомлений во входящих /api/notifications
+const (
+	NotificationTransferReceived = "transfer.received"
+	NotificationTransferPending  = "transfer.pending"
+	NotificationTransferReversed = "transfer.reversed"
+	NotificationGiftReceived     = "gift.received"
+	NotificationOrderStatus      = "order.status"
+	NotificationCoinsGranted     = "coins.granted"
+	NotificationAccountFrozen    = "account.frozen"
+	NotificationAccountUnfrozen  = "account.unfrozen"
+)
+
+type Notification struct {
+	ID        int64           `json:"id"`
+	Type      string          `json:"type"`
+	Data      json.RawMessage `json:"data"`
+	CreatedAt time.Time       `json:"createdAt"`
+	ReadAt    *time.Time      `json:"readAt,omitempty"`
+}
+
+type NotificationsRequest struct {
+	UnreadOnly bool
+	BeforeID   int64
+	Limit      int
+}
+
+// NotificationsResponse - страница уведомлений, новые сверху. nextBeforeId есть, если уведомления не закончились
+type NotificationsResponse struct {
+	Notifications []Notification `json:"notifications"`
+	UnreadCount   int            `json:"unreadCount"`
+	NextBeforeID  *int64         `json:"nextBeforeId,omitempty"`
+}
+
+// ReadNotificationsRequest - отметить прочитанными все уведомления, upToId ограничивает их
+// уже показанными, чтобы не пропустить пришедшие после загрузки страницы
+type ReadNotificationsRequest struct {
+	UpToID int64 `json:"upToId"`
+}
+
+type ReadNotificationsResponse struct {
+	Updated int `json:"updated"`
+}
+
+// EmailMessage - письмо для Mailer, отправитель задаётся настройками почты
+type EmailMessage struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// DigestActivity - активность пользователя за неделю [WeekStart, WeekEnd) для письма-сводки.
+// Учитываются переводы без сторно и покупки, оплаченные пользователем
+type DigestActivity struct {
+	Username    string
+	DisplayName string
+	WeekStart   time.Time
+	WeekEnd     time.Time
+
+	Received      int
+	ReceivedCount int
+	Sent          int
+	SentCount     int
+	Spent         int
+	PurchaseCount int
+	Balance       int
+
+	TopSenders []DigestCounterparty
+	Items      []DigestItem
+}
+
+// Empty - за неделю ничего не произошло, письмо не отправляется
+func (a DigestActivity) Empty() bool {
+	return a.ReceivedCount == 0 && a.SentCount == 0 && a.PurchaseCount == 0
+}
+
+type DigestCounterparty struct {
+	Username string
+	Amount   int
+}
+
+type DigestItem struct {
+	Item  string
+	Count int
+}
+
+// DigestSettings - подписка на еженедельное письмо. Email - куда оно уходит, пустой, если почта не настроена
+type DigestSettings struct {
+	Enabled bool   `json:"enabled"`
+	Email   string `json:"email,omitempty"`
 }
@@ -0,0 +1,18 @@
+package interfaces
+
+import (
+	"context"
+
+	"ttavito/domain/entities"
+)
+
+// EventPublisher отправляет доменные события из outbox. Событие может прийти повторно,
+// получатели отличают дубликаты по ID
+type EventPublisher interface {
+	Publish(ctx context.Context, e entities.OutboxEvent) error
+}
+
+// WebhookSender отправляет доставку подписчику и возвращает код ответа, 0 - ответа не было
+type WebhookSender interface {
+	Send(ctx context.Context, d entities.WebhookDelivery) (int, error)
+}
@@ -0,0 +1,12 @@
+package interfaces
+
+import (
+	"context"
+
+	"ttavito/domain/entities"
+)
+
+// Mailer отправляет письма
+type Mailer interface {
+	Send(ctx context.Context, msg entities.EmailMessage) error
+}
@@ -2,12 +2,82 @@ package interfaces
 
 import (
 	"context"
+	"time"
 	"ttavito/domain/entities"
 )
 
 type ShopRepository interface {
 	GetInfo(ctx context.Context, username string) (*entities.InfoResponse, error)
 	BuyItem(ctx context.Context, username, item string) error
-	SendCoin(ctx context.Context, senderUsername string, recipientUsername string, amount int) error
+	GiftItem(ctx context.Context, buyer string, req entities.GiftRequest) error
+	SendCoin(ctx context.Context, senderUsername string, recipientUsername string, amount int, message string) error
+	BatchSendCoin(ctx context.Context, senderUsername string, req entities.BatchSendCoinRequest) (*entities.BatchSendCoinResponse, error)
+	CreatePendingTransfer(ctx context.Context, senderUsername string, req entities.SendCoinRequest, expiresAt time.Time) (*entities.PendingTransfer, error)
+	GetPendingTransfers(ctx context.Context, username string) (*entities.PendingTransfersResponse, error)
+	AcceptPendingTransfer(ctx context.Context, username, id string) (*entities.PendingTransfer, error)
+	DeclinePendingTransfer(ctx context.Context, username, id string) (*entities.PendingTransfer, error)
+	ExpirePendingTransfers(ctx context.Context, now time.Time) (int, error)
+	CreateScheduledTransfer(ctx context.Context, senderUsername string, req entities.ScheduledTransferRequest, nextRunAt time.Time) (*entities.ScheduledTransfer, error)
+	GetScheduledTransfers(ctx context.Context, username string) ([]entities.ScheduledTransfer, error)
+	GetScheduledTransfer(ctx context.Context, username, id string) (*entities.ScheduledTransfer, error)
+	UpdateScheduledTransferStatus(ctx context.Context, id, fromStatus, toStatus string, nextRunAt *time.Time) error
+	GetDueScheduledTransfers(ctx context.Context, now time.Time, limit int) ([]entities.ScheduledTransfer, error)
+	RunScheduledTransfer(ctx context.Context, s entities.ScheduledTransfer, now time.Time, nextRunAt *time.Time) (*entities.ScheduledTransferRun, error)
+	GrantCoins(ctx context.Context, admin string, req entities.GrantRequest) (*entities.Grant, error)
+	BulkGrantCoins(ctx context.Context, admin string, req entities.BulkGrantRequest) (*entities.BulkGrantResponse, error)
+	CreateDepartment(ctx context.Context, admin string, req entities.DepartmentRequest) (*entities.Department, error)
+	AddDepartmentMembers(ctx context.Context, admin string, req entities.DepartmentMembersRequest) (*entities.Department, error)
+	AllocateDepartmentBudget(ctx context.Context, admin string, req entities.BudgetAllocationRequest) (*entities.Department, error)
+	RecognizeFromBudget(ctx context.Context, manager string, req entities.RecognitionRequest) (*entities.Recognition, error)
+	GetDepartmentBudgetReport(ctx context.Context, req entities.DepartmentReportRequest) (*entities.DepartmentBudgetReport, error)
+	ImportOrgChart(ctx context.Context, entries []entities.OrgChartEntry, dryRun bool) (*entities.OrgChartImportResult, error)
+	GetTeam(ctx context.Context, name string) (*entities.Team, error)
+	GetProfile(ctx context.Context, username string) (*entities.Profile, error)
+	UpdateProfile(ctx context.Context, username string, req entities.UpdateProfileRequest) error
+	SetAvatar(ctx context.Context, username, key, contentType string) error
+	SearchUsers(ctx context.Context, requester string, req entities.UserSearchRequest) (*entities.UserSearchResponse, error)
+	GetAvatarKey(ctx context.Context, username string) (key, contentType string, err error)
+	OffboardUser(ctx context.Context, admin string, req entities.OffboardRequest) (*entities.Offboarding, error)
+	SessionsRevokedAt(ctx context.Context, username string) (time.Time, error)
+	FreezeAccount(ctx context.Context, admin string, req entities.FreezeRequest) (*entities.AccountFreeze, error)
+	UnfreezeAccount(ctx context.Context, admin string, req entities.UnfreezeRequest) (*entities.AccountFreeze, error)
+	GetFraudTransfers(ctx context.Context, window time.Duration) ([]entities.FraudTransfer, error)
+	SaveFraudFindings(ctx context.Context, findings []entities.FraudFinding) (int, error)
+	GetFraudFindings(ctx context.Context, req entities.FraudFindingsRequest) (*entities.FraudFindingsResponse, error)
+	ReviewFraudFinding(ctx context.Context, admin string, req entities.FraudReviewRequest) (*entities.FraudFinding, error)
+	GetAuditEvents(ctx context.Context, req entities.AuditQuery) (*entities.AuditEventsResponse, error)
+	GetAuditHead(ctx context.Context) (*entities.AuditHead, error)
+	ChainAuditEvents(ctx context.Context, limit int) (int, error)
+	ScanAuditEvents(ctx context.Context, fn func(entities.AuditEvent) error) error
+
+	ClaimOutboxEvents(ctx context.Context, limit int, lease time.Duration) ([]entities.OutboxEvent, error)
+	MarkOutboxPublished(ctx context.Context, ids []int64) error
+	RetryOutboxEvent(ctx context.Context, id int64, lastError string, nextAttemptAt time.Time) error
+	DeleteOutboxPublished(ctx context.Context, before time.Time) (int, error)
+
+	CreateWebhook(ctx context.Context, admin string, req entities.WebhookRequest) (*entities.WebhookSubscription, error)
+	GetWebhooks(ctx context.Context) ([]entities.WebhookSubscription, error)
+	DeleteWebhook(ctx context.Context, admin, id string) error
+	EnqueueWebhookDeliveries(ctx context.Context, e entities.OutboxEvent) (int, error)
+	ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]entities.WebhookDelivery, error)
+	CompleteWebhookDelivery(ctx context.Context, id string, statusCode int) error
+	FailWebhookDelivery(ctx context.Context, id string, statusCode int, lastError string, nextAttemptAt *time.Time) error
+	GetWebhookDeliveries(ctx context.Context, req entities.WebhookDeliveriesRequest) (*entities.WebhookDeliveriesResponse, error)
+	RetryWebhookDelivery(ctx context.Context, admin, id string) (*entities.WebhookDelivery, error)
+
+	GetNotifications(ctx context.Context, username string, req entities.NotificationsRequest) (*entities.NotificationsResponse, error)
+	MarkNotificationRead(ctx context.Context, username string, id int64) (*entities.Notification, error)
+	MarkNotificationsRead(ctx context.Context, username string, upToID int64) (int, error)
+
+	GetDigestEnabled(ctx context.Context, username string) (bool, error)
+	SetDigestEnabled(ctx context.Context, username string, enabled bool) error
+	GetDigestRecipients(ctx context.Context, weekStart time.Time, limit int) ([]string, error)
+	ClaimDigest(ctx context.Context, username string, weekStart time.Time) (bool, error)
+	ReleaseDigest(ctx context.Context, username string, weekStart time.Time) error
+	GetDigestActivity(ctx context.Context, username string, from, to time.Time) (*entities.DigestActivity, error)
+
+	ExpireCoinLots(ctx context.Context, now time.Time) (int, error)
+	IssueAllowance(ctx context.Context, period string, amount int) (int, error)
 	Auth(ctx context.Context, username, password string) (bool, error)
+	ReverseTransfer(ctx context.Context, admin string, req entities.ReverseTransferRequest) (*entities.ReversalResponse, error)
 }
@@ -0,0 +1,15 @@
+package interfaces
+
+import (
+	"context"
+	"errors"
+)
+
+var ErrObjectNotFound = errors.New("object not found")
+
+// FileStore хранит бинарные объекты (аватары) по ключу
+type FileStore interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	// Get возвращает ErrObjectNotFound, если объекта нет
+	Get(ctx context.Context, key string) ([]byte, error)
+}
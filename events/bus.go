@@ -0,0 +1,47 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"ttavito/domain/entities"
+)
+
+type Handler func(ctx context.Context, e entities.OutboxEvent) error
+
+// Bus раздаёт события подписчикам внутри процесса. Если хотя бы один подписчик вернул ошибку,
+// событие будет опубликовано повторно, и остальные получат его ещё раз
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+}
+
+func NewBus() *Bus {
+	return &Bus{handlers: make(map[string][]Handler)}
+}
+
+// Subscribe подписывает h на события eventType, пустой eventType - на все события
+func (b *Bus) Subscribe(eventType string, h Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[eventType] = append(b.handlers[eventType], h)
+}
+
+func (b *Bus) Publish(ctx context.Context, e entities.OutboxEvent) error {
+	b.mu.RLock()
+	handlers := append(append([]Handler(nil), b.handlers[e.Type]...), b.handlers[""]...)
+	b.mu.RUnlock()
+
+	var errs []error
+	for _, h := range handlers {
+		if err := h(ctx, e); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("event %d: %w", e.ID, errors.Join(errs...))
+	}
+	return nil
+}
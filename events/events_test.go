@@ -0,0 +1,236 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"ttavito/domain/entities"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func event(id int64, eventType string) entities.OutboxEvent {
+	return entities.OutboxEvent{
+		ID:        id,
+		Type:      eventType,
+		Key:       "bob",
+		Payload:   json.RawMessage(`{"amount":10}`),
+		CreatedAt: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestBus(t *testing.T) {
+	bus := NewBus()
+	var got []string
+	bus.Subscribe(entities.EventCoinsTransferred, func(ctx context.Context, e entities.OutboxEvent) error {
+		got = append(got, "transfer:"+e.Key)
+		return nil
+	})
+	bus.Subscribe("", func(ctx context.Context, e entities.OutboxEvent) error {
+		got = append(got, "all:"+e.Type)
+		return nil
+	})
+
+	assert.NoError(t, bus.Publish(context.Background(), event(1, entities.EventCoinsTransferred)))
+	assert.NoError(t, bus.Publish(context.Background(), event(2, entities.EventItemPurchased)))
+	assert.Equal(t, []string{"transfer:bob", "all:coins.transferred", "all:item.purchased"}, got)
+
+	boom := errors.New("boom")
+	bus.Subscribe(entities.EventItemPurchased, func(ctx context.Context, e entities.OutboxEvent) error {
+		return boom
+	})
+	assert.ErrorIs(t, bus.Publish(context.Background(), event(3, entities.EventItemPurchased)), boom)
+}
+
+type natsMessage struct {
+	subject string
+	header  string
+	data    string
+}
+
+// natsStandIn - минимальный сервер NATS: отвечает на PING и запоминает PUB/HPUB.
+// Сообщения в тему reject отклоняются через -ERR
+type natsStandIn struct {
+	ln      net.Listener
+	headers bool
+	reject  string
+
+	mu       sync.Mutex
+	messages []natsMessage
+	conns    []net.Conn
+	connects []map[string]any
+}
+
+func newNATSStandIn(t *testing.T, headers bool) *natsStandIn {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &natsStandIn{ln: ln, headers: headers}
+	go s.serve()
+	t.Cleanup(func() {
+		ln.Close()
+		s.dropConnections()
+	})
+	return s
+}
+
+func (s *natsStandIn) url() string {
+	return "nats://u:p@" + s.ln.Addr().String()
+}
+
+func (s *natsStandIn) serve() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.conns = append(s.conns, conn)
+		s.mu.Unlock()
+		go s.handle(conn)
+	}
+}
+
+func (s *natsStandIn) handle(conn net.Conn) {
+	defer conn.Close()
+	fmt.Fprintf(conn, "INFO {\"server_id\":\"stand-in\",\"max_payload\":1048576,\"headers\":%t}\r\n", s.headers)
+
+	r := bufio.NewReader(conn)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch fields[0] {
+		case "CONNECT":
+			var opts map[string]any
+			json.Unmarshal([]byte(strings.TrimPrefix(strings.TrimSpace(line), "CONNECT ")), &opts)
+			s.mu.Lock()
+			s.connects = append(s.connects, opts)
+			s.mu.Unlock()
+		case "PING":
+			fmt.Fprint(conn, "PONG\r\n")
+		case "PUB", "HPUB":
+			msg := natsMessage{subject: fields[1]}
+			total, _ := strconv.Atoi(fields[len(fields)-1])
+			body := make([]byte, total+2)
+			if _, err := io.ReadFull(r, body); err != nil {
+				return
+			}
+			body = body[:total]
+			if fields[0] == "HPUB" {
+				hdrLen, _ := strconv.Atoi(fields[2])
+				msg.header = string(body[:hdrLen])
+				body = body[hdrLen:]
+			}
+			msg.data = string(body)
+
+			if msg.subject == s.reject {
+				fmt.Fprint(conn, "-ERR 'Permissions Violation for Publish'\r\n")
+				continue
+			}
+			s.mu.Lock()
+			s.messages = append(s.messages, msg)
+			s.mu.Unlock()
+		}
+	}
+}
+
+func (s *natsStandIn) received() []natsMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]natsMessage(nil), s.messages...)
+}
+
+func (s *natsStandIn) dropConnections() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, c := range s.conns {
+		c.Close()
+	}
+	s.conns = nil
+}
+
+func TestNATSPublisher(t *testing.T) {
+	server := newNATSStandIn(t, true)
+	p, err := NewNATSPublisher(server.url(), "ttavito")
+	assert.NoError(t, err)
+	defer p.Close()
+
+	ctx := context.Background()
+	assert.NoError(t, p.Publish(ctx, event(7, entities.EventCoinsTransferred)))
+
+	got := server.received()
+	if assert.Len(t, got, 1) {
+		assert.Equal(t, "ttavito.coins.transferred", got[0].subject)
+		assert.Contains(t, got[0].header, "Nats-Msg-Id: 7\r\n")
+
+		var e entities.OutboxEvent
+		assert.NoError(t, json.Unmarshal([]byte(got[0].data), &e))
+		assert.Equal(t, int64(7), e.ID)
+		assert.Equal(t, "bob", e.Key)
+		assert.JSONEq(t, `{"amount":10}`, string(e.Payload))
+	}
+	assert.Equal(t, "u", server.connects[0]["user"])
+
+	// После обрыва соединения клиент переподключается сам, а публикация повторяется до успеха
+	server.dropConnections()
+	assert.Eventually(t, func() bool {
+		return p.Publish(ctx, event(8, entities.EventItemPurchased)) == nil
+	}, 5*time.Second, 100*time.Millisecond)
+	got = server.received()
+	assert.Equal(t, "ttavito.item.purchased", got[len(got)-1].subject)
+}
+
+func TestNATSPublisherWithoutHeaders(t *testing.T) {
+	server := newNATSStandIn(t, false)
+	p, err := NewNATSPublisher(server.url(), "")
+	assert.NoError(t, err)
+	defer p.Close()
+
+	assert.NoError(t, p.Publish(context.Background(), event(1, entities.EventItemPurchased)))
+	got := server.received()
+	if assert.Len(t, got, 1) {
+		assert.Equal(t, "item.purchased", got[0].subject)
+		assert.Empty(t, got[0].header)
+	}
+}
+
+func TestNATSPublisherRejected(t *testing.T) {
+	server := newNATSStandIn(t, true)
+	server.reject = "ttavito.item.purchased"
+	p, err := NewNATSPublisher(server.url(), "ttavito")
+	assert.NoError(t, err)
+	defer p.Close()
+
+	err = p.Publish(context.Background(), event(1, entities.EventItemPurchased))
+	assert.ErrorContains(t, err, "Permissions Violation")
+	assert.Empty(t, server.received())
+}
+
+func TestNewNATSPublisherInvalidURL(t *testing.T) {
+	_, err := NewNATSPublisher("http://localhost:4222", "")
+	assert.Error(t, err)
+
+	// Недоступный сервер не мешает запуску, клиент подключится позже
+	p, err := NewNATSPublisher("nats://127.0.0.1:1", "")
+	if assert.NoError(t, err) {
+		p.Close()
+	}
+}
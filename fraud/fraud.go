@@ -0,0 +1,317 @@
+package fraud
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"ttavito/domain/entities"
+)
+
+// Сколько циклов ищется за один запуск: на плотном графе их число растёт экспоненциально
+const maxCycles = 1000
+
+// Столько символов помещается в fraud_findings.details
+const maxDetailsLength = 500
+
+// Rules - пороги поиска схем. Нулевое значение отключает правило.
+type Rules struct {
+	CycleMaxLength       int           // сколько участников может быть в цикле, циклы ищутся от 3 участников
+	CycleMinAmount       int           // сколько монет должно пройти по каждому звену цикла
+	FreshAccountAge      time.Duration // аккаунт, переводящий монеты раньше этого срока после регистрации, считается новым
+	FanInMinSenders      int           // сколько новых аккаунтов должны перевести одному получателю
+	VelocityWindow       time.Duration
+	VelocityMaxTransfers int // больше переводов одного отправителя за VelocityWindow - подозрительно
+}
+
+func (r Rules) Enabled() bool {
+	return r.cyclesEnabled() || r.fanInEnabled() || r.velocityEnabled()
+}
+
+func (r Rules) cyclesEnabled() bool {
+	return r.CycleMaxLength >= 3
+}
+
+func (r Rules) fanInEnabled() bool {
+	return r.FreshAccountAge > 0 && r.FanInMinSenders > 0
+}
+
+func (r Rules) velocityEnabled() bool {
+	return r.VelocityWindow > 0 && r.VelocityMaxTransfers > 0
+}
+
+// Detect ищет в переводах за окно window циклы, приток монет от новых аккаунтов и всплески переводов.
+// Fingerprint находки строится по периоду длиной window, в который попал её первый перевод:
+// при повторных запусках на сдвинутом окне он не меняется, а та же схема через несколько
+// периодов получает новый fingerprint
+func Detect(transfers []entities.FraudTransfer, rules Rules, window time.Duration) []entities.FraudFinding {
+	var findings []entities.FraudFinding
+	if rules.cyclesEnabled() {
+		findings = append(findings, findCycles(transfers, rules, window)...)
+	}
+	if rules.fanInEnabled() {
+		findings = append(findings, findFanIn(transfers, rules, window)...)
+	}
+	if rules.velocityEnabled() {
+		findings = append(findings, findVelocity(transfers, rules, window)...)
+	}
+	return findings
+}
+
+// fingerprint - вид находки и SHA-256 от периода, в который попал at, и частей parts.
+// Длина не зависит от числа участников и всегда помещается в колонку
+func fingerprint(kind string, at time.Time, window time.Duration, parts ...string) string {
+	var period int64
+	if window > 0 {
+		period = at.UnixNano() / int64(window)
+	}
+	h := sha256.New()
+	fmt.Fprintf(h, "%d", period)
+	for _, part := range parts {
+		h.Write([]byte{0})
+		h.Write([]byte(part))
+	}
+	return kind + ":" + hex.EncodeToString(h.Sum(nil))
+}
+
+// truncateDetails обрезает описание находки до размера колонки
+func truncateDetails(details string) string {
+	runes := []rune(details)
+	if len(runes) <= maxDetailsLength {
+		return details
+	}
+	return string(runes[:maxDetailsLength-3]) + "..."
+}
+
+type edge struct {
+	amount int
+	ids    []string
+	first  time.Time
+}
+
+// findCycles ищет простые циклы, каждый ровно один раз: обход начинается с
+// лексикографически наименьшего участника и заходит только в участников больше него
+func findCycles(transfers []entities.FraudTransfer, rules Rules, window time.Duration) []entities.FraudFinding {
+	graph := make(map[string]map[string]*edge)
+	for _, t := range transfers {
+		if graph[t.From] == nil {
+			graph[t.From] = make(map[string]*edge)
+		}
+		e := graph[t.From][t.To]
+		if e == nil {
+			e = &edge{first: t.CreatedAt}
+			graph[t.From][t.To] = e
+		}
+		e.amount += t.Amount
+		e.ids = append(e.ids, t.ID)
+		if t.CreatedAt.Before(e.first) {
+			e.first = t.CreatedAt
+		}
+	}
+
+	// Звенья, по которым прошло меньше порога, в циклы не входят
+	next := make(map[string][]string)
+	for from, edges := range graph {
+		for to, e := range edges {
+			if e.amount >= rules.CycleMinAmount {
+				next[from] = append(next[from], to)
+			}
+		}
+		slices.Sort(next[from])
+	}
+
+	starts := make([]string, 0, len(next))
+	for from := range next {
+		starts = append(starts, from)
+	}
+	slices.Sort(starts)
+
+	var findings []entities.FraudFinding
+	var path []string
+	onPath := make(map[string]bool)
+
+	var visit func(start, node string)
+	visit = func(start, node string) {
+		if len(findings) >= maxCycles {
+			return
+		}
+		path = append(path, node)
+		onPath[node] = true
+
+		for _, to := range next[node] {
+			switch {
+			case to == start && len(path) >= 3:
+				findings = append(findings, cycleFinding(graph, path, window))
+			case to > start && !onPath[to] && len(path) < rules.CycleMaxLength:
+				visit(start, to)
+			}
+		}
+
+		onPath[node] = false
+		path = path[:len(path)-1]
+	}
+
+	for _, start := range starts {
+		visit(start, start)
+	}
+	return findings
+}
+
+func cycleFinding(graph map[string]map[string]*edge, path []string, window time.Duration) entities.FraudFinding {
+	participants := slices.Clone(path)
+
+	var ids []string
+	var first time.Time
+	amount := -1
+	for i, from := range participants {
+		e := graph[from][participants[(i+1)%len(participants)]]
+		ids = append(ids, e.ids...)
+		if amount < 0 || e.amount < amount {
+			amount = e.amount
+		}
+		if first.IsZero() || e.first.Before(first) {
+			first = e.first
+		}
+	}
+
+	return entities.FraudFinding{
+		Fingerprint:  fingerprint(entities.FraudKindCycle, first, window, participants...),
+		Kind:         entities.FraudKindCycle,
+		Username:     participants[0],
+		Participants: participants,
+		TransferIDs:  ids,
+		Amount:       amount,
+		Details: truncateDetails(fmt.Sprintf("at least %d coins went around %s -> %s",
+			amount, strings.Join(participants, " -> "), participants[0])),
+	}
+}
+
+// findFanIn ищет получателей, которым переводили монеты много аккаунтов вскоре после регистрации
+func findFanIn(transfers []entities.FraudTransfer, rules Rules, window time.Duration) []entities.FraudFinding {
+	type fanIn struct {
+		senders map[string]bool
+		ids     []string
+		amount  int
+		first   time.Time
+	}
+
+	byRecipient := make(map[string]*fanIn)
+	for _, t := range transfers {
+		if t.SenderRegisteredAt == nil || t.CreatedAt.Sub(*t.SenderRegisteredAt) >= rules.FreshAccountAge {
+			continue
+		}
+
+		f := byRecipient[t.To]
+		if f == nil {
+			f = &fanIn{senders: make(map[string]bool), first: t.CreatedAt}
+			byRecipient[t.To] = f
+		}
+		f.senders[t.From] = true
+		f.ids = append(f.ids, t.ID)
+		f.amount += t.Amount
+		if t.CreatedAt.Before(f.first) {
+			f.first = t.CreatedAt
+		}
+	}
+
+	var findings []entities.FraudFinding
+	for recipient, f := range byRecipient {
+		if len(f.senders) < rules.FanInMinSenders {
+			continue
+		}
+
+		senders := make([]string, 0, len(f.senders))
+		for sender := range f.senders {
+			senders = append(senders, sender)
+		}
+		slices.Sort(senders)
+
+		findings = append(findings, entities.FraudFinding{
+			// Новые отправители дополняют ту же находку, пока начало притока не ушло в другой период
+			Fingerprint:  fingerprint(entities.FraudKindFanIn, f.first, window, recipient),
+			Kind:         entities.FraudKindFanIn,
+			Username:     recipient,
+			Participants: append([]string{recipient}, senders...),
+			TransferIDs:  f.ids,
+			Amount:       f.amount,
+			Details: fmt.Sprintf("%d accounts sent %d coins within %s of registration",
+				len(senders), f.amount, rules.FreshAccountAge),
+		})
+	}
+
+	slices.SortFunc(findings, func(a, b entities.FraudFinding) int {
+		return strings.Compare(a.Username, b.Username)
+	})
+	return findings
+}
+
+// findVelocity ищет у отправителей непересекающиеся серии, где переводов
+// за VelocityWindow больше VelocityMaxTransfers
+func findVelocity(transfers []entities.FraudTransfer, rules Rules, window time.Duration) []entities.FraudFinding {
+	bySender := make(map[string][]entities.FraudTransfer)
+	for _, t := range transfers {
+		bySender[t.From] = append(bySender[t.From], t)
+	}
+
+	senders := make([]string, 0, len(bySender))
+	for sender := range bySender {
+		senders = append(senders, sender)
+	}
+	slices.Sort(senders)
+
+	var findings []entities.FraudFinding
+	for _, sender := range senders {
+		sent := bySender[sender]
+		if len(sent) <= rules.VelocityMaxTransfers {
+			continue
+		}
+		slices.SortStableFunc(sent, func(a, b entities.FraudTransfer) int {
+			return a.CreatedAt.Compare(b.CreatedAt)
+		})
+
+		for start := 0; start < len(sent); {
+			end := start
+			for end+1 < len(sent) && sent[end+1].CreatedAt.Sub(sent[start].CreatedAt) < rules.VelocityWindow {
+				end++
+			}
+			if end-start+1 <= rules.VelocityMaxTransfers {
+				start++
+				continue
+			}
+
+			findings = append(findings, velocityFinding(sender, sent[start:end+1], rules, window))
+			start = end + 1
+		}
+	}
+	return findings
+}
+
+func velocityFinding(sender string, burst []entities.FraudTransfer, rules Rules, window time.Duration) entities.FraudFinding {
+	recipients := make(map[string]bool)
+	participants := []string{sender}
+	ids := make([]string, 0, len(burst))
+	amount := 0
+	for _, t := range burst {
+		if !recipients[t.To] {
+			recipients[t.To] = true
+			participants = append(participants, t.To)
+		}
+		ids = append(ids, t.ID)
+		amount += t.Amount
+	}
+	slices.Sort(participants[1:])
+
+	return entities.FraudFinding{
+		Fingerprint:  fingerprint(entities.FraudKindVelocity, burst[0].CreatedAt, window, sender, burst[0].ID),
+		Kind:         entities.FraudKindVelocity,
+		Username:     sender,
+		Participants: participants,
+		TransferIDs:  ids,
+		Amount:       amount,
+		Details: fmt.Sprintf("%d transfers totalling %d coins to %d recipients within %s",
+			len(burst), amount, len(recipients), rules.VelocityWindow),
+	}
+}
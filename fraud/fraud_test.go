@@ -0,0 +1,163 @@
+package fraud
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"ttavito/domain/entities"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var base = time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+const window = 7 * 24 * time.Hour
+
+func transfer(id, from, to string, amount int, at time.Duration) entities.FraudTransfer {
+	return entities.FraudTransfer{ID: id, From: from, To: to, Amount: amount, CreatedAt: base.Add(at)}
+}
+
+func TestRulesEnabled(t *testing.T) {
+	assert.False(t, Rules{}.Enabled())
+	assert.False(t, Rules{CycleMaxLength: 2}.Enabled())
+	assert.False(t, Rules{FanInMinSenders: 5}.Enabled())
+	assert.True(t, Rules{CycleMaxLength: 3}.Enabled())
+	assert.True(t, Rules{VelocityWindow: time.Hour, VelocityMaxTransfers: 10}.Enabled())
+}
+
+func TestDetectCycles(t *testing.T) {
+	transfers := []entities.FraudTransfer{
+		transfer("1", "bob", "carol", 300, 0),
+		transfer("2", "carol", "alice", 200, time.Minute),
+		transfer("3", "carol", "alice", 150, 2*time.Minute),
+		transfer("4", "alice", "bob", 300, 3*time.Minute),
+		// Взаимные благодарности - не цикл
+		transfer("5", "dave", "erin", 100, 0),
+		transfer("6", "erin", "dave", 100, time.Minute),
+		// Мелкое звено не даёт циклу попасть в находки
+		transfer("7", "frank", "grace", 300, 0),
+		transfer("8", "grace", "heidi", 10, 0),
+		transfer("9", "heidi", "frank", 300, 0),
+	}
+
+	findings := Detect(transfers, Rules{CycleMaxLength: 4, CycleMinAmount: 100}, window)
+
+	assert.Len(t, findings, 1)
+	f := findings[0]
+	assert.Equal(t, entities.FraudKindCycle, f.Kind)
+	assert.Equal(t, fingerprint(entities.FraudKindCycle, base, window, "alice", "bob", "carol"), f.Fingerprint)
+	assert.Equal(t, "alice", f.Username)
+	assert.Equal(t, []string{"alice", "bob", "carol"}, f.Participants)
+	assert.ElementsMatch(t, []string{"1", "2", "3", "4"}, f.TransferIDs)
+	assert.Equal(t, 300, f.Amount)
+}
+
+func TestDetectCyclesMaxLength(t *testing.T) {
+	transfers := []entities.FraudTransfer{
+		transfer("1", "a", "b", 10, 0),
+		transfer("2", "b", "c", 10, 0),
+		transfer("3", "c", "d", 10, 0),
+		transfer("4", "d", "a", 10, 0),
+	}
+
+	assert.Empty(t, Detect(transfers, Rules{CycleMaxLength: 3}, window))
+	assert.Len(t, Detect(transfers, Rules{CycleMaxLength: 4}, window), 1)
+}
+
+func TestDetectFanIn(t *testing.T) {
+	var transfers []entities.FraudTransfer
+	for i := range 5 {
+		tr := transfer(fmt.Sprint(i), fmt.Sprintf("puppet%d", i), "boss", 100, time.Duration(i)*time.Hour)
+		registered := tr.CreatedAt.Add(-time.Hour)
+		tr.SenderRegisteredAt = &registered
+		transfers = append(transfers, tr)
+	}
+	// Давно зарегистрированный и неизвестный отправители не считаются
+	old := base.Add(-30 * 24 * time.Hour)
+	transfers = append(transfers,
+		entities.FraudTransfer{ID: "old", From: "veteran", To: "carol", Amount: 100, CreatedAt: base, SenderRegisteredAt: &old},
+		transfer("unknown", "legacy", "carol", 100, 0),
+	)
+
+	rules := Rules{FreshAccountAge: 72 * time.Hour, FanInMinSenders: 5}
+	findings := Detect(transfers, rules, window)
+
+	assert.Len(t, findings, 1)
+	f := findings[0]
+	assert.Equal(t, entities.FraudKindFanIn, f.Kind)
+	assert.Equal(t, "boss", f.Username)
+	assert.Equal(t, []string{"boss", "puppet0", "puppet1", "puppet2", "puppet3", "puppet4"}, f.Participants)
+	assert.Equal(t, 500, f.Amount)
+	assert.Equal(t, fingerprint(entities.FraudKindFanIn, base, window, "boss"), f.Fingerprint)
+
+	rules.FanInMinSenders = 6
+	assert.Empty(t, Detect(transfers, rules, window))
+}
+
+func TestDetectVelocity(t *testing.T) {
+	var transfers []entities.FraudTransfer
+	for i := range 4 {
+		transfers = append(transfers, transfer(fmt.Sprintf("a%d", i), "alice", "bob", 10, time.Duration(i)*time.Minute))
+	}
+	// Через сутки вторая серия
+	for i := range 4 {
+		transfers = append(transfers, transfer(fmt.Sprintf("b%d", i), "alice", fmt.Sprintf("user%d", i), 10, 24*time.Hour+time.Duration(i)*time.Minute))
+	}
+	// Редкие переводы не в счёт
+	for i := range 4 {
+		transfers = append(transfers, transfer(fmt.Sprintf("c%d", i), "carol", "bob", 10, time.Duration(i)*time.Hour))
+	}
+
+	findings := Detect(transfers, Rules{VelocityWindow: time.Hour, VelocityMaxTransfers: 3}, window)
+
+	assert.Len(t, findings, 2)
+	assert.Equal(t, fingerprint(entities.FraudKindVelocity, base, window, "alice", "a0"), findings[0].Fingerprint)
+	assert.Equal(t, []string{"alice", "bob"}, findings[0].Participants)
+	assert.Equal(t, []string{"a0", "a1", "a2", "a3"}, findings[0].TransferIDs)
+	assert.Equal(t, 40, findings[0].Amount)
+	assert.Equal(t, fingerprint(entities.FraudKindVelocity, base.Add(24*time.Hour), window, "alice", "b0"), findings[1].Fingerprint)
+	assert.NotEqual(t, findings[0].Fingerprint, findings[1].Fingerprint)
+	assert.Equal(t, []string{"alice", "user0", "user1", "user2", "user3"}, findings[1].Participants)
+}
+
+func TestDetectFingerprintStableInPeriod(t *testing.T) {
+	cycle := func(at time.Duration, ids ...string) []entities.FraudTransfer {
+		return []entities.FraudTransfer{
+			transfer(ids[0], "alice", "bob", 100, at),
+			transfer(ids[1], "bob", "carol", 100, at+time.Minute),
+			transfer(ids[2], "carol", "alice", 100, at+2*time.Minute),
+		}
+	}
+	rules := Rules{CycleMaxLength: 3}
+
+	// Окно сдвинулось, а часть переводов из него выпала: та же схема, тот же fingerprint
+	first := Detect(cycle(0, "1", "2", "3"), rules, window)
+	shifted := Detect(append(cycle(0, "1", "2", "3")[1:], transfer("4", "alice", "bob", 100, time.Hour)), rules, window)
+	assert.Len(t, first, 1)
+	assert.Len(t, shifted, 1)
+	assert.Equal(t, first[0].Fingerprint, shifted[0].Fingerprint)
+
+	// Та же схема через несколько месяцев - новая находка
+	later := Detect(cycle(90*24*time.Hour, "5", "6", "7"), rules, window)
+	assert.Len(t, later, 1)
+	assert.NotEqual(t, first[0].Fingerprint, later[0].Fingerprint)
+}
+
+func TestDetectFingerprintLength(t *testing.T) {
+	var transfers []entities.FraudTransfer
+	var participants []string
+	for i := range 8 {
+		participants = append(participants, fmt.Sprintf("%c%s", 'a'+i, strings.Repeat("x", 99)))
+	}
+	for i, from := range participants {
+		transfers = append(transfers, transfer(fmt.Sprint(i), from, participants[(i+1)%len(participants)], 100, 0))
+	}
+
+	findings := Detect(transfers, Rules{CycleMaxLength: len(participants)}, window)
+
+	assert.Len(t, findings, 1)
+	assert.LessOrEqual(t, len(findings[0].Fingerprint), 500)
+	assert.LessOrEqual(t, len([]rune(findings[0].Details)), maxDetailsLength)
+}
@@ -0,0 +1,39 @@
+package internal
+
+import (
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Список администраторов задаётся через ADMIN_USERNAMES через запятую
+var adminUsernames = parseUsernames(os.Getenv("ADMIN_USERNAMES"))
+
+func parseUsernames(raw string) map[string]struct{} {
+	res := make(map[string]struct{})
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		if name != "" {
+			res[name] = struct{}{}
+		}
+	}
+	return res
+}
+
+func IsAdmin(username string) bool {
+	_, ok := adminUsernames[username]
+	return ok
+}
+
+// AdminMiddleware должен стоять после AuthMiddleware
+func AdminMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, ok := r.Context().Value(UsernameContextKey).(string)
+		if !ok || !IsAdmin(username) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,38 @@
+package internal
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminMiddleware(t *testing.T) {
+	adminUsernames = parseUsernames("boss, admin")
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	t.Run("admin", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/test", nil)
+		req = req.WithContext(context.WithValue(req.Context(), UsernameContextKey, "admin"))
+		rr := httptest.NewRecorder()
+
+		AdminMiddleware(handler).ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("not admin", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/test", nil)
+		req = req.WithContext(context.WithValue(req.Context(), UsernameContextKey, "employee"))
+		rr := httptest.NewRecorder()
+
+		AdminMiddleware(handler).ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+}
@@ -0,0 +1,68 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"ttavito/domain/entities"
+)
+
+const (
+	DefaultAuditLimit = 100
+	MaxAuditLimit     = 500
+)
+
+// ValidateAuditQueryMiddleware разбирает фильтры журнала: actor, action (admin.* - по префиксу),
+// target, requestId, from и to в RFC 3339, limit и beforeId для следующей страницы
+func ValidateAuditQueryMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		req := entities.AuditQuery{
+			Actor:     query.Get("actor"),
+			Action:    query.Get("action"),
+			Target:    query.Get("target"),
+			RequestID: query.Get("requestId"),
+			Limit:     DefaultAuditLimit,
+		}
+
+		for _, bound := range []struct {
+			name string
+			dst  **time.Time
+		}{{"from", &req.From}, {"to", &req.To}} {
+			raw := query.Get(bound.name)
+			if raw == "" {
+				continue
+			}
+			t, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				http.Error(w, bound.name+" must be in RFC 3339 format", http.StatusBadRequest)
+				return
+			}
+			*bound.dst = &t
+		}
+
+		if raw := query.Get("limit"); raw != "" {
+			limit, err := strconv.Atoi(raw)
+			if err != nil || limit <= 0 || limit > MaxAuditLimit {
+				http.Error(w, fmt.Sprintf("limit must be from 1 to %d", MaxAuditLimit), http.StatusBadRequest)
+				return
+			}
+			req.Limit = limit
+		}
+
+		if raw := query.Get("beforeId"); raw != "" {
+			beforeID, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil || beforeID <= 0 {
+				http.Error(w, "Invalid beforeId", http.StatusBadRequest)
+				return
+			}
+			req.BeforeID = beforeID
+		}
+
+		ctx := context.WithValue(r.Context(), ValidAuditQueryKey, req)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
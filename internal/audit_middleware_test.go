@@ -0,0 +1,49 @@
+package internal
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"ttavito/domain/entities"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateAuditQueryMiddleware(t *testing.T) {
+	from := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		query string
+		code  int
+		want  entities.AuditQuery
+	}{
+		{"defaults", "", http.StatusOK, entities.AuditQuery{Limit: DefaultAuditLimit}},
+		{"filters", "?actor=admin&action=admin.*&target=bob&from=2026-10-01T00:00:00Z&beforeId=42&limit=10", http.StatusOK, entities.AuditQuery{
+			Actor: "admin", Action: "admin.*", Target: "bob", From: &from, BeforeID: 42, Limit: 10,
+		}},
+		{"invalid from", "?from=yesterday", http.StatusBadRequest, entities.AuditQuery{}},
+		{"limit too large", "?limit=1000", http.StatusBadRequest, entities.AuditQuery{}},
+		{"invalid beforeId", "?beforeId=-1", http.StatusBadRequest, entities.AuditQuery{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got entities.AuditQuery
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Context().Value(ValidAuditQueryKey).(entities.AuditQuery)
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/test"+tt.query, nil)
+			rr := httptest.NewRecorder()
+
+			ValidateAuditQueryMiddleware(handler).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.code, rr.Code)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"ttavito/domain/entities"
+)
+
+const MaxBatchTransfers = 100
+
+func ValidateBatchSendCoinMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req entities.BatchSendCoinRequest
+
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid JSON format", http.StatusBadRequest)
+			return
+		}
+		defer r.Body.Close()
+
+		if len(req.Transfers) == 0 || len(req.Transfers) > MaxBatchTransfers {
+			http.Error(w, "Invalid input data", http.StatusBadRequest)
+			return
+		}
+
+		// Один получатель дважды в пакете почти всегда означает ошибку в списке
+		seen := make(map[string]struct{}, len(req.Transfers))
+		for i := range req.Transfers {
+			t := &req.Transfers[i]
+			if t.ToUser == "" || t.Amount <= 0 {
+				http.Error(w, "Invalid input data", http.StatusBadRequest)
+				return
+			}
+			if _, ok := seen[t.ToUser]; ok {
+				http.Error(w, "Duplicate recipient: "+t.ToUser, http.StatusBadRequest)
+				return
+			}
+			seen[t.ToUser] = struct{}{}
+
+			var err error
+			t.Message, err = SanitizeMessage(t.Message)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+
+		ctx := context.WithValue(r.Context(), ValidBatchSendKey, req)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
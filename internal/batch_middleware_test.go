@@ -0,0 +1,40 @@
+package internal
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateBatchSendCoinMiddleware(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		code int
+	}{
+		{"invalid json", `invalid json`, http.StatusBadRequest},
+		{"empty batch", `{"transfers": []}`, http.StatusBadRequest},
+		{"invalid amount", `{"transfers": [{"toUser": "user1", "amount": 0}]}`, http.StatusBadRequest},
+		{"duplicate recipient", `{"transfers": [{"toUser": "user1", "amount": 1}, {"toUser": "user1", "amount": 2}]}`, http.StatusBadRequest},
+		{"success", `{"transfers": [{"toUser": "user1", "amount": 1}, {"toUser": "user2", "amount": 2, "message": "спасибо"}]}`, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/test", bytes.NewBuffer([]byte(tt.body)))
+			rr := httptest.NewRecorder()
+
+			middleware := ValidateBatchSendCoinMiddleware(handler)
+			middleware.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.code, rr.Code)
+		})
+	}
+}
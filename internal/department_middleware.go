@@ -0,0 +1,133 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"ttavito/domain/entities"
+)
+
+const MaxDepartmentNameLength = 100
+
+func validDepartmentName(name string) bool {
+	return name != "" && len(name) <= MaxDepartmentNameLength && strings.TrimSpace(name) == name
+}
+
+func ValidateDepartmentMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req entities.DepartmentRequest
+
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid JSON format", http.StatusBadRequest)
+			return
+		}
+		defer r.Body.Close()
+
+		if !validDepartmentName(req.Name) || req.Manager == "" || req.Budget < 0 {
+			http.Error(w, "Invalid input data", http.StatusBadRequest)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), ValidDepartmentKey, req)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func ValidateDepartmentMembersMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req entities.DepartmentMembersRequest
+
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid JSON format", http.StatusBadRequest)
+			return
+		}
+		defer r.Body.Close()
+
+		req.Department = r.PathValue("department")
+		if !validDepartmentName(req.Department) || len(req.Usernames) == 0 || slices.Contains(req.Usernames, "") {
+			http.Error(w, "Invalid input data", http.StatusBadRequest)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), ValidMembersKey, req)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func ValidateBudgetAllocationMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req entities.BudgetAllocationRequest
+
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid JSON format", http.StatusBadRequest)
+			return
+		}
+		defer r.Body.Close()
+
+		req.Department = r.PathValue("department")
+		if !validDepartmentName(req.Department) || req.Amount <= 0 {
+			http.Error(w, "Invalid input data", http.StatusBadRequest)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), ValidAllocationKey, req)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func ValidateRecognitionMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req entities.RecognitionRequest
+
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid JSON format", http.StatusBadRequest)
+			return
+		}
+		defer r.Body.Close()
+
+		req.Department = r.PathValue("department")
+		if !validDepartmentName(req.Department) || req.ToUser == "" || req.Amount <= 0 {
+			http.Error(w, "Invalid input data", http.StatusBadRequest)
+			return
+		}
+
+		var err error
+		req.Message, err = SanitizeMessage(req.Message)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), ValidRecognizeKey, req)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// ValidateDepartmentReportMiddleware разбирает ?month=2025-02, по умолчанию - текущий месяц (UTC)
+func ValidateDepartmentReportMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := entities.DepartmentReportRequest{Department: r.PathValue("department")}
+		if !validDepartmentName(req.Department) {
+			http.Error(w, "Invalid input data", http.StatusBadRequest)
+			return
+		}
+
+		now := time.Now().UTC()
+		req.Month = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		if raw := r.URL.Query().Get("month"); raw != "" {
+			month, err := time.Parse("2006-01", raw)
+			if err != nil {
+				http.Error(w, "month must be in YYYY-MM format", http.StatusBadRequest)
+				return
+			}
+			req.Month = month
+		}
+
+		ctx := context.WithValue(r.Context(), ValidReportKey, req)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
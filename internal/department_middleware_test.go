@@ -0,0 +1,74 @@
+package internal
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"ttavito/domain/entities"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateRecognitionMiddleware(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		code int
+	}{
+		{"invalid json", `invalid json`, http.StatusBadRequest},
+		{"invalid amount", `{"toUser": "dev", "amount": -1}`, http.StatusBadRequest},
+		{"success", `{"toUser": "dev", "amount": 100, "message": "за релиз"}`, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				req := r.Context().Value(ValidRecognizeKey).(entities.RecognitionRequest)
+				assert.Equal(t, "platform", req.Department)
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/test", bytes.NewBuffer([]byte(tt.body)))
+			req.SetPathValue("department", "platform")
+			rr := httptest.NewRecorder()
+
+			ValidateRecognitionMiddleware(handler).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.code, rr.Code)
+		})
+	}
+}
+
+func TestValidateDepartmentReportMiddleware(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		code  int
+		month time.Time
+	}{
+		{"explicit month", "?month=2025-02", http.StatusOK, time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC)},
+		{"invalid month", "?month=02.2025", http.StatusBadRequest, time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got entities.DepartmentReportRequest
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Context().Value(ValidReportKey).(entities.DepartmentReportRequest)
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/test"+tt.query, nil)
+			req.SetPathValue("department", "platform")
+			rr := httptest.NewRecorder()
+
+			ValidateDepartmentReportMiddleware(handler).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.code, rr.Code)
+			assert.Equal(t, tt.month, got.Month)
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"ttavito/domain/entities"
+)
+
+// ValidateDigestSettingsMiddleware разбирает {"enabled": false}. Поле обязательно,
+// чтобы пустое тело случайно не отключило сводку
+func ValidateDigestSettingsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Enabled *bool `json:"enabled"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, "Invalid JSON format", http.StatusBadRequest)
+			return
+		}
+		defer r.Body.Close()
+
+		if body.Enabled == nil {
+			http.Error(w, "enabled is required", http.StatusBadRequest)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), ValidDigestKey, entities.DigestSettings{Enabled: *body.Enabled})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
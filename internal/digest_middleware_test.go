@@ -0,0 +1,45 @@
+package internal
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ttavito/domain/entities"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateDigestSettingsMiddleware(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		code int
+		want entities.DigestSettings
+	}{
+		{"disable", `{"enabled": false}`, http.StatusOK, entities.DigestSettings{Enabled: false}},
+		{"enable", `{"enabled": true}`, http.StatusOK, entities.DigestSettings{Enabled: true}},
+		{"missing field", `{}`, http.StatusBadRequest, entities.DigestSettings{}},
+		{"wrong type", `{"enabled": "no"}`, http.StatusBadRequest, entities.DigestSettings{}},
+		{"empty body", "", http.StatusBadRequest, entities.DigestSettings{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got entities.DigestSettings
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Context().Value(ValidDigestKey).(entities.DigestSettings)
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodPut, "/test", strings.NewReader(tt.body))
+			rr := httptest.NewRecorder()
+
+			ValidateDigestSettingsMiddleware(handler).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.code, rr.Code)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package internal
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// Подпротокол, за которым браузерный клиент передаёт токен в /api/ws
+const WebSocketTokenProtocol = "bearer"
+
+// WebSocketTokenMiddleware принимает токен из подпротокола: браузерный WebSocket не умеет
+// задавать заголовки, поэтому клиент передаёт Sec-WebSocket-Protocol: bearer, <токен>.
+// Ставится перед AuthMiddleware
+func WebSocketTokenMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			var protocols []string
+			for _, value := range r.Header.Values("Sec-WebSocket-Protocol") {
+				for _, p := range strings.Split(value, ",") {
+					protocols = append(protocols, strings.TrimSpace(p))
+				}
+			}
+			if i := slices.Index(protocols, WebSocketTokenProtocol); i >= 0 && i+1 < len(protocols) {
+				r.Header.Set("Authorization", "Bearer "+protocols[i+1])
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
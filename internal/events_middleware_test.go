@@ -0,0 +1,48 @@
+package internal
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebSocketTokenMiddleware(t *testing.T) {
+	jwttool := JWTTool{}
+	token, err := jwttool.GenerateToken("alice", "pass")
+	assert.NoError(t, err)
+
+	var (
+		username  string
+		expiresAt time.Time
+	)
+	handler := ChainMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, _ = r.Context().Value(UsernameContextKey).(string)
+		expiresAt, _ = r.Context().Value(TokenExpiresContextKey).(time.Time)
+		w.WriteHeader(http.StatusOK)
+	}), WebSocketTokenMiddleware, AuthMiddleware)
+
+	t.Run("token in subprotocol", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/ws", nil)
+		req.Header.Set("Sec-WebSocket-Protocol", "bearer, "+token)
+		rr := httptest.NewRecorder()
+
+		handler.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "alice", username)
+		assert.WithinDuration(t, time.Now().Add(24*time.Hour), expiresAt, time.Minute)
+	})
+
+	t.Run("no token", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/ws", nil)
+		req.Header.Set("Sec-WebSocket-Protocol", "bearer")
+		rr := httptest.NewRecorder()
+
+		handler.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+}
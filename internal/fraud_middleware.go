@@ -0,0 +1,86 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"ttavito/domain/entities"
+)
+
+const (
+	DefaultFraudFindingsLimit = 50
+	MaxFraudFindingsLimit     = 200
+)
+
+func ValidateFraudFindingsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		req := entities.FraudFindingsRequest{
+			Status: query.Get("status"),
+			Kind:   query.Get("kind"),
+			Limit:  DefaultFraudFindingsLimit,
+		}
+
+		switch req.Status {
+		case "", entities.FraudStatusOpen, entities.FraudStatusConfirmed, entities.FraudStatusDismissed:
+		default:
+			http.Error(w, "status must be open, confirmed or dismissed", http.StatusBadRequest)
+			return
+		}
+
+		switch req.Kind {
+		case "", entities.FraudKindCycle, entities.FraudKindFanIn, entities.FraudKindVelocity:
+		default:
+			http.Error(w, "kind must be cycle, fan_in or velocity", http.StatusBadRequest)
+			return
+		}
+
+		if raw := query.Get("limit"); raw != "" {
+			limit, err := strconv.Atoi(raw)
+			if err != nil || limit <= 0 || limit > MaxFraudFindingsLimit {
+				http.Error(w, fmt.Sprintf("limit must be from 1 to %d", MaxFraudFindingsLimit), http.StatusBadRequest)
+				return
+			}
+			req.Limit = limit
+		}
+
+		ctx := context.WithValue(r.Context(), ValidFindingsKey, req)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func ValidateFraudReviewMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req entities.FraudReviewRequest
+
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid JSON format", http.StatusBadRequest)
+			return
+		}
+		defer r.Body.Close()
+
+		req.ID = r.PathValue("id")
+		if !uuidRegexp.MatchString(req.ID) {
+			http.Error(w, "Invalid input data", http.StatusBadRequest)
+			return
+		}
+
+		if req.Status != entities.FraudStatusConfirmed && req.Status != entities.FraudStatusDismissed {
+			http.Error(w, "status must be confirmed or dismissed", http.StatusBadRequest)
+			return
+		}
+
+		var err error
+		req.Note, err = SanitizeMessage(req.Note)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), ValidReviewKey, req)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
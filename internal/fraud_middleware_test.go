@@ -0,0 +1,87 @@
+package internal
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ttavito/domain/entities"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateFraudFindingsMiddleware(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		code  int
+		want  entities.FraudFindingsRequest
+	}{
+		{"defaults", "", http.StatusOK, entities.FraudFindingsRequest{Limit: DefaultFraudFindingsLimit}},
+		{"filters", "?status=open&kind=cycle&limit=10", http.StatusOK, entities.FraudFindingsRequest{
+			Status: entities.FraudStatusOpen, Kind: entities.FraudKindCycle, Limit: 10,
+		}},
+		{"unknown status", "?status=closed", http.StatusBadRequest, entities.FraudFindingsRequest{}},
+		{"unknown kind", "?kind=smurfing", http.StatusBadRequest, entities.FraudFindingsRequest{}},
+		{"limit too large", "?limit=1000", http.StatusBadRequest, entities.FraudFindingsRequest{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got entities.FraudFindingsRequest
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Context().Value(ValidFindingsKey).(entities.FraudFindingsRequest)
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/test"+tt.query, nil)
+			rr := httptest.NewRecorder()
+
+			ValidateFraudFindingsMiddleware(handler).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.code, rr.Code)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestValidateFraudReviewMiddleware(t *testing.T) {
+	const id = "3f2b8c1e-4d5a-4b6c-8e7f-9a0b1c2d3e4f"
+
+	tests := []struct {
+		name string
+		id   string
+		body string
+		code int
+		want entities.FraudReviewRequest
+	}{
+		{"invalid id", "42", `{"status": "confirmed"}`, http.StatusBadRequest, entities.FraudReviewRequest{}},
+		{"invalid status", id, `{"status": "open"}`, http.StatusBadRequest, entities.FraudReviewRequest{}},
+		{"confirmed", id, `{"status": "confirmed", "note": " кольцо из трёх аккаунтов "}`, http.StatusOK, entities.FraudReviewRequest{
+			ID: id, Status: entities.FraudStatusConfirmed, Note: "кольцо из трёх аккаунтов",
+		}},
+		{"dismissed without note", id, `{"status": "dismissed"}`, http.StatusOK, entities.FraudReviewRequest{
+			ID: id, Status: entities.FraudStatusDismissed,
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got entities.FraudReviewRequest
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Context().Value(ValidReviewKey).(entities.FraudReviewRequest)
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/test", bytes.NewBufferString(tt.body))
+			req.SetPathValue("id", tt.id)
+			rr := httptest.NewRecorder()
+
+			ValidateFraudReviewMiddleware(handler).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.code, rr.Code)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"ttavito/domain/entities"
+)
+
+func ValidateFreezeMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req entities.FreezeRequest
+
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid JSON format", http.StatusBadRequest)
+			return
+		}
+		defer r.Body.Close()
+
+		req.Username = r.PathValue("username")
+		if req.Username == "" {
+			http.Error(w, "Invalid input data", http.StatusBadRequest)
+			return
+		}
+
+		var err error
+		req.Reason, err = sanitizeReason(req.Reason)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), ValidFreezeKey, req)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func ValidateUnfreezeMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req entities.UnfreezeRequest
+
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid JSON format", http.StatusBadRequest)
+			return
+		}
+		defer r.Body.Close()
+
+		req.Username = r.PathValue("username")
+		if req.Username == "" {
+			http.Error(w, "Invalid input data", http.StatusBadRequest)
+			return
+		}
+
+		var err error
+		req.Reason, err = sanitizeReason(req.Reason)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), ValidUnfreezeKey, req)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
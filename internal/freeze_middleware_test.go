@@ -0,0 +1,69 @@
+package internal
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ttavito/domain/entities"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateFreezeMiddleware(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		code int
+		want entities.FreezeRequest
+	}{
+		{"invalid json", `invalid json`, http.StatusBadRequest, entities.FreezeRequest{}},
+		{"reason required", `{"blockIncoming": true}`, http.StatusBadRequest, entities.FreezeRequest{}},
+		{"outgoing only", `{"reason": " проверка "}`, http.StatusOK, entities.FreezeRequest{
+			Username: "suspect", Reason: "проверка",
+		}},
+		{"block incoming", `{"reason": "проверка", "blockIncoming": true}`, http.StatusOK, entities.FreezeRequest{
+			Username: "suspect", Reason: "проверка", BlockIncoming: true,
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got entities.FreezeRequest
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Context().Value(ValidFreezeKey).(entities.FreezeRequest)
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/test", bytes.NewBuffer([]byte(tt.body)))
+			req.SetPathValue("username", "suspect")
+			rr := httptest.NewRecorder()
+
+			ValidateFreezeMiddleware(handler).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.code, rr.Code)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestValidateUnfreezeMiddleware(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, entities.UnfreezeRequest{Username: "suspect", Reason: "проверка завершена"},
+			r.Context().Value(ValidUnfreezeKey).(entities.UnfreezeRequest))
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/test", bytes.NewBufferString(`{}`))
+	req.SetPathValue("username", "suspect")
+	rr := httptest.NewRecorder()
+	ValidateUnfreezeMiddleware(handler).ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/test", bytes.NewBufferString(`{"reason": "проверка завершена"}`))
+	req.SetPathValue("username", "suspect")
+	rr = httptest.NewRecorder()
+	ValidateUnfreezeMiddleware(handler).ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
@@ -0,0 +1,37 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"ttavito/domain/entities"
+)
+
+func ValidateGiftMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req entities.GiftRequest
+
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid JSON format", http.StatusBadRequest)
+			return
+		}
+		defer r.Body.Close()
+
+		req.Item = r.PathValue("item")
+		if req.Item == "" || req.ToUser == "" {
+			http.Error(w, "Invalid input data", http.StatusBadRequest)
+			return
+		}
+
+		var err error
+		req.Message, err = SanitizeMessage(req.Message)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), ValidGiftKey, req)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,66 @@
+package internal
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ttavito/domain/entities"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateGiftMiddleware(t *testing.T) {
+	t.Run("missing recipient", func(t *testing.T) {
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/test", bytes.NewBuffer([]byte(`{"message": "hi"}`)))
+		req.SetPathValue("item", "cup")
+		rr := httptest.NewRecorder()
+
+		middleware := ValidateGiftMiddleware(handler)
+		middleware.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("message too long", func(t *testing.T) {
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+
+		body := `{"toUser": "user123", "message": "` + strings.Repeat("a", MaxMessageLength+1) + `"}`
+		req := httptest.NewRequest(http.MethodPost, "/test", bytes.NewBuffer([]byte(body)))
+		req.SetPathValue("item", "cup")
+		rr := httptest.NewRecorder()
+
+		middleware := ValidateGiftMiddleware(handler)
+		middleware.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			req, ok := r.Context().Value(ValidGiftKey).(entities.GiftRequest)
+			assert.True(t, ok)
+			assert.Equal(t, "cup", req.Item)
+			assert.Equal(t, "user123", req.ToUser)
+			assert.Equal(t, "с праздником", req.Message)
+			w.WriteHeader(http.StatusOK)
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/test", bytes.NewBuffer([]byte(`{"toUser": "user123", "message": " с\tпраздником "}`)))
+		req.SetPathValue("item", "cup")
+		rr := httptest.NewRecorder()
+
+		middleware := ValidateGiftMiddleware(handler)
+		middleware.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+}
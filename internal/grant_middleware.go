@@ -0,0 +1,150 @@
+package internal
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"ttavito/domain/entities"
+)
+
+const (
+	MaxBulkGrantRows  = 1000
+	MaxBulkGrantBytes = 1 << 20
+)
+
+func ValidateGrantMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req entities.GrantRequest
+
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid JSON format", http.StatusBadRequest)
+			return
+		}
+		defer r.Body.Close()
+
+		if req.ToUser == "" || req.Amount <= 0 {
+			http.Error(w, "Invalid input data", http.StatusBadRequest)
+			return
+		}
+
+		var err error
+		req.Reason, err = sanitizeReason(req.Reason)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), ValidGrantKey, req)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// ValidateBulkGrantMiddleware разбирает CSV со столбцами username,amount,reason из поля file
+// multipart-формы или из тела запроса. Ошибки в отдельных строках не прерывают разбор:
+// строка помечается invalid, чтобы в ответе были видны все проблемы файла сразу.
+func ValidateBulkGrantMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req entities.BulkGrantRequest
+
+		if raw := r.URL.Query().Get("dryRun"); raw != "" {
+			var err error
+			req.DryRun, err = strconv.ParseBool(raw)
+			if err != nil {
+				http.Error(w, "Invalid dryRun value", http.StatusBadRequest)
+				return
+			}
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, MaxBulkGrantBytes)
+		defer r.Body.Close()
+
+		var body io.Reader = r.Body
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+			file, _, err := r.FormFile("file")
+			if err != nil {
+				http.Error(w, "CSV file is required in the file field", http.StatusBadRequest)
+				return
+			}
+			defer file.Close()
+			body = file
+		}
+
+		rows, err := parseBulkGrantCSV(body)
+		if err != nil {
+			http.Error(w, "Invalid CSV: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if len(rows) == 0 || len(rows) > MaxBulkGrantRows {
+			http.Error(w, fmt.Sprintf("CSV must contain from 1 to %d rows", MaxBulkGrantRows), http.StatusBadRequest)
+			return
+		}
+		req.Rows = rows
+
+		ctx := context.WithValue(r.Context(), ValidBulkGrantKey, req)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func parseBulkGrantCSV(body io.Reader) ([]entities.BulkGrantRow, error) {
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var rows []entities.BulkGrantRow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		// Заголовок необязателен
+		if line == 1 && strings.EqualFold(strings.TrimSpace(record[0]), "username") {
+			continue
+		}
+
+		rows = append(rows, parseBulkGrantRecord(line, record))
+	}
+}
+
+func parseBulkGrantRecord(line int, record []string) entities.BulkGrantRow {
+	row := entities.BulkGrantRow{Line: line}
+
+	invalid := func(msg string) entities.BulkGrantRow {
+		row.Status = entities.GrantRowInvalid
+		row.Error = msg
+		return row
+	}
+
+	if len(record) != 3 {
+		return invalid(fmt.Sprintf("expected 3 columns, got %d", len(record)))
+	}
+
+	row.ToUser = strings.TrimSpace(record[0])
+	if row.ToUser == "" {
+		return invalid("username is required")
+	}
+
+	amount, err := strconv.Atoi(strings.TrimSpace(record[1]))
+	if err != nil || amount <= 0 {
+		return invalid("amount must be a positive integer")
+	}
+	row.Amount = amount
+
+	row.Reason, err = sanitizeReason(record[2])
+	if err != nil {
+		return invalid(err.Error())
+	}
+
+	return row
+}
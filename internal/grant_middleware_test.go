@@ -0,0 +1,103 @@
+package internal
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ttavito/domain/entities"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateGrantMiddleware(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		code int
+	}{
+		{"invalid json", `invalid json`, http.StatusBadRequest},
+		{"invalid amount", `{"toUser": "user1", "amount": 0, "reason": "release"}`, http.StatusBadRequest},
+		{"missing reason", `{"toUser": "user1", "amount": 10}`, http.StatusBadRequest},
+		{"success", `{"toUser": "user1", "amount": 10, "reason": "release"}`, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/test", bytes.NewBuffer([]byte(tt.body)))
+			rr := httptest.NewRecorder()
+
+			middleware := ValidateGrantMiddleware(handler)
+			middleware.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.code, rr.Code)
+		})
+	}
+}
+
+func TestValidateBulkGrantMiddleware(t *testing.T) {
+	t.Run("marks invalid rows and keeps line numbers", func(t *testing.T) {
+		body := "username,amount,reason\nuser1,100,release\nuser2,-5,oops\nuser3,10\nuser4,20,\"team, award\"\n"
+
+		var got entities.BulkGrantRequest
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = r.Context().Value(ValidBulkGrantKey).(entities.BulkGrantRequest)
+			w.WriteHeader(http.StatusOK)
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/test?dryRun=true", bytes.NewBufferString(body))
+		rr := httptest.NewRecorder()
+
+		ValidateBulkGrantMiddleware(handler).ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.True(t, got.DryRun)
+		assert.Len(t, got.Rows, 4)
+		assert.Equal(t, 2, got.Rows[0].Line)
+		assert.Empty(t, got.Rows[0].Status)
+		assert.Equal(t, entities.GrantRowInvalid, got.Rows[1].Status)
+		assert.Equal(t, entities.GrantRowInvalid, got.Rows[2].Status)
+		assert.Equal(t, "team, award", got.Rows[3].Reason)
+	})
+
+	t.Run("accepts multipart upload", func(t *testing.T) {
+		var buf bytes.Buffer
+		mw := multipart.NewWriter(&buf)
+		part, _ := mw.CreateFormFile("file", "grants.csv")
+		part.Write([]byte("user1,100,release\n"))
+		mw.Close()
+
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/test", &buf)
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		rr := httptest.NewRecorder()
+
+		ValidateBulkGrantMiddleware(handler).ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("rejects empty file and bad dryRun", func(t *testing.T) {
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+
+		for _, target := range []string{"/test", "/test?dryRun=maybe"} {
+			req := httptest.NewRequest(http.MethodPost, target, bytes.NewBufferString("username,amount,reason\n"))
+			rr := httptest.NewRecorder()
+
+			ValidateBulkGrantMiddleware(handler).ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+		}
+	})
+}
@@ -17,13 +17,27 @@ func (r *JWTTool) GenerateToken(username, password string) (string, error) {
 		"username": username,
 		"password": password,
 		"exp":      time.Now().Add(time.Hour * 24).Unix(),
+		"iat":      time.Now().Unix(),
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString(jwtSecret)
 }
 
+// TokenClaims - данные проверенного токена. У старых токенов без iat время выдачи нулевое
+type TokenClaims struct {
+	Username  string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+}
+
 func (r *JWTTool) ValidateToken(tokenString string) (string, error) {
+	claims, err := r.ParseToken(tokenString)
+	return claims.Username, err
+}
+
+// ParseToken дополнительно возвращает время выдачи и окончания действия токена
+func (r *JWTTool) ParseToken(tokenString string) (TokenClaims, error) {
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, errors.New("unexpected signing method")
@@ -32,16 +46,23 @@ func (r *JWTTool) ValidateToken(tokenString string) (string, error) {
 	})
 
 	if err != nil {
-		return "", err
+		return TokenClaims{}, err
 	}
 
 	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
 		username, exists := claims["username"].(string)
 		if !exists {
-			return "", errors.New("username not found in token")
+			return TokenClaims{}, errors.New("username not found in token")
+		}
+		res := TokenClaims{Username: username}
+		if iat, _ := claims.GetIssuedAt(); iat != nil {
+			res.IssuedAt = iat.Time
+		}
+		if exp, _ := claims.GetExpirationTime(); exp != nil {
+			res.ExpiresAt = exp.Time
 		}
-		return username, nil
+		return res, nil
 	}
 
-	return "", errors.New("invalid token")
+	return TokenClaims{}, errors.New("invalid token")
 }
@@ -3,7 +3,9 @@ package internal
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"regexp"
 	"strings"
 	"ttavito/domain/entities"
 )
@@ -16,9 +18,38 @@ type TokenValidator interface {
 
 const (
 	UsernameContextKey ContextKey = "username"
-	ValidSendCoinKey   ContextKey = "validSendCoinReq"
-	ValidAuthReqKey    ContextKey = "validAuthReq"
-	ValidBuyItemKey    ContextKey = "validBuyItemReq"
+	// Время окончания действия токена, нулевое - бессрочный
+	TokenExpiresContextKey ContextKey = "tokenExpiresAt"
+	ValidSendCoinKey       ContextKey = "validSendCoinReq"
+	ValidAuthReqKey        ContextKey = "validAuthReq"
+	ValidBuyItemKey        ContextKey = "validBuyItemReq"
+	ValidReverseKey        ContextKey = "validReverseTransferReq"
+	ValidGiftKey           ContextKey = "validGiftReq"
+	ValidBatchSendKey      ContextKey = "validBatchSendCoinReq"
+	ValidPathIDKey         ContextKey = "validPathID"
+	ValidScheduleKey       ContextKey = "validScheduledTransferReq"
+	ValidGrantKey          ContextKey = "validGrantReq"
+	ValidBulkGrantKey      ContextKey = "validBulkGrantReq"
+	ValidDepartmentKey     ContextKey = "validDepartmentReq"
+	ValidMembersKey        ContextKey = "validDepartmentMembersReq"
+	ValidAllocationKey     ContextKey = "validBudgetAllocationReq"
+	ValidRecognizeKey      ContextKey = "validRecognitionReq"
+	ValidReportKey         ContextKey = "validDepartmentReportReq"
+	ValidProfileKey        ContextKey = "validUpdateProfileReq"
+	ValidAvatarKey         ContextKey = "validAvatar"
+	ValidSearchKey         ContextKey = "validUserSearchReq"
+	ValidOffboardKey       ContextKey = "validOffboardReq"
+	ValidFreezeKey         ContextKey = "validFreezeReq"
+	ValidUnfreezeKey       ContextKey = "validUnfreezeReq"
+	ValidFindingsKey       ContextKey = "validFraudFindingsReq"
+	ValidReviewKey         ContextKey = "validFraudReviewReq"
+	ValidAuditQueryKey     ContextKey = "validAuditQuery"
+	ValidWebhookKey        ContextKey = "validWebhookReq"
+	ValidDeliveriesKey     ContextKey = "validWebhookDeliveriesReq"
+	ValidNotificationsKey  ContextKey = "validNotificationsReq"
+	ValidNotificationIDKey ContextKey = "validNotificationID"
+	ValidReadAllKey        ContextKey = "validReadNotificationsReq"
+	ValidDigestKey         ContextKey = "validDigestSettingsReq"
 )
 
 func ChainMiddleware(handler http.Handler, middlewares ...func(http.Handler) http.Handler) http.Handler {
@@ -52,6 +83,28 @@ func PostMethodMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+func PutMethodMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func DeleteMethodMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
 func AuthMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		authHeader := r.Header.Get("Authorization")
@@ -62,13 +115,27 @@ func AuthMiddleware(next http.Handler) http.Handler {
 
 		token := strings.TrimPrefix(authHeader, "Bearer ")
 		jwttool := JWTTool{}
-		username, err := jwttool.ValidateToken(token)
+		claims, err := jwttool.ParseToken(token)
+		username := claims.Username
 		if err != nil || username == "" {
 			http.Error(w, "Invalid token", http.StatusUnauthorized)
 			return
 		}
 
+		if sessions != nil {
+			revoked, err := sessions.revoked(r.Context(), username, claims.IssuedAt)
+			if err != nil {
+				http.Error(w, "Can't check session", http.StatusServiceUnavailable)
+				return
+			}
+			if revoked {
+				http.Error(w, "Session revoked", http.StatusUnauthorized)
+				return
+			}
+		}
+
 		ctx := context.WithValue(r.Context(), UsernameContextKey, username)
+		ctx = context.WithValue(ctx, TokenExpiresContextKey, claims.ExpiresAt)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
@@ -88,6 +155,13 @@ func ValidateSendCoinMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
+		var err error
+		req.Message, err = SanitizeMessage(req.Message)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
 		ctx := context.WithValue(r.Context(), ValidSendCoinKey, req)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
@@ -126,3 +200,31 @@ func ValdateAuthRequestMiddleware(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
+
+var uuidRegexp = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// ValidatePathIDMiddleware проверяет, что {id} в пути - UUID
+func ValidatePathIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+		if !uuidRegexp.MatchString(id) {
+			http.Error(w, "Invalid input data", http.StatusBadRequest)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), ValidPathIDKey, id)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// Причина начисления обязательна, чтобы по записи было понятно, за что выданы монеты
+func sanitizeReason(reason string) (string, error) {
+	reason, err := SanitizeMessage(reason)
+	if err != nil {
+		return "", err
+	}
+	if reason == "" {
+		return "", errors.New("reason is required")
+	}
+	return reason, nil
+}
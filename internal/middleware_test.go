@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"ttavito/domain/entities"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -111,6 +113,38 @@ func TestValidateSendCoinMiddleware(t *testing.T) {
 
 		assert.Equal(t, http.StatusOK, rr.Code)
 	})
+
+	t.Run("message too long", func(t *testing.T) {
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+
+		body := `{"toUser": "user123", "amount": 100, "message": "` + strings.Repeat("a", MaxMessageLength+1) + `"}`
+		req := httptest.NewRequest(http.MethodPost, "/test", bytes.NewBuffer([]byte(body)))
+		rr := httptest.NewRecorder()
+
+		middleware := ValidateSendCoinMiddleware(handler)
+		middleware.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("sanitizes message", func(t *testing.T) {
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			req, ok := r.Context().Value(ValidSendCoinKey).(entities.SendCoinRequest)
+			assert.True(t, ok)
+			assert.Equal(t, "спасибо за ревью", req.Message)
+			w.WriteHeader(http.StatusOK)
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/test", bytes.NewBuffer([]byte(`{"toUser": "user123", "amount": 100, "message": "спасибо\u0007 за\n ревью "}`)))
+		rr := httptest.NewRecorder()
+
+		middleware := ValidateSendCoinMiddleware(handler)
+		middleware.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
 }
 
 func TestValdateAuthRequestMiddleware(t *testing.T) {
@@ -156,3 +190,29 @@ func TestValdateAuthRequestMiddleware(t *testing.T) {
 		assert.Equal(t, http.StatusOK, rr.Code)
 	})
 }
+
+func TestValidatePathIDMiddleware(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/test", nil)
+		req.SetPathValue("id", "42")
+		rr := httptest.NewRecorder()
+
+		ValidatePathIDMiddleware(handler).ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/test", nil)
+		req.SetPathValue("id", "0b6b1f8e-3f43-4a53-9a4c-2f0f6b0d8f11")
+		rr := httptest.NewRecorder()
+
+		ValidatePathIDMiddleware(handler).ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+}
@@ -0,0 +1,91 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"ttavito/domain/entities"
+)
+
+const (
+	DefaultNotificationsLimit = 20
+	MaxNotificationsLimit     = 100
+)
+
+// ValidateNotificationsMiddleware разбирает ?unread=true&limit=20&beforeId=
+func ValidateNotificationsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		req := entities.NotificationsRequest{Limit: DefaultNotificationsLimit}
+
+		if raw := query.Get("unread"); raw != "" {
+			unread, err := strconv.ParseBool(raw)
+			if err != nil {
+				http.Error(w, "unread must be true or false", http.StatusBadRequest)
+				return
+			}
+			req.UnreadOnly = unread
+		}
+
+		if raw := query.Get("limit"); raw != "" {
+			limit, err := strconv.Atoi(raw)
+			if err != nil || limit <= 0 || limit > MaxNotificationsLimit {
+				http.Error(w, fmt.Sprintf("limit must be from 1 to %d", MaxNotificationsLimit), http.StatusBadRequest)
+				return
+			}
+			req.Limit = limit
+		}
+
+		if raw := query.Get("beforeId"); raw != "" {
+			beforeID, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil || beforeID <= 0 {
+				http.Error(w, "Invalid beforeId", http.StatusBadRequest)
+				return
+			}
+			req.BeforeID = beforeID
+		}
+
+		ctx := context.WithValue(r.Context(), ValidNotificationsKey, req)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// ValidateNotificationIDMiddleware проверяет числовой id уведомления в пути
+func ValidateNotificationIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil || id <= 0 {
+			http.Error(w, "Invalid input data", http.StatusBadRequest)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), ValidNotificationIDKey, id)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// ValidateReadNotificationsMiddleware разбирает необязательное тело {"upToId": 42}
+func ValidateReadNotificationsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req entities.ReadNotificationsRequest
+
+		err := json.NewDecoder(r.Body).Decode(&req)
+		defer r.Body.Close()
+		if err != nil && !errors.Is(err, io.EOF) {
+			http.Error(w, "Invalid JSON format", http.StatusBadRequest)
+			return
+		}
+		if req.UpToID < 0 {
+			http.Error(w, "Invalid upToId", http.StatusBadRequest)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), ValidReadAllKey, req)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
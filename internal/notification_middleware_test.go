@@ -0,0 +1,96 @@
+package internal
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ttavito/domain/entities"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateNotificationsMiddleware(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		code  int
+		want  entities.NotificationsRequest
+	}{
+		{"defaults", "", http.StatusOK, entities.NotificationsRequest{Limit: DefaultNotificationsLimit}},
+		{"filters", "?unread=true&limit=5&beforeId=42", http.StatusOK, entities.NotificationsRequest{
+			UnreadOnly: true, BeforeID: 42, Limit: 5,
+		}},
+		{"invalid unread", "?unread=yes", http.StatusBadRequest, entities.NotificationsRequest{}},
+		{"limit too large", "?limit=1000", http.StatusBadRequest, entities.NotificationsRequest{}},
+		{"invalid beforeId", "?beforeId=-1", http.StatusBadRequest, entities.NotificationsRequest{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got entities.NotificationsRequest
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Context().Value(ValidNotificationsKey).(entities.NotificationsRequest)
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/test"+tt.query, nil)
+			rr := httptest.NewRecorder()
+
+			ValidateNotificationsMiddleware(handler).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.code, rr.Code)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestValidateNotificationIDMiddleware(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, int64(42), r.Context().Value(ValidNotificationIDKey))
+		w.WriteHeader(http.StatusOK)
+	})
+
+	for id, code := range map[string]int{"42": http.StatusOK, "0": http.StatusBadRequest, "abc": http.StatusBadRequest} {
+		req := httptest.NewRequest(http.MethodPost, "/test", nil)
+		req.SetPathValue("id", id)
+		rr := httptest.NewRecorder()
+
+		ValidateNotificationIDMiddleware(handler).ServeHTTP(rr, req)
+
+		assert.Equal(t, code, rr.Code, id)
+	}
+}
+
+func TestValidateReadNotificationsMiddleware(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		code int
+		want entities.ReadNotificationsRequest
+	}{
+		{"empty body", "", http.StatusOK, entities.ReadNotificationsRequest{}},
+		{"up to id", `{"upToId": 42}`, http.StatusOK, entities.ReadNotificationsRequest{UpToID: 42}},
+		{"negative", `{"upToId": -1}`, http.StatusBadRequest, entities.ReadNotificationsRequest{}},
+		{"invalid json", `{`, http.StatusBadRequest, entities.ReadNotificationsRequest{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got entities.ReadNotificationsRequest
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Context().Value(ValidReadAllKey).(entities.ReadNotificationsRequest)
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/test", strings.NewReader(tt.body))
+			rr := httptest.NewRecorder()
+
+			ValidateReadNotificationsMiddleware(handler).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.code, rr.Code)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
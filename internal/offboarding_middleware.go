@@ -0,0 +1,58 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"ttavito/domain/entities"
+)
+
+func ValidateOffboardMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req entities.OffboardRequest
+
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid JSON format", http.StatusBadRequest)
+			return
+		}
+		defer r.Body.Close()
+
+		req.Username = r.PathValue("username")
+		if req.Username == "" {
+			http.Error(w, "Invalid input data", http.StatusBadRequest)
+			return
+		}
+
+		if req.BalancePolicy == "" {
+			req.BalancePolicy = entities.BalancePolicyForfeit
+		}
+		if req.BalancePolicy == entities.BalancePolicyForfeit && len(req.Recipients) > 0 {
+			http.Error(w, "recipients are only allowed with redistribute policy", http.StatusBadRequest)
+			return
+		}
+		if len(req.Recipients) > MaxBatchTransfers {
+			http.Error(w, fmt.Sprintf("At most %d recipients allowed", MaxBatchTransfers), http.StatusBadRequest)
+			return
+		}
+		seen := make(map[string]bool, len(req.Recipients))
+		for _, recipient := range req.Recipients {
+			if recipient == "" || seen[recipient] {
+				http.Error(w, "Recipients must be unique and non-empty", http.StatusBadRequest)
+				return
+			}
+			seen[recipient] = true
+		}
+
+		var err error
+		req.Reason, err = sanitizeReason(req.Reason)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), ValidOffboardKey, req)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,51 @@
+package internal
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ttavito/domain/entities"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateOffboardMiddleware(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		code int
+		want entities.OffboardRequest
+	}{
+		{"invalid json", `invalid json`, http.StatusBadRequest, entities.OffboardRequest{}},
+		{"reason required", `{"balancePolicy": "forfeit"}`, http.StatusBadRequest, entities.OffboardRequest{}},
+		{"recipients with forfeit", `{"recipients": ["bob"], "reason": "увольнение"}`, http.StatusBadRequest, entities.OffboardRequest{}},
+		{"duplicate recipients", `{"balancePolicy": "redistribute", "recipients": ["bob", "bob"], "reason": "увольнение"}`, http.StatusBadRequest, entities.OffboardRequest{}},
+		{"default policy", `{"reason": " увольнение "}`, http.StatusOK, entities.OffboardRequest{
+			Username: "leaver", BalancePolicy: entities.BalancePolicyForfeit, Reason: "увольнение",
+		}},
+		{"redistribute", `{"balancePolicy": "redistribute", "recipients": ["bob", "carol"], "reason": "увольнение"}`, http.StatusOK, entities.OffboardRequest{
+			Username: "leaver", BalancePolicy: entities.BalancePolicyRedistribute, Recipients: []string{"bob", "carol"}, Reason: "увольнение",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got entities.OffboardRequest
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Context().Value(ValidOffboardKey).(entities.OffboardRequest)
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/test", bytes.NewBuffer([]byte(tt.body)))
+			req.SetPathValue("username", "leaver")
+			rr := httptest.NewRecorder()
+
+			ValidateOffboardMiddleware(handler).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.code, rr.Code)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"unicode/utf8"
+
+	"ttavito/domain/entities"
+)
+
+const (
+	// Совпадает с размером колонок user_profiles
+	MaxProfileFieldLength = 100
+	MaxAvatarBytes        = 1 << 20
+)
+
+// Форматы, которые браузер покажет в <img>. Тип определяется по содержимому, а не по заголовку
+var avatarContentTypes = []string{"image/png", "image/jpeg", "image/gif", "image/webp"}
+
+func ValidateProfileMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req entities.UpdateProfileRequest
+
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid JSON format", http.StatusBadRequest)
+			return
+		}
+		defer r.Body.Close()
+
+		var err error
+		req.DisplayName, err = sanitizeProfileField("displayName", req.DisplayName)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		req.Title, err = sanitizeProfileField("title", req.Title)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), ValidProfileKey, req)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func sanitizeProfileField(name, value string) (string, error) {
+	value = sanitizeText(value)
+	if utf8.RuneCountInString(value) > MaxProfileFieldLength {
+		return "", fmt.Errorf("%s is too long, max %d characters", name, MaxProfileFieldLength)
+	}
+	return value, nil
+}
+
+// ValidateAvatarMiddleware читает картинку из тела запроса целиком
+func ValidateAvatarMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, MaxAvatarBytes)
+		defer r.Body.Close()
+
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Avatar must be at most %d bytes", MaxAvatarBytes), http.StatusRequestEntityTooLarge)
+			return
+		}
+		if len(data) == 0 {
+			http.Error(w, "Avatar image is required", http.StatusBadRequest)
+			return
+		}
+
+		contentType := http.DetectContentType(data)
+		if !slices.Contains(avatarContentTypes, contentType) {
+			http.Error(w, "Avatar must be a PNG, JPEG, GIF or WebP image", http.StatusUnsupportedMediaType)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), ValidAvatarKey, entities.Avatar{Data: data, ContentType: contentType})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
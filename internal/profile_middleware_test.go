@@ -0,0 +1,76 @@
+package internal
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ttavito/domain/entities"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateProfileMiddleware(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		code int
+		want entities.UpdateProfileRequest
+	}{
+		{"invalid json", `invalid json`, http.StatusBadRequest, entities.UpdateProfileRequest{}},
+		{"too long", `{"displayName": "` + strings.Repeat("я", MaxProfileFieldLength+1) + `"}`, http.StatusBadRequest, entities.UpdateProfileRequest{}},
+		{"sanitized", `{"displayName": "  Боб​  Иванов ", "title": "QA\n"}`, http.StatusOK, entities.UpdateProfileRequest{DisplayName: "Боб Иванов", Title: "QA"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got entities.UpdateProfileRequest
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Context().Value(ValidProfileKey).(entities.UpdateProfileRequest)
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodPut, "/test", bytes.NewBuffer([]byte(tt.body)))
+			rr := httptest.NewRecorder()
+
+			ValidateProfileMiddleware(handler).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.code, rr.Code)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestValidateAvatarMiddleware(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+	tests := []struct {
+		name string
+		body []byte
+		code int
+	}{
+		{"empty", nil, http.StatusBadRequest},
+		{"not an image", []byte("<svg onload=alert(1)>"), http.StatusUnsupportedMediaType},
+		{"too large", append(png, make([]byte, MaxAvatarBytes)...), http.StatusRequestEntityTooLarge},
+		{"png", png, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				avatar := r.Context().Value(ValidAvatarKey).(entities.Avatar)
+				assert.Equal(t, "image/png", avatar.ContentType)
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodPut, "/test", bytes.NewReader(tt.body))
+			rr := httptest.NewRecorder()
+
+			ValidateAvatarMiddleware(handler).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.code, rr.Code)
+		})
+	}
+}
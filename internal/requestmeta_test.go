@@ -0,0 +1,58 @@
+package internal
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ttavito/audit"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestMetaMiddleware(t *testing.T) {
+	tests := []struct {
+		name       string
+		trustProxy bool
+		requestID  string
+		forwarded  string
+		wantID     string
+		wantIP     string
+	}{
+		{"generated id", false, "", "", "", "192.0.2.1"},
+		{"client id", false, "abc-123", "", "abc-123", "192.0.2.1"},
+		{"invalid client id", false, "abc 123<script>", "", "", "192.0.2.1"},
+		{"forwarded ignored", false, "", "203.0.113.7", "", "192.0.2.1"},
+		{"forwarded trusted", true, "", "203.0.113.7, 10.0.0.1", "", "203.0.113.7"},
+		{"forwarded garbage", true, "", "unknown", "", "192.0.2.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got audit.Meta
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = audit.MetaFrom(r.Context())
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			req.RemoteAddr = "192.0.2.1:54321"
+			if tt.requestID != "" {
+				req.Header.Set(RequestIDHeader, tt.requestID)
+			}
+			if tt.forwarded != "" {
+				req.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+			rr := httptest.NewRecorder()
+
+			RequestMetaMiddleware(tt.trustProxy)(handler).ServeHTTP(rr, req)
+
+			if tt.wantID != "" {
+				assert.Equal(t, tt.wantID, got.RequestID)
+			} else {
+				assert.Len(t, got.RequestID, 32)
+			}
+			assert.Equal(t, got.RequestID, rr.Header().Get(RequestIDHeader))
+			assert.Equal(t, tt.wantIP, got.ClientIP)
+		})
+	}
+}
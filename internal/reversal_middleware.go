@@ -0,0 +1,33 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"ttavito/domain/entities"
+)
+
+func ValidateReverseTransferMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req entities.ReverseTransferRequest
+
+		// Тело запроса необязательное
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+			http.Error(w, "Invalid JSON format", http.StatusBadRequest)
+			return
+		}
+		defer r.Body.Close()
+
+		req.TransferID = r.PathValue("id")
+		if !uuidRegexp.MatchString(req.TransferID) {
+			http.Error(w, "Invalid input data", http.StatusBadRequest)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), ValidReverseKey, req)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,65 @@
+package internal
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ttavito/domain/entities"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateReverseTransferMiddleware(t *testing.T) {
+	t.Run("invalid id", func(t *testing.T) {
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/test", nil)
+		req.SetPathValue("id", "not-a-uuid")
+		rr := httptest.NewRecorder()
+
+		middleware := ValidateReverseTransferMiddleware(handler)
+		middleware.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("success without body", func(t *testing.T) {
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			req, ok := r.Context().Value(ValidReverseKey).(entities.ReverseTransferRequest)
+			assert.True(t, ok)
+			assert.False(t, req.AllowPartial)
+			w.WriteHeader(http.StatusOK)
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/test", nil)
+		req.SetPathValue("id", "0b6b1f8e-3f43-4a53-9a4c-2f0f6b0d8f11")
+		rr := httptest.NewRecorder()
+
+		middleware := ValidateReverseTransferMiddleware(handler)
+		middleware.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("success with partial", func(t *testing.T) {
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			req, ok := r.Context().Value(ValidReverseKey).(entities.ReverseTransferRequest)
+			assert.True(t, ok)
+			assert.True(t, req.AllowPartial)
+			w.WriteHeader(http.StatusOK)
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/test", bytes.NewBuffer([]byte(`{"allowPartial": true}`)))
+		req.SetPathValue("id", "0b6b1f8e-3f43-4a53-9a4c-2f0f6b0d8f11")
+		rr := httptest.NewRecorder()
+
+		middleware := ValidateReverseTransferMiddleware(handler)
+		middleware.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+}
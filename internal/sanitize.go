@@ -0,0 +1,43 @@
+package internal
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Совпадает с размером колонок для сообщений в БД
+const MaxMessageLength = 200
+
+var ErrMessageTooLong = errors.New("message is too long")
+
+// SanitizeMessage убирает управляющие и невидимые символы, схлопывает пробелы
+// и проверяет длину сообщения в символах
+func SanitizeMessage(msg string) (string, error) {
+	res := sanitizeText(msg)
+	if utf8.RuneCountInString(res) > MaxMessageLength {
+		return "", ErrMessageTooLong
+	}
+	return res, nil
+}
+
+func sanitizeText(msg string) string {
+	if !utf8.ValidString(msg) {
+		msg = strings.ToValidUTF8(msg, "")
+	}
+
+	var b strings.Builder
+	for _, r := range msg {
+		switch {
+		case unicode.IsSpace(r):
+			b.WriteRune(' ')
+		case unicode.IsControl(r), unicode.Is(unicode.Cf, r):
+			continue
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	return strings.Join(strings.Fields(b.String()), " ")
+}
@@ -0,0 +1,27 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSanitizeMessage(t *testing.T) {
+	t.Run("strips control characters and collapses spaces", func(t *testing.T) {
+		res, err := SanitizeMessage("  спасибо\x00 за\n\n помощь​! ")
+		assert.NoError(t, err)
+		assert.Equal(t, "спасибо за помощь!", res)
+	})
+
+	t.Run("counts runes, not bytes", func(t *testing.T) {
+		res, err := SanitizeMessage(strings.Repeat("я", MaxMessageLength))
+		assert.NoError(t, err)
+		assert.Len(t, []rune(res), MaxMessageLength)
+	})
+
+	t.Run("too long", func(t *testing.T) {
+		_, err := SanitizeMessage(strings.Repeat("a", MaxMessageLength+1))
+		assert.ErrorIs(t, err, ErrMessageTooLong)
+	})
+}
@@ -0,0 +1,54 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"ttavito/cron"
+	"ttavito/domain/entities"
+)
+
+func ValidateScheduledTransferMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req entities.ScheduledTransferRequest
+
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid JSON format", http.StatusBadRequest)
+			return
+		}
+		defer r.Body.Close()
+
+		if req.ToUser == "" || req.Amount <= 0 {
+			http.Error(w, "Invalid input data", http.StatusBadRequest)
+			return
+		}
+
+		// Нужно ровно одно из двух: разовая дата или cron-выражение
+		if (req.RunAt == nil) == (req.Cron == "") {
+			http.Error(w, "Exactly one of runAt or cron is required", http.StatusBadRequest)
+			return
+		}
+		if req.RunAt != nil && !req.RunAt.After(time.Now()) {
+			http.Error(w, "runAt must be in the future", http.StatusBadRequest)
+			return
+		}
+		if req.Cron != "" {
+			if _, err := cron.Parse(req.Cron); err != nil {
+				http.Error(w, "Invalid cron: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+
+		var err error
+		req.Message, err = SanitizeMessage(req.Message)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), ValidScheduleKey, req)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,46 @@
+package internal
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateScheduledTransferMiddleware(t *testing.T) {
+	future := time.Now().Add(time.Hour).Format(time.RFC3339)
+	past := time.Now().Add(-time.Hour).Format(time.RFC3339)
+
+	tests := []struct {
+		name string
+		body string
+		code int
+	}{
+		{"invalid json", `invalid json`, http.StatusBadRequest},
+		{"neither runAt nor cron", `{"toUser": "user1", "amount": 10}`, http.StatusBadRequest},
+		{"both runAt and cron", `{"toUser": "user1", "amount": 10, "runAt": "` + future + `", "cron": "0 9 * * *"}`, http.StatusBadRequest},
+		{"runAt in the past", `{"toUser": "user1", "amount": 10, "runAt": "` + past + `"}`, http.StatusBadRequest},
+		{"invalid cron", `{"toUser": "user1", "amount": 10, "cron": "every monday"}`, http.StatusBadRequest},
+		{"one-off", `{"toUser": "user1", "amount": 10, "runAt": "` + future + `"}`, http.StatusOK},
+		{"recurring", `{"toUser": "user1", "amount": 10, "cron": "0 9 1 * *", "message": "ежемесячное спасибо"}`, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/test", bytes.NewBuffer([]byte(tt.body)))
+			rr := httptest.NewRecorder()
+
+			middleware := ValidateScheduledTransferMiddleware(handler)
+			middleware.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.code, rr.Code)
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"unicode/utf8"
+
+	"ttavito/domain/entities"
+)
+
+const (
+	MaxSearchQueryLength = 100
+	DefaultSearchLimit   = 10
+	MaxSearchLimit       = 50
+)
+
+func ValidateUserSearchMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		req := entities.UserSearchRequest{
+			Query: sanitizeText(query.Get("q")),
+			Limit: DefaultSearchLimit,
+		}
+		if req.Query == "" || utf8.RuneCountInString(req.Query) > MaxSearchQueryLength {
+			http.Error(w, fmt.Sprintf("q must be from 1 to %d characters", MaxSearchQueryLength), http.StatusBadRequest)
+			return
+		}
+
+		if raw := query.Get("limit"); raw != "" {
+			limit, err := strconv.Atoi(raw)
+			if err != nil || limit <= 0 || limit > MaxSearchLimit {
+				http.Error(w, fmt.Sprintf("limit must be from 1 to %d", MaxSearchLimit), http.StatusBadRequest)
+				return
+			}
+			req.Limit = limit
+		}
+
+		if raw := query.Get("offset"); raw != "" {
+			offset, err := strconv.Atoi(raw)
+			if err != nil || offset < 0 {
+				http.Error(w, "Invalid offset", http.StatusBadRequest)
+				return
+			}
+			req.Offset = offset
+		}
+
+		ctx := context.WithValue(r.Context(), ValidSearchKey, req)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,45 @@
+package internal
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ttavito/domain/entities"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateUserSearchMiddleware(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		code  int
+		want  entities.UserSearchRequest
+	}{
+		{"missing query", "", http.StatusBadRequest, entities.UserSearchRequest{}},
+		{"blank query", "?q=%20%20", http.StatusBadRequest, entities.UserSearchRequest{}},
+		{"invalid limit", "?q=bob&limit=500", http.StatusBadRequest, entities.UserSearchRequest{}},
+		{"invalid offset", "?q=bob&offset=-1", http.StatusBadRequest, entities.UserSearchRequest{}},
+		{"defaults", "?q=%20bob", http.StatusOK, entities.UserSearchRequest{Query: "bob", Limit: DefaultSearchLimit}},
+		{"paginated", "?q=bob&limit=5&offset=10", http.StatusOK, entities.UserSearchRequest{Query: "bob", Limit: 5, Offset: 10}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got entities.UserSearchRequest
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Context().Value(ValidSearchKey).(entities.UserSearchRequest)
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/test"+tt.query, nil)
+			rr := httptest.NewRecorder()
+
+			ValidateUserSearchMiddleware(handler).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.code, rr.Code)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package internal
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	// Как долго AuthMiddleware помнит момент отзыва токенов пользователя.
+	// Столько же после увольнения ещё могут проходить запросы со старым токеном
+	sessionCacheTTL = 30 * time.Second
+
+	sessionCacheSweepSize = 10000
+)
+
+// SessionStore отдаёт момент, раньше которого выданные пользователю токены недействительны.
+// Нулевое время - токены не отзывались
+type SessionStore interface {
+	SessionsRevokedAt(ctx context.Context, username string) (time.Time, error)
+}
+
+// Без хранилища, например в тестах, отзыв токенов не проверяется
+var sessions *sessionCache
+
+// SetSessionStore включает проверку отзыва токенов в AuthMiddleware
+func SetSessionStore(store SessionStore) {
+	sessions = newSessionCache(store, sessionCacheTTL)
+}
+
+type sessionCache struct {
+	mu      sync.Mutex
+	store   SessionStore
+	ttl     time.Duration
+	entries map[string]sessionEntry
+	now     func() time.Time
+}
+
+type sessionEntry struct {
+	revokedAt time.Time
+	loadedAt  time.Time
+}
+
+func newSessionCache(store SessionStore, ttl time.Duration) *sessionCache {
+	return &sessionCache{
+		store:   store,
+		ttl:     ttl,
+		entries: make(map[string]sessionEntry),
+		now:     time.Now,
+	}
+}
+
+// revoked сообщает, отозван ли токен username, выданный в issuedAt
+func (c *sessionCache) revoked(ctx context.Context, username string, issuedAt time.Time) (bool, error) {
+	now := c.now()
+
+	c.mu.Lock()
+	entry, ok := c.entries[username]
+	c.mu.Unlock()
+
+	if !ok || now.Sub(entry.loadedAt) >= c.ttl {
+		revokedAt, err := c.store.SessionsRevokedAt(ctx, username)
+		if err != nil {
+			return false, err
+		}
+		entry = sessionEntry{revokedAt: revokedAt, loadedAt: now}
+
+		c.mu.Lock()
+		if len(c.entries) >= sessionCacheSweepSize {
+			c.sweep(now)
+		}
+		c.entries[username] = entry
+		c.mu.Unlock()
+	}
+
+	// iat хранится с точностью до секунды, поэтому токен, выданный в ту же секунду, что и отзыв, тоже отклоняется
+	return !entry.revokedAt.IsZero() && issuedAt.Before(entry.revokedAt), nil
+}
+
+func (c *sessionCache) sweep(now time.Time) {
+	for username, entry := range c.entries {
+		if now.Sub(entry.loadedAt) >= c.ttl {
+			delete(c.entries, username)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeSessionStore struct {
+	revokedAt map[string]time.Time
+	calls     int
+	err       error
+}
+
+func (s *fakeSessionStore) SessionsRevokedAt(ctx context.Context, username string) (time.Time, error) {
+	s.calls++
+	return s.revokedAt[username], s.err
+}
+
+func TestSessionCache(t *testing.T) {
+	revokedAt := time.Date(2025, time.February, 14, 12, 0, 0, 500, time.UTC)
+	store := &fakeSessionStore{revokedAt: map[string]time.Time{"leaver": revokedAt}}
+	cache := newSessionCache(store, time.Minute)
+	now := revokedAt.Add(time.Second)
+	cache.now = func() time.Time { return now }
+
+	revoked, err := cache.revoked(context.Background(), "leaver", revokedAt.Add(-time.Hour))
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+
+	// Токен выдан в ту же секунду, что и отзыв: iat без долей секунды меньше момента отзыва
+	revoked, _ = cache.revoked(context.Background(), "leaver", revokedAt.Truncate(time.Second))
+	assert.True(t, revoked)
+
+	revoked, _ = cache.revoked(context.Background(), "leaver", revokedAt.Add(time.Second))
+	assert.False(t, revoked)
+
+	revoked, _ = cache.revoked(context.Background(), "bob", time.Time{})
+	assert.False(t, revoked)
+
+	assert.Equal(t, 2, store.calls)
+
+	now = now.Add(time.Minute)
+	cache.revoked(context.Background(), "leaver", now)
+	assert.Equal(t, 3, store.calls)
+}
+
+func TestAuthMiddlewareRevokedSession(t *testing.T) {
+	jwttool := JWTTool{}
+	token, err := jwttool.GenerateToken("leaver", "pass")
+	assert.NoError(t, err)
+
+	store := &fakeSessionStore{revokedAt: map[string]time.Time{"leaver": time.Now().Add(time.Hour)}}
+	sessions = newSessionCache(store, time.Minute)
+	defer func() { sessions = nil }()
+
+	handler := AuthMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	send := func() int {
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, send())
+
+	store.revokedAt = nil
+	sessions = newSessionCache(store, time.Minute)
+	assert.Equal(t, http.StatusOK, send())
+
+	store.err = errors.New("db is down")
+	sessions = newSessionCache(store, time.Minute)
+	assert.Equal(t, http.StatusServiceUnavailable, send())
+}
@@ -0,0 +1,120 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"ttavito/domain/entities"
+	"ttavito/webhook"
+)
+
+const (
+	// Совпадают с размерами колонок webhook_subscriptions
+	MaxWebhookURLLength    = 2048
+	MinWebhookSecretLength = 16
+	MaxWebhookSecretLength = 128
+
+	DefaultWebhookDeliveriesLimit = 50
+	MaxWebhookDeliveriesLimit     = 200
+)
+
+// ValidateWebhookMiddleware проверяет подписку: абсолютный http(s) адрес не во внутренней сети,
+// известные типы событий и секрет, если он задан. Имена хостов проверяются при отправке,
+// после разрешения в адрес
+func ValidateWebhookMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req entities.WebhookRequest
+
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid JSON format", http.StatusBadRequest)
+			return
+		}
+		defer r.Body.Close()
+
+		u, err := url.Parse(req.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || len(req.URL) > MaxWebhookURLLength {
+			http.Error(w, "url must be an absolute http or https URL", http.StatusBadRequest)
+			return
+		}
+		if !publicWebhookHost(u.Hostname()) {
+			http.Error(w, "url must not point to a loopback, private or link-local address", http.StatusBadRequest)
+			return
+		}
+
+		if len(req.EventTypes) == 0 {
+			http.Error(w, "eventTypes must not be empty", http.StatusBadRequest)
+			return
+		}
+		for _, eventType := range req.EventTypes {
+			switch eventType {
+			case entities.EventCoinsTransferred, entities.EventItemPurchased:
+			default:
+				http.Error(w, "eventTypes must contain only coins.transferred and item.purchased", http.StatusBadRequest)
+				return
+			}
+		}
+		slices.Sort(req.EventTypes)
+		req.EventTypes = slices.Compact(req.EventTypes)
+
+		if req.Secret != "" && (len(req.Secret) < MinWebhookSecretLength || len(req.Secret) > MaxWebhookSecretLength) {
+			http.Error(w, fmt.Sprintf("secret must be from %d to %d characters", MinWebhookSecretLength, MaxWebhookSecretLength), http.StatusBadRequest)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), ValidWebhookKey, req)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func publicWebhookHost(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return webhook.PublicAddr(addr)
+	}
+	return true
+}
+
+func ValidateWebhookDeliveriesMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		req := entities.WebhookDeliveriesRequest{
+			SubscriptionID: r.PathValue("id"),
+			Status:         query.Get("status"),
+			Limit:          DefaultWebhookDeliveriesLimit,
+		}
+
+		if !uuidRegexp.MatchString(req.SubscriptionID) {
+			http.Error(w, "Invalid input data", http.StatusBadRequest)
+			return
+		}
+
+		switch req.Status {
+		case "", entities.WebhookDeliveryPending, entities.WebhookDeliveryDelivered, entities.WebhookDeliveryDead:
+		default:
+			http.Error(w, "status must be pending, delivered or dead", http.StatusBadRequest)
+			return
+		}
+
+		if raw := query.Get("limit"); raw != "" {
+			limit, err := strconv.Atoi(raw)
+			if err != nil || limit <= 0 || limit > MaxWebhookDeliveriesLimit {
+				http.Error(w, fmt.Sprintf("limit must be from 1 to %d", MaxWebhookDeliveriesLimit), http.StatusBadRequest)
+				return
+			}
+			req.Limit = limit
+		}
+
+		ctx := context.WithValue(r.Context(), ValidDeliveriesKey, req)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,92 @@
+package internal
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ttavito/domain/entities"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateWebhookMiddleware(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		code int
+		want entities.WebhookRequest
+	}{
+		{"valid", `{"url":"https://hooks.example.com/x","eventTypes":["item.purchased","coins.transferred","item.purchased"]}`, http.StatusOK,
+			entities.WebhookRequest{URL: "https://hooks.example.com/x", EventTypes: []string{"coins.transferred", "item.purchased"}}},
+		{"with secret", `{"url":"http://bot:8080/hook","eventTypes":["coins.transferred"],"secret":"0123456789abcdef"}`, http.StatusOK,
+			entities.WebhookRequest{URL: "http://bot:8080/hook", EventTypes: []string{"coins.transferred"}, Secret: "0123456789abcdef"}},
+		{"relative url", `{"url":"/hook","eventTypes":["coins.transferred"]}`, http.StatusBadRequest, entities.WebhookRequest{}},
+		{"other scheme", `{"url":"ftp://example.com","eventTypes":["coins.transferred"]}`, http.StatusBadRequest, entities.WebhookRequest{}},
+		{"loopback", `{"url":"http://127.0.0.1:8080/hook","eventTypes":["coins.transferred"]}`, http.StatusBadRequest, entities.WebhookRequest{}},
+		{"localhost", `{"url":"http://localhost/hook","eventTypes":["coins.transferred"]}`, http.StatusBadRequest, entities.WebhookRequest{}},
+		{"private", `{"url":"https://10.0.0.5/hook","eventTypes":["coins.transferred"]}`, http.StatusBadRequest, entities.WebhookRequest{}},
+		{"link-local", `{"url":"http://169.254.169.254/latest","eventTypes":["coins.transferred"]}`, http.StatusBadRequest, entities.WebhookRequest{}},
+		{"mapped ipv6", `{"url":"http://[::ffff:192.168.1.1]/hook","eventTypes":["coins.transferred"]}`, http.StatusBadRequest, entities.WebhookRequest{}},
+		{"no events", `{"url":"https://example.com","eventTypes":[]}`, http.StatusBadRequest, entities.WebhookRequest{}},
+		{"unknown event", `{"url":"https://example.com","eventTypes":["user.deleted"]}`, http.StatusBadRequest, entities.WebhookRequest{}},
+		{"short secret", `{"url":"https://example.com","eventTypes":["coins.transferred"],"secret":"123"}`, http.StatusBadRequest, entities.WebhookRequest{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got entities.WebhookRequest
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Context().Value(ValidWebhookKey).(entities.WebhookRequest)
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/test", strings.NewReader(tt.body))
+			rr := httptest.NewRecorder()
+
+			ValidateWebhookMiddleware(handler).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.code, rr.Code)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestValidateWebhookDeliveriesMiddleware(t *testing.T) {
+	id := "11111111-1111-1111-1111-111111111111"
+	tests := []struct {
+		name  string
+		id    string
+		query string
+		code  int
+		want  entities.WebhookDeliveriesRequest
+	}{
+		{"defaults", id, "", http.StatusOK, entities.WebhookDeliveriesRequest{SubscriptionID: id, Limit: DefaultWebhookDeliveriesLimit}},
+		{"filters", id, "?status=dead&limit=5", http.StatusOK, entities.WebhookDeliveriesRequest{
+			SubscriptionID: id, Status: entities.WebhookDeliveryDead, Limit: 5,
+		}},
+		{"invalid id", "abc", "", http.StatusBadRequest, entities.WebhookDeliveriesRequest{}},
+		{"unknown status", id, "?status=failed", http.StatusBadRequest, entities.WebhookDeliveriesRequest{}},
+		{"limit too large", id, "?limit=1000", http.StatusBadRequest, entities.WebhookDeliveriesRequest{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got entities.WebhookDeliveriesRequest
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Context().Value(ValidDeliveriesKey).(entities.WebhookDeliveriesRequest)
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/test"+tt.query, nil)
+			req.SetPathValue("id", tt.id)
+			rr := httptest.NewRecorder()
+
+			ValidateWebhookDeliveriesMiddleware(handler).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.code, rr.Code)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
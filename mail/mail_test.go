@@ -0,0 +1,191 @@
+package mail
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"ttavito/domain/entities"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// smtpStandIn - минимальный SMTP-сервер: принимает одно письмо и отдаёт его в канал
+type smtpStandIn struct {
+	addr     string
+	received chan smtpEnvelope
+}
+
+type smtpEnvelope struct {
+	from string
+	to   []string
+	data string
+}
+
+func newSMTPStandIn(t *testing.T, rejectRcpt bool) *smtpStandIn {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	s := &smtpStandIn{addr: ln.Addr().String(), received: make(chan smtpEnvelope, 1)}
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		s.serve(conn, rejectRcpt)
+	}()
+	return s
+}
+
+func (s *smtpStandIn) serve(conn net.Conn, rejectRcpt bool) {
+	r := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+
+	var env smtpEnvelope
+	reply("220 localhost ESMTP stand-in")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.TrimRight(line, "\r\n")
+		switch verb := strings.ToUpper(strings.SplitN(cmd, " ", 2)[0]); verb {
+		case "EHLO", "HELO":
+			reply("250 localhost")
+		case "MAIL":
+			env.from = strings.TrimPrefix(cmd, "MAIL FROM:")
+			reply("250 OK")
+		case "RCPT":
+			if rejectRcpt {
+				reply("550 No such user")
+				continue
+			}
+			env.to = append(env.to, strings.TrimPrefix(cmd, "RCPT TO:"))
+			reply("250 OK")
+		case "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(line, "."))
+			}
+			env.data = data.String()
+			reply("250 OK queued")
+		case "QUIT":
+			reply("221 Bye")
+			s.received <- env
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+func TestSMTPSender(t *testing.T) {
+	server := newSMTPStandIn(t, false)
+	sender := NewSMTPSender(SMTPConfig{Addr: server.addr, From: "Магазин <merch@example.com>", Timeout: 5 * time.Second})
+
+	err := sender.Send(context.Background(), entities.EmailMessage{
+		To:      "alice@example.com",
+		Subject: "Ваша неделя",
+		Text:    "Привет, Алиса",
+		HTML:    "<p>Привет, Алиса</p>",
+	})
+	assert.NoError(t, err)
+
+	var env smtpEnvelope
+	select {
+	case env = <-server.received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("message was not delivered")
+	}
+	assert.Equal(t, "<merch@example.com>", env.from)
+	assert.Equal(t, []string{"<alice@example.com>"}, env.to)
+
+	msg, err := mail.ReadMessage(strings.NewReader(env.data))
+	if !assert.NoError(t, err) {
+		return
+	}
+	subject, _ := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	assert.Equal(t, "Ваша неделя", subject)
+	assert.Equal(t, "<alice@example.com>", msg.Header.Get("To"))
+	assert.NotEmpty(t, msg.Header.Get("Message-ID"))
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	assert.NoError(t, err)
+	assert.Equal(t, "multipart/alternative", mediaType)
+
+	parts := multipart.NewReader(msg.Body, params["boundary"])
+	var contentTypes, bodies []string
+	for {
+		part, err := parts.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if !assert.NoError(t, err) {
+			return
+		}
+		// multipart.Reader сам снимает quoted-printable
+		body, _ := io.ReadAll(part)
+		contentTypes = append(contentTypes, part.Header.Get("Content-Type"))
+		bodies = append(bodies, string(body))
+	}
+	assert.Equal(t, []string{"text/plain; charset=utf-8", "text/html; charset=utf-8"}, contentTypes)
+	assert.Equal(t, []string{"Привет, Алиса", "<p>Привет, Алиса</p>"}, bodies)
+}
+
+func TestSMTPSenderRejected(t *testing.T) {
+	server := newSMTPStandIn(t, true)
+	sender := NewSMTPSender(SMTPConfig{Addr: server.addr, From: "merch@example.com", Timeout: 5 * time.Second})
+
+	err := sender.Send(context.Background(), entities.EmailMessage{To: "ghost@example.com", Subject: "Hi", Text: "Hi"})
+	assert.ErrorContains(t, err, "RCPT TO")
+	assert.ErrorIs(t, err, entities.ErrEmailRejected)
+}
+
+func TestBuild(t *testing.T) {
+	t.Run("plain text", func(t *testing.T) {
+		data, err := Build("merch@example.com", entities.EmailMessage{To: "bob@example.com", Subject: "Hi", Text: "Всего 100 монет"}, time.Now())
+		assert.NoError(t, err)
+
+		msg, err := mail.ReadMessage(strings.NewReader(string(data)))
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, "text/plain; charset=utf-8", msg.Header.Get("Content-Type"))
+		assert.Equal(t, "quoted-printable", msg.Header.Get("Content-Transfer-Encoding"))
+	})
+
+	t.Run("header injection", func(t *testing.T) {
+		data, err := Build("merch@example.com", entities.EmailMessage{To: "bob@example.com", Subject: "Hi\r\nBcc: eve@example.com", Text: "x"}, time.Now())
+		assert.NoError(t, err)
+
+		msg, err := mail.ReadMessage(strings.NewReader(string(data)))
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Empty(t, msg.Header.Get("Bcc"))
+	})
+
+	t.Run("invalid recipient", func(t *testing.T) {
+		_, err := Build("merch@example.com", entities.EmailMessage{To: "not an address", Text: "x"}, time.Now())
+		assert.ErrorIs(t, err, entities.ErrInvalidEmailAddress)
+	})
+}
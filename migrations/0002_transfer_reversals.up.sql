@@ -0,0 +1,4 @@
+-- Сторнирующий перевод ссылается на исходный перевод
+ALTER TABLE transfers ADD COLUMN IF NOT EXISTS reversal_of UUID REFERENCES transfers (id);
+
+CREATE INDEX IF NOT EXISTS idx_transfers_reversal_of ON transfers(reversal_of);
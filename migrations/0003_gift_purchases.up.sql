@@ -0,0 +1,8 @@
+-- Кто оплатил покупку: для подарка отличается от владельца (username)
+ALTER TABLE purchases ADD COLUMN IF NOT EXISTS buyer_username VARCHAR(100) REFERENCES users (username);
+ALTER TABLE purchases ADD COLUMN IF NOT EXISTS gift_message VARCHAR(200);
+
+UPDATE purchases SET buyer_username = username WHERE buyer_username IS NULL;
+ALTER TABLE purchases ALTER COLUMN buyer_username SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_purchases_buyer ON purchases(buyer_username);
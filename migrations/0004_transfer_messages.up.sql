@@ -0,0 +1,2 @@
+-- Сообщение к переводу ("спасибо за ..."), длина совпадает с internal.MaxMessageLength
+ALTER TABLE transfers ADD COLUMN IF NOT EXISTS message VARCHAR(200);
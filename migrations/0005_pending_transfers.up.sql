@@ -0,0 +1,19 @@
+-- Переводы, ожидающие подтверждения получателем.
+-- Сумма списывается с отправителя при создании и либо зачисляется получателю,
+-- либо возвращается отправителю при отказе или истечении срока.
+CREATE TABLE IF NOT EXISTS pending_transfers (
+   id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+   sender_username VARCHAR(100) NOT NULL REFERENCES users (username),
+   receiver_username VARCHAR(100) NOT NULL REFERENCES users (username),
+   amount INT NOT NULL CHECK (amount > 0),
+   message VARCHAR(200),
+   status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'accepted', 'declined', 'expired')),
+   transfer_id UUID REFERENCES transfers (id), -- заполняется после подтверждения
+   created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+   expires_at TIMESTAMPTZ NOT NULL,
+   resolved_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_pending_transfers_receiver ON pending_transfers(receiver_username) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_pending_transfers_sender ON pending_transfers(sender_username) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_pending_transfers_expires ON pending_transfers(expires_at) WHERE status = 'pending';
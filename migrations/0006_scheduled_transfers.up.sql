@@ -0,0 +1,30 @@
+-- Отложенные (cron_expr IS NULL) и регулярные переводы
+CREATE TABLE IF NOT EXISTS scheduled_transfers (
+   id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+   sender_username VARCHAR(100) NOT NULL REFERENCES users (username),
+   receiver_username VARCHAR(100) NOT NULL REFERENCES users (username),
+   amount INT NOT NULL CHECK (amount > 0),
+   message VARCHAR(200),
+   cron_expr VARCHAR(100),
+   status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'paused', 'cancelled', 'completed', 'failed')),
+   next_run_at TIMESTAMPTZ, -- NULL, когда запусков больше не будет
+   last_run_at TIMESTAMPTZ,
+   last_status VARCHAR(30),
+   last_error TEXT,
+   failure_count INT NOT NULL DEFAULT 0,
+   created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- История запусков, в том числе неудачных из-за нехватки монет
+CREATE TABLE IF NOT EXISTS scheduled_transfer_runs (
+   id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+   schedule_id UUID NOT NULL REFERENCES scheduled_transfers (id),
+   run_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+   status VARCHAR(30) NOT NULL, -- success | insufficient_balance | failed
+   transfer_id UUID REFERENCES transfers (id),
+   error TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_scheduled_transfers_sender ON scheduled_transfers(sender_username);
+CREATE INDEX IF NOT EXISTS idx_scheduled_transfers_due ON scheduled_transfers(next_run_at) WHERE status = 'active';
+CREATE INDEX IF NOT EXISTS idx_scheduled_transfer_runs_schedule ON scheduled_transfer_runs(schedule_id);
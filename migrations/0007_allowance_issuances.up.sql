@@ -0,0 +1,12 @@
+-- Начисления периодического пособия. Уникальность (period, username) не даёт
+-- начислить пособие дважды за период, даже если выдача запущена повторно
+CREATE TABLE IF NOT EXISTS allowance_issuances (
+   id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+   period VARCHAR(20) NOT NULL, -- 2025-02, 2025-W07 или 2025-02-14
+   username VARCHAR(100) NOT NULL REFERENCES users (username),
+   amount INT NOT NULL CHECK (amount > 0),
+   created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+   UNIQUE (period, username)
+);
+
+CREATE INDEX IF NOT EXISTS idx_allowance_issuances_username ON allowance_issuances(username);
@@ -0,0 +1,13 @@
+-- Начисления монет администратором, по одному или пакетом из CSV (batch_id)
+CREATE TABLE IF NOT EXISTS coin_grants (
+   id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+   batch_id UUID, -- общий для всех строк одной загрузки CSV
+   granted_by VARCHAR(100) NOT NULL REFERENCES users (username),
+   username VARCHAR(100) NOT NULL REFERENCES users (username),
+   amount INT NOT NULL CHECK (amount > 0),
+   reason VARCHAR(200) NOT NULL,
+   created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_coin_grants_username ON coin_grants(username);
+CREATE INDEX IF NOT EXISTS idx_coin_grants_batch ON coin_grants(batch_id) WHERE batch_id IS NOT NULL;
@@ -0,0 +1,22 @@
+-- Партии монет: каждое поступление на баланс - отдельная партия со своим сроком жизни.
+-- Списания расходуют самые старые партии, сумма remaining по пользователю равна users.balance
+CREATE TABLE IF NOT EXISTS coin_lots (
+   id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+   username VARCHAR(100) NOT NULL REFERENCES users (username),
+   amount INT NOT NULL CHECK (amount > 0), -- сколько поступило
+   remaining INT NOT NULL CHECK (remaining >= 0 AND remaining <= amount), -- сколько ещё не потрачено
+   expired_amount INT NOT NULL DEFAULT 0, -- сколько сгорело
+   source VARCHAR(20) NOT NULL, -- initial | transfer | refund | reversal | grant | allowance
+   issued_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+   expires_at TIMESTAMPTZ -- NULL - бессрочно
+);
+
+CREATE INDEX IF NOT EXISTS idx_coin_lots_open ON coin_lots(username, issued_at) WHERE remaining > 0;
+CREATE INDEX IF NOT EXISTS idx_coin_lots_expiring ON coin_lots(expires_at) WHERE remaining > 0;
+
+-- Монеты, начисленные до появления партий, не сгорают
+INSERT INTO coin_lots (username, amount, remaining, source)
+SELECT username, balance, balance, 'initial'
+FROM users
+WHERE balance > 0
+  AND NOT EXISTS (SELECT 1 FROM coin_lots l WHERE l.username = users.username);
@@ -0,0 +1,28 @@
+-- Отделы с бюджетом на поощрения. Бюджет отдела не связан с личными балансами
+CREATE TABLE IF NOT EXISTS departments (
+   name VARCHAR(100) PRIMARY KEY,
+   manager_username VARCHAR(100) NOT NULL REFERENCES users (username),
+   budget INT NOT NULL DEFAULT 0 CHECK (budget >= 0), -- остаток бюджета
+   created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Сотрудник состоит не более чем в одном отделе
+CREATE TABLE IF NOT EXISTS department_members (
+   username VARCHAR(100) PRIMARY KEY REFERENCES users (username),
+   department_name VARCHAR(100) NOT NULL REFERENCES departments (name)
+);
+
+-- Движение бюджета: пополнения администратором и поощрения сотрудников руководителем
+CREATE TABLE IF NOT EXISTS department_budget_entries (
+   id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+   department_name VARCHAR(100) NOT NULL REFERENCES departments (name),
+   kind VARCHAR(20) NOT NULL CHECK (kind IN ('allocation', 'recognition')),
+   actor_username VARCHAR(100) NOT NULL REFERENCES users (username),
+   recipient_username VARCHAR(100) REFERENCES users (username), -- только для recognition
+   amount INT NOT NULL CHECK (amount > 0),
+   message VARCHAR(200),
+   created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_department_members_department ON department_members(department_name);
+CREATE INDEX IF NOT EXISTS idx_department_budget_entries_department ON department_budget_entries(department_name, created_at);
@@ -0,0 +1,20 @@
+-- Справочник сотрудников: руководитель, команды и состав команд.
+-- Отделы и членство в отделах - в 0010_departments
+ALTER TABLE users ADD COLUMN IF NOT EXISTS manager_username VARCHAR(100) REFERENCES users (username);
+
+CREATE TABLE IF NOT EXISTS teams (
+   name VARCHAR(100) PRIMARY KEY,
+   department_name VARCHAR(100) NOT NULL REFERENCES departments (name),
+   lead_username VARCHAR(100) REFERENCES users (username),
+   created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Сотрудник состоит не более чем в одной команде
+CREATE TABLE IF NOT EXISTS team_members (
+   username VARCHAR(100) PRIMARY KEY REFERENCES users (username),
+   team_name VARCHAR(100) NOT NULL REFERENCES teams (name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_users_manager ON users(manager_username);
+CREATE INDEX IF NOT EXISTS idx_teams_department ON teams(department_name);
+CREATE INDEX IF NOT EXISTS idx_team_members_team ON team_members(team_name);
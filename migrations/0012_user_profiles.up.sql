@@ -0,0 +1,10 @@
+-- Профиль сотрудника. Отдел и команда берутся из справочника (department_members, team_members)
+CREATE TABLE IF NOT EXISTS user_profiles (
+   username VARCHAR(100) PRIMARY KEY REFERENCES users (username),
+   display_name VARCHAR(100),
+   title VARCHAR(100),
+   avatar_key VARCHAR(200), -- ключ объекта в хранилище аватаров
+   avatar_content_type VARCHAR(50),
+   avatar_updated_at TIMESTAMPTZ,
+   updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,5 @@
+-- Поиск получателя по логину и отображаемому имени: префикс и нечёткое совпадение
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_users_username_trgm ON users USING GIN (username gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_user_profiles_display_name_trgm ON user_profiles USING GIN (display_name gin_trgm_ops);
@@ -0,0 +1,19 @@
+-- Заполняется при увольнении: такие пользователи не получают переводы и не попадают в поиск
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deactivated_at TIMESTAMPTZ;
+
+-- Токены, выданные до этого момента, недействительны
+ALTER TABLE users ADD COLUMN IF NOT EXISTS sessions_revoked_at TIMESTAMPTZ;
+
+-- Журнал увольнений: кто уволил, причина и что стало с балансом
+CREATE TABLE IF NOT EXISTS user_offboardings (
+   id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+   username VARCHAR(100) NOT NULL UNIQUE REFERENCES users (username),
+   admin_username VARCHAR(100) NOT NULL REFERENCES users (username),
+   reason VARCHAR(200) NOT NULL,
+   balance_policy VARCHAR(20) NOT NULL CHECK (balance_policy IN ('forfeit', 'redistribute')),
+   balance_amount INT NOT NULL CHECK (balance_amount >= 0), -- сгоревший или распределённый остаток
+   recipients VARCHAR(100)[] NOT NULL DEFAULT '{}',
+   pending_refunded INT NOT NULL DEFAULT 0,
+   schedules_cancelled INT NOT NULL DEFAULT 0,
+   created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
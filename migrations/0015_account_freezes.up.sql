@@ -0,0 +1,15 @@
+-- Заморозка счёта на время проверки. Снятая заморозка остаётся в истории
+CREATE TABLE IF NOT EXISTS account_freezes (
+   id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+   username VARCHAR(100) NOT NULL REFERENCES users (username),
+   reason VARCHAR(200) NOT NULL,
+   block_incoming BOOLEAN NOT NULL DEFAULT FALSE, -- запрещены и входящие переводы
+   frozen_by VARCHAR(100) NOT NULL REFERENCES users (username),
+   frozen_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+   unfrozen_by VARCHAR(100) REFERENCES users (username),
+   unfrozen_at TIMESTAMPTZ,
+   unfreeze_reason VARCHAR(200)
+);
+
+-- Действующая заморозка у пользователя одна
+CREATE UNIQUE INDEX IF NOT EXISTS idx_account_freezes_active ON account_freezes(username) WHERE unfrozen_at IS NULL;
@@ -0,0 +1,23 @@
+-- Момент регистрации. У пользователей, созданных до миграции, он неизвестен (NULL)
+ALTER TABLE users ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ;
+ALTER TABLE users ALTER COLUMN created_at SET DEFAULT CURRENT_TIMESTAMP;
+
+-- Очередь подозрительных схем переводов на разбор администратором
+CREATE TABLE IF NOT EXISTS fraud_findings (
+   id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+   fingerprint VARCHAR(500) NOT NULL UNIQUE, -- одна и та же схема не попадает в очередь дважды
+   kind VARCHAR(20) NOT NULL CHECK (kind IN ('cycle', 'fan_in', 'velocity')),
+   username VARCHAR(100) NOT NULL REFERENCES users (username), -- на кого указывает находка
+   participants VARCHAR(100)[] NOT NULL,
+   transfer_ids UUID[] NOT NULL,
+   amount INT NOT NULL,
+   details VARCHAR(500) NOT NULL,
+   status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'confirmed', 'dismissed')),
+   detected_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+   reviewed_by VARCHAR(100) REFERENCES users (username),
+   reviewed_at TIMESTAMPTZ,
+   review_note VARCHAR(200)
+);
+
+CREATE INDEX IF NOT EXISTS idx_fraud_findings_status ON fraud_findings(status, detected_at);
+CREATE INDEX IF NOT EXISTS idx_transfers_created_at ON transfers(created_at);
@@ -0,0 +1,34 @@
+-- Журнал действий: кто, что, над кем и с какими параметрами. Пишется в транзакции самого действия
+CREATE TABLE IF NOT EXISTS audit_events (
+   id BIGSERIAL PRIMARY KEY,
+   actor VARCHAR(100) NOT NULL, -- пользователь, администратор или system для фоновых задач и CLI
+   action VARCHAR(50) NOT NULL,
+   target VARCHAR(200) NOT NULL DEFAULT '',
+   payload JSONB NOT NULL DEFAULT '{}',
+   request_id VARCHAR(64) NOT NULL DEFAULT '',
+   client_ip VARCHAR(64) NOT NULL DEFAULT '',
+   created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_actor ON audit_events(actor, id);
+CREATE INDEX IF NOT EXISTS idx_audit_events_target ON audit_events(target, id);
+CREATE INDEX IF NOT EXISTS idx_audit_events_action ON audit_events(action, id);
+CREATE INDEX IF NOT EXISTS idx_audit_events_request ON audit_events(request_id) WHERE request_id <> '';
+CREATE INDEX IF NOT EXISTS idx_audit_events_created_at ON audit_events(created_at);
+
+-- Журнал только дополняется: изменение и удаление записей запрещены
+CREATE OR REPLACE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+BEGIN
+   RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_events_no_update ON audit_events;
+CREATE TRIGGER audit_events_no_update
+   BEFORE UPDATE OR DELETE ON audit_events
+   FOR EACH ROW EXECUTE FUNCTION audit_events_append_only();
+
+DROP TRIGGER IF EXISTS audit_events_no_truncate ON audit_events;
+CREATE TRIGGER audit_events_no_truncate
+   BEFORE TRUNCATE ON audit_events
+   FOR EACH STATEMENT EXECUTE FUNCTION audit_events_append_only();
@@ -0,0 +1,7 @@
+-- Цепочка хешей: hash каждой записи считается от hash предыдущей.
+-- Записи, сделанные до цепочки, остаются без хешей и не проверяются
+ALTER TABLE audit_events ADD COLUMN IF NOT EXISTS prev_hash VARCHAR(64);
+ALTER TABLE audit_events ADD COLUMN IF NOT EXISTS hash VARCHAR(64);
+
+-- От одной записи цепочка не может ветвиться
+CREATE UNIQUE INDEX IF NOT EXISTS idx_audit_events_prev_hash ON audit_events(prev_hash) WHERE prev_hash IS NOT NULL;
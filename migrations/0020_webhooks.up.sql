@@ -0,0 +1,31 @@
+-- Подписки внешних систем на доменные события. Удалённая подписка остаётся ради журнала доставок
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+   id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+   url VARCHAR(2048) NOT NULL,
+   event_types VARCHAR(64)[] NOT NULL,
+   secret VARCHAR(128) NOT NULL, -- ключ HMAC-подписи доставок
+   created_by VARCHAR(100) NOT NULL REFERENCES users (username),
+   created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+   deleted_at TIMESTAMPTZ
+);
+
+-- Доставка события подписчику, она же запись журнала доставок.
+-- dead - попытки кончились, доставку можно перезапустить вручную
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+   id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+   subscription_id UUID NOT NULL REFERENCES webhook_subscriptions (id),
+   event_id BIGINT NOT NULL,
+   event_type VARCHAR(64) NOT NULL,
+   payload JSONB NOT NULL, -- тело запроса, как его получит подписчик
+   status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'delivered', 'dead')),
+   attempts INT NOT NULL DEFAULT 0,
+   next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+   last_status_code INT,
+   last_error VARCHAR(500),
+   created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+   delivered_at TIMESTAMPTZ,
+   UNIQUE (subscription_id, event_id) -- повторная публикация события не дублирует доставку
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_pending ON webhook_deliveries(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_subscription ON webhook_deliveries(subscription_id, created_at);
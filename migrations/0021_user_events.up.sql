@@ -0,0 +1,62 @@
+-- События для потока /api/events. Уведомление уходит при коммите транзакции всем репликам,
+-- которые слушают канал user_events
+CREATE OR REPLACE FUNCTION notify_user_event(target VARCHAR, event_type TEXT, data JSONB) RETURNS void AS $$
+BEGIN
+   PERFORM pg_notify('user_events', json_build_object('username', target, 'type', event_type, 'data', data)::text);
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION users_notify_balance() RETURNS trigger AS $$
+BEGIN
+   PERFORM notify_user_event(NEW.username, 'balance.updated', jsonb_build_object('balance', NEW.balance));
+   RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS users_balance_updated ON users;
+CREATE TRIGGER users_balance_updated
+   AFTER UPDATE OF balance ON users
+   FOR EACH ROW WHEN (OLD.balance IS DISTINCT FROM NEW.balance)
+   EXECUTE FUNCTION users_notify_balance();
+
+CREATE OR REPLACE FUNCTION transfers_notify_received() RETURNS trigger AS $$
+BEGIN
+   PERFORM notify_user_event(NEW.receiver_username, 'coins.received', jsonb_build_object(
+      'transferId', NEW.id,
+      'fromUser', NEW.sender_username,
+      'amount', NEW.amount,
+      'message', COALESCE(NEW.message, ''),
+      'reversal', NEW.reversal_of IS NOT NULL
+   ));
+   RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS transfers_coins_received ON transfers;
+CREATE TRIGGER transfers_coins_received
+   AFTER INSERT ON transfers
+   FOR EACH ROW EXECUTE FUNCTION transfers_notify_received();
+
+-- Покупка проводится сразу, поэтому статус пока один - completed.
+-- Подарок приходит и покупателю, и получателю
+CREATE OR REPLACE FUNCTION purchases_notify_status() RETURNS trigger AS $$
+DECLARE
+   data JSONB := jsonb_build_object(
+      'item', NEW.product_name,
+      'buyer', NEW.buyer_username,
+      'owner', NEW.username,
+      'status', 'completed'
+   );
+BEGIN
+   PERFORM notify_user_event(NEW.buyer_username, 'purchase.status', data);
+   IF NEW.username <> NEW.buyer_username THEN
+      PERFORM notify_user_event(NEW.username, 'purchase.status', data);
+   END IF;
+   RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS purchases_status_changed ON purchases;
+CREATE TRIGGER purchases_status_changed
+   AFTER INSERT ON purchases
+   FOR EACH ROW EXECUTE FUNCTION purchases_notify_status();
@@ -0,0 +1,20 @@
+-- Общая лента благодарностей для /api/ws: переводы с сообщением. Сумма не публикуется,
+-- сторно в ленту не попадают
+CREATE OR REPLACE FUNCTION transfers_notify_kudos() RETURNS trigger AS $$
+BEGIN
+   PERFORM pg_notify('kudos_feed', json_build_object(
+      'transferId', NEW.id,
+      'fromUser', NEW.sender_username,
+      'toUser', NEW.receiver_username,
+      'message', NEW.message,
+      'createdAt', NEW.created_at
+   )::text);
+   RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS transfers_kudos_feed ON transfers;
+CREATE TRIGGER transfers_kudos_feed
+   AFTER INSERT ON transfers
+   FOR EACH ROW WHEN (NEW.reversal_of IS NULL AND COALESCE(NEW.message, '') <> '')
+   EXECUTE FUNCTION transfers_notify_kudos();
@@ -0,0 +1,134 @@
+-- Входящие уведомления пользователя. Пишутся триггерами в транзакции действия,
+-- поэтому не теряются, даже если пользователь не был подключён к потоку событий
+CREATE TABLE IF NOT EXISTS notifications (
+   id BIGSERIAL PRIMARY KEY,
+   username VARCHAR(100) NOT NULL REFERENCES users (username),
+   type VARCHAR(50) NOT NULL,
+   data JSONB NOT NULL,
+   created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+   read_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_username ON notifications(username, id DESC);
+CREATE INDEX IF NOT EXISTS idx_notifications_unread ON notifications(username, id DESC) WHERE read_at IS NULL;
+
+CREATE OR REPLACE FUNCTION add_notification(target VARCHAR, notification_type TEXT, data JSONB) RETURNS void AS $$
+BEGIN
+   INSERT INTO notifications (username, type, data) VALUES (target, notification_type, data);
+END;
+$$ LANGUAGE plpgsql;
+
+-- Перевод получен. Сторно приходит обеим сторонам исходного перевода
+CREATE OR REPLACE FUNCTION transfers_add_notification() RETURNS trigger AS $$
+BEGIN
+   IF NEW.reversal_of IS NULL THEN
+      PERFORM add_notification(NEW.receiver_username, 'transfer.received', jsonb_build_object(
+         'transferId', NEW.id,
+         'fromUser', NEW.sender_username,
+         'amount', NEW.amount,
+         'message', COALESCE(NEW.message, '')
+      ));
+   ELSE
+      PERFORM add_notification(NEW.sender_username, 'transfer.reversed', jsonb_build_object(
+         'transferId', NEW.reversal_of,
+         'amount', NEW.amount,
+         'direction', 'outgoing'
+      ));
+      PERFORM add_notification(NEW.receiver_username, 'transfer.reversed', jsonb_build_object(
+         'transferId', NEW.reversal_of,
+         'amount', NEW.amount,
+         'direction', 'incoming'
+      ));
+   END IF;
+   RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS transfers_notifications ON transfers;
+CREATE TRIGGER transfers_notifications
+   AFTER INSERT ON transfers
+   FOR EACH ROW EXECUTE FUNCTION transfers_add_notification();
+
+-- Перевод ждёт подтверждения получателя
+CREATE OR REPLACE FUNCTION pending_transfers_add_notification() RETURNS trigger AS $$
+BEGIN
+   PERFORM add_notification(NEW.receiver_username, 'transfer.pending', jsonb_build_object(
+      'pendingTransferId', NEW.id,
+      'fromUser', NEW.sender_username,
+      'amount', NEW.amount,
+      'message', COALESCE(NEW.message, ''),
+      'expiresAt', NEW.expires_at
+   ));
+   RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS pending_transfers_notifications ON pending_transfers;
+CREATE TRIGGER pending_transfers_notifications
+   AFTER INSERT ON pending_transfers
+   FOR EACH ROW EXECUTE FUNCTION pending_transfers_add_notification();
+
+-- Заказ проведён. Получатель подарка узнаёт о нём отдельным уведомлением
+CREATE OR REPLACE FUNCTION purchases_add_notification() RETURNS trigger AS $$
+BEGIN
+   PERFORM add_notification(NEW.buyer_username, 'order.status', jsonb_build_object(
+      'purchaseId', NEW.id,
+      'item', NEW.product_name,
+      'owner', NEW.username,
+      'status', 'completed'
+   ));
+   IF NEW.username <> NEW.buyer_username THEN
+      PERFORM add_notification(NEW.username, 'gift.received', jsonb_build_object(
+         'purchaseId', NEW.id,
+         'item', NEW.product_name,
+         'fromUser', NEW.buyer_username,
+         'message', COALESCE(NEW.gift_message, '')
+      ));
+   END IF;
+   RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS purchases_notifications ON purchases;
+CREATE TRIGGER purchases_notifications
+   AFTER INSERT ON purchases
+   FOR EACH ROW EXECUTE FUNCTION purchases_add_notification();
+
+-- Действия администраторов: начисление, заморозка и разморозка счёта
+CREATE OR REPLACE FUNCTION coin_grants_add_notification() RETURNS trigger AS $$
+BEGIN
+   PERFORM add_notification(NEW.username, 'coins.granted', jsonb_build_object(
+      'grantId', NEW.id,
+      'amount', NEW.amount,
+      'reason', NEW.reason,
+      'grantedBy', NEW.granted_by
+   ));
+   RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS coin_grants_notifications ON coin_grants;
+CREATE TRIGGER coin_grants_notifications
+   AFTER INSERT ON coin_grants
+   FOR EACH ROW EXECUTE FUNCTION coin_grants_add_notification();
+
+CREATE OR REPLACE FUNCTION account_freezes_add_notification() RETURNS trigger AS $$
+BEGIN
+   IF TG_OP = 'INSERT' THEN
+      PERFORM add_notification(NEW.username, 'account.frozen', jsonb_build_object(
+         'reason', NEW.reason,
+         'blockIncoming', NEW.block_incoming
+      ));
+   ELSIF OLD.unfrozen_at IS NULL AND NEW.unfrozen_at IS NOT NULL THEN
+      PERFORM add_notification(NEW.username, 'account.unfrozen', jsonb_build_object(
+         'reason', COALESCE(NEW.unfreeze_reason, '')
+      ));
+   END IF;
+   RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS account_freezes_notifications ON account_freezes;
+CREATE TRIGGER account_freezes_notifications
+   AFTER INSERT OR UPDATE ON account_freezes
+   FOR EACH ROW EXECUTE FUNCTION account_freezes_add_notification();
@@ -0,0 +1,14 @@
+-- Отказ от еженедельного письма-сводки. По умолчанию письмо получают все
+CREATE TABLE IF NOT EXISTS digest_optouts (
+   username VARCHAR(100) PRIMARY KEY REFERENCES users (username),
+   created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Сводка за неделю week_start уже отправлена или отправляется. Строка занимается до отправки,
+-- поэтому несколько реплик не отправят письмо дважды; при ошибке отправки строка удаляется
+CREATE TABLE IF NOT EXISTS digest_sends (
+   username VARCHAR(100) NOT NULL REFERENCES users (username),
+   week_start DATE NOT NULL,
+   created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+   PRIMARY KEY (username, week_start)
+);
@@ -0,0 +1,23 @@
+-- Цепочку хешей строит фоновый воркер, а не транзакция действия: запись добавляется без хеша,
+-- воркер связывает новые записи по порядку id. chain_seq - место записи в цепочке:
+-- NULL - ещё не связана, 0 - сделана до появления цепочки и не проверяется
+ALTER TABLE audit_events ADD COLUMN IF NOT EXISTS chain_seq BIGINT;
+
+-- Воркеру разрешено один раз дописать хеш и место в цепочке, остальные поля по-прежнему не меняются
+CREATE OR REPLACE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+BEGIN
+   IF TG_OP = 'UPDATE' AND OLD.chain_seq IS NULL AND NEW.chain_seq IS NOT NULL
+      AND (NEW.id, NEW.actor, NEW.action, NEW.target, NEW.payload, NEW.request_id, NEW.client_ip, NEW.created_at)
+         IS NOT DISTINCT FROM (OLD.id, OLD.actor, OLD.action, OLD.target, OLD.payload, OLD.request_id, OLD.client_ip, OLD.created_at)
+      AND (OLD.hash IS NULL OR (NEW.hash, NEW.prev_hash) IS NOT DISTINCT FROM (OLD.hash, OLD.prev_hash)) THEN
+      RETURN NEW;
+   END IF;
+   RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+-- До этой миграции записи связывались по порядку id
+UPDATE audit_events SET chain_seq = CASE WHEN hash IS NULL THEN 0 ELSE id END WHERE chain_seq IS NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_audit_events_chain_seq ON audit_events(chain_seq) WHERE chain_seq > 0;
+CREATE INDEX IF NOT EXISTS idx_audit_events_unchained ON audit_events(id) WHERE chain_seq IS NULL;
@@ -0,0 +1,2 @@
+-- Новая находка сверяется с прежними находками того же вида на того же пользователя по переводам
+CREATE INDEX IF NOT EXISTS idx_fraud_findings_subject ON fraud_findings(kind, username);
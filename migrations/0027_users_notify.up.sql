@@ -0,0 +1,14 @@
+-- Отзыв сессий (увольнение) уходит при коммите всем репликам через канал users,
+-- и они закрывают открытые потоки событий и WebSocket пользователя
+CREATE OR REPLACE FUNCTION users_notify_sessions_revoked() RETURNS trigger AS $$
+BEGIN
+   PERFORM pg_notify('users', NEW.username);
+   RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS users_sessions_revoked ON users;
+CREATE TRIGGER users_sessions_revoked
+   AFTER UPDATE OF sessions_revoked_at ON users
+   FOR EACH ROW WHEN (OLD.sessions_revoked_at IS DISTINCT FROM NEW.sessions_revoked_at)
+   EXECUTE FUNCTION users_notify_sessions_revoked();
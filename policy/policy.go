@@ -0,0 +1,79 @@
+package policy
+
+import (
+	"fmt"
+	"time"
+
+	"ttavito/domain/entities"
+)
+
+// Rules - ограничения на исходящие переводы. Нулевое значение отключает правило.
+type Rules struct {
+	MaxAmount           int           // максимум за один перевод
+	DailyLimit          int           // сумма исходящих за последние 24 часа
+	WeeklyLimit         int           // сумма исходящих за последние 7 дней
+	RecipientDailyLimit int           // сумма одному получателю за последние 24 часа
+	RecipientCooldown   time.Duration // пауза между переводами одному получателю
+}
+
+// Stats - исходящие переводы отправителя, посчитанные внутри транзакции перевода
+type Stats struct {
+	SentLastDay            int
+	SentLastWeek           int
+	SentToRecipientLastDay int
+	// nil, если отправитель ещё не переводил этому получателю
+	SinceLastToRecipient *time.Duration
+}
+
+func (r Rules) Enabled() bool {
+	return r.MaxAmount > 0 || r.DailyLimit > 0 || r.WeeklyLimit > 0 ||
+		r.RecipientDailyLimit > 0 || r.RecipientCooldown > 0
+}
+
+// Check возвращает *entities.PolicyViolation для первого нарушенного правила
+func (r Rules) Check(amount int, stats Stats) error {
+	if r.MaxAmount > 0 && amount > r.MaxAmount {
+		return &entities.PolicyViolation{
+			Code:    entities.PolicyAmountExceedsMax,
+			Message: fmt.Sprintf("transfer amount exceeds the maximum of %d", r.MaxAmount),
+		}
+	}
+
+	if r.DailyLimit > 0 && stats.SentLastDay+amount > r.DailyLimit {
+		return &entities.PolicyViolation{
+			Code:    entities.PolicyDailyLimitExceeded,
+			Message: fmt.Sprintf("daily outgoing limit of %d exceeded, %d left", r.DailyLimit, remaining(r.DailyLimit, stats.SentLastDay)),
+		}
+	}
+
+	if r.WeeklyLimit > 0 && stats.SentLastWeek+amount > r.WeeklyLimit {
+		return &entities.PolicyViolation{
+			Code:    entities.PolicyWeeklyLimitExceeded,
+			Message: fmt.Sprintf("weekly outgoing limit of %d exceeded, %d left", r.WeeklyLimit, remaining(r.WeeklyLimit, stats.SentLastWeek)),
+		}
+	}
+
+	if r.RecipientDailyLimit > 0 && stats.SentToRecipientLastDay+amount > r.RecipientDailyLimit {
+		return &entities.PolicyViolation{
+			Code:    entities.PolicyRecipientLimitExceeded,
+			Message: fmt.Sprintf("daily limit of %d per recipient exceeded, %d left", r.RecipientDailyLimit, remaining(r.RecipientDailyLimit, stats.SentToRecipientLastDay)),
+		}
+	}
+
+	if r.RecipientCooldown > 0 && stats.SinceLastToRecipient != nil && *stats.SinceLastToRecipient < r.RecipientCooldown {
+		wait := (r.RecipientCooldown - *stats.SinceLastToRecipient).Round(time.Second)
+		return &entities.PolicyViolation{
+			Code:    entities.PolicyRecipientCooldown,
+			Message: fmt.Sprintf("next transfer to this recipient is allowed in %s", wait),
+		}
+	}
+
+	return nil
+}
+
+func remaining(limit, used int) int {
+	if used >= limit {
+		return 0
+	}
+	return limit - used
+}
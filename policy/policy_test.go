@@ -0,0 +1,63 @@
+package policy
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"ttavito/domain/entities"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRulesEnabled(t *testing.T) {
+	assert.False(t, Rules{}.Enabled())
+	assert.True(t, Rules{WeeklyLimit: 100}.Enabled())
+	assert.True(t, Rules{RecipientCooldown: time.Minute}.Enabled())
+}
+
+func TestRulesCheck(t *testing.T) {
+	rules := Rules{
+		MaxAmount:           200,
+		DailyLimit:          300,
+		WeeklyLimit:         500,
+		RecipientDailyLimit: 100,
+		RecipientCooldown:   time.Hour,
+	}
+	tenMinutes := 10 * time.Minute
+	twoHours := 2 * time.Hour
+
+	tests := []struct {
+		name   string
+		amount int
+		stats  Stats
+		code   string
+	}{
+		{"allowed", 50, Stats{SentLastDay: 100, SentLastWeek: 200}, ""},
+		{"amount exceeds max", 201, Stats{}, entities.PolicyAmountExceedsMax},
+		{"daily limit", 100, Stats{SentLastDay: 250, SentLastWeek: 250}, entities.PolicyDailyLimitExceeded},
+		{"weekly limit", 100, Stats{SentLastDay: 0, SentLastWeek: 450}, entities.PolicyWeeklyLimitExceeded},
+		{"recipient limit", 60, Stats{SentToRecipientLastDay: 50}, entities.PolicyRecipientLimitExceeded},
+		{"cooldown", 10, Stats{SinceLastToRecipient: &tenMinutes}, entities.PolicyRecipientCooldown},
+		{"cooldown passed", 10, Stats{SinceLastToRecipient: &twoHours}, ""},
+		{"exactly at limit", 100, Stats{SentLastDay: 200, SentLastWeek: 400}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := rules.Check(tt.amount, tt.stats)
+			if tt.code == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			var violation *entities.PolicyViolation
+			assert.True(t, errors.As(err, &violation))
+			assert.Equal(t, tt.code, violation.Code)
+		})
+	}
+}
+
+func TestDisabledRulesAllowEverything(t *testing.T) {
+	assert.NoError(t, Rules{}.Check(1_000_000, Stats{SentLastDay: 1_000_000}))
+}
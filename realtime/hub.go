@@ -0,0 +1,233 @@
+package realtime
+
+import (
+	"encoding/json"
+	"log/slog"
+	"sync"
+
+	"ttavito/domain/entities"
+)
+
+// Каналы Postgres, в которые триггеры пишут события пользователей, общей ленты
+// и логины пользователей, чьи сессии отозваны
+const (
+	Channel      = "user_events"
+	FeedChannel  = "kudos_feed"
+	UsersChannel = "users"
+)
+
+const (
+	// Сколько потоков одновременно может открыть один пользователь
+	MaxStreamsPerUser = 5
+	// Сколько событий ждут отправки клиенту. Клиент, который не успевает их забирать,
+	// отключается и переподключается заново
+	streamBuffer = 32
+)
+
+// Hub раздаёт события пользователей открытым потокам этой реплики
+type Hub struct {
+	mu   sync.Mutex
+	subs map[string]map[chan entities.UserEvent]struct{}
+	feed map[chan entities.UserEvent]struct{}
+	// Закрываются, когда сессии пользователя отозваны
+	revoked map[string]map[chan struct{}]struct{}
+	closed  bool
+	done    chan struct{}
+}
+
+func NewHub() *Hub {
+	return &Hub{
+		subs:    make(map[string]map[chan entities.UserEvent]struct{}),
+		feed:    make(map[chan entities.UserEvent]struct{}),
+		revoked: make(map[string]map[chan struct{}]struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// Subscribe открывает поток событий username. Канал закрывается, если клиент не успевает
+// читать события или hub закрыт. cancel нужно вызвать, когда поток больше не нужен
+func (h *Hub) Subscribe(username string) (<-chan entities.UserEvent, func(), error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ch := make(chan entities.UserEvent, streamBuffer)
+	if h.closed {
+		close(ch)
+		return ch, func() {}, nil
+	}
+	if len(h.subs[username]) >= MaxStreamsPerUser {
+		return nil, nil, entities.ErrTooManyEventStreams
+	}
+	if h.subs[username] == nil {
+		h.subs[username] = make(map[chan entities.UserEvent]struct{})
+	}
+	h.subs[username][ch] = struct{}{}
+
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		h.remove(username, ch)
+	}, nil
+}
+
+// remove закрывает канал, если он ещё подписан. Вызывается под mu
+func (h *Hub) remove(username string, ch chan entities.UserEvent) {
+	if _, ok := h.subs[username][ch]; !ok {
+		return
+	}
+	delete(h.subs[username], ch)
+	if len(h.subs[username]) == 0 {
+		delete(h.subs, username)
+	}
+	close(ch)
+}
+
+// SubscribeFeed открывает общую ленту благодарностей. Ограничения на число подписок нет:
+// лента открывается только внутри WebSocket-сессии, одна на сессию
+func (h *Hub) SubscribeFeed() (<-chan entities.UserEvent, func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ch := make(chan entities.UserEvent, streamBuffer)
+	if h.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	h.feed[ch] = struct{}{}
+
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		h.removeFeed(ch)
+	}
+}
+
+// removeFeed - то же, что remove, для ленты. Вызывается под mu
+func (h *Hub) removeFeed(ch chan entities.UserEvent) {
+	if _, ok := h.feed[ch]; !ok {
+		return
+	}
+	delete(h.feed, ch)
+	close(ch)
+}
+
+// Publish отправляет событие всем открытым потокам пользователя
+func (h *Hub) Publish(e entities.UserEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch := range h.subs[e.Username] {
+		select {
+		case ch <- e:
+		default:
+			slog.Warn("Event stream is too slow, disconnecting", "username", e.Username)
+			h.remove(e.Username, ch)
+		}
+	}
+}
+
+// Dispatch разбирает уведомление из канала Channel
+func (h *Hub) Dispatch(payload string) {
+	var e entities.UserEvent
+	if err := json.Unmarshal([]byte(payload), &e); err != nil || e.Username == "" || e.Type == "" {
+		slog.Error("Invalid user event notification", "payload", payload, "error", err)
+		return
+	}
+	h.Publish(e)
+}
+
+// PublishFeed отправляет событие всем подписчикам ленты
+func (h *Hub) PublishFeed(e entities.UserEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch := range h.feed {
+		select {
+		case ch <- e:
+		default:
+			slog.Warn("Feed subscriber is too slow, disconnecting")
+			h.removeFeed(ch)
+		}
+	}
+}
+
+// DispatchFeed разбирает уведомление из канала FeedChannel: содержимое события ленты
+func (h *Hub) DispatchFeed(payload string) {
+	if !json.Valid([]byte(payload)) {
+		slog.Error("Invalid feed notification", "payload", payload)
+		return
+	}
+	h.PublishFeed(entities.UserEvent{Type: entities.FeedEventKudos, Data: json.RawMessage(payload)})
+}
+
+// watch возвращает канал, который закроется при отзыве сессий username.
+// cancel нужно вызвать, когда сессия завершена
+func (h *Hub) watch(username string) (<-chan struct{}, func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ch := make(chan struct{})
+	if h.revoked[username] == nil {
+		h.revoked[username] = make(map[chan struct{}]struct{})
+	}
+	h.revoked[username][ch] = struct{}{}
+
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if _, ok := h.revoked[username][ch]; ok {
+			delete(h.revoked[username], ch)
+			if len(h.revoked[username]) == 0 {
+				delete(h.revoked, username)
+			}
+		}
+	}
+}
+
+// Disconnect закрывает все потоки и WebSocket-сессии пользователя, например после увольнения
+func (h *Hub) Disconnect(username string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch := range h.revoked[username] {
+		close(ch)
+	}
+	delete(h.revoked, username)
+	for ch := range h.subs[username] {
+		h.remove(username, ch)
+	}
+}
+
+// DispatchUsers разбирает уведомление из канала UsersChannel: логин пользователя
+func (h *Hub) DispatchUsers(payload string) {
+	if payload == "" {
+		slog.Error("Invalid users notification", "payload", payload)
+		return
+	}
+	h.Disconnect(payload)
+}
+
+// Close закрывает все потоки, чтобы сервер мог завершиться, не дожидаясь клиентов
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return
+	}
+	h.closed = true
+	close(h.done)
+	for username, subs := range h.subs {
+		for ch := range subs {
+			h.remove(username, ch)
+		}
+	}
+	for ch := range h.feed {
+		h.removeFeed(ch)
+	}
+}
+
+// Done закрывается вместе с hub
+func (h *Hub) Done() <-chan struct{} {
+	return h.done
+}
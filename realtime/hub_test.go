@@ -0,0 +1,100 @@
+package realtime
+
+import (
+	"testing"
+
+	"ttavito/domain/entities"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHubDispatch(t *testing.T) {
+	h := NewHub()
+	alice, cancel, err := h.Subscribe("alice")
+	assert.NoError(t, err)
+	defer cancel()
+	bob, cancelBob, err := h.Subscribe("bob")
+	assert.NoError(t, err)
+	defer cancelBob()
+
+	h.Dispatch(`{"username":"alice","type":"balance.updated","data":{"balance":900}}`)
+	h.Dispatch(`not json`)
+
+	select {
+	case e := <-alice:
+		assert.Equal(t, entities.UserEventBalanceUpdated, e.Type)
+		assert.JSONEq(t, `{"balance":900}`, string(e.Data))
+	default:
+		t.Fatal("alice did not receive the event")
+	}
+	assert.Len(t, bob, 0)
+}
+
+func TestHubStreamLimit(t *testing.T) {
+	h := NewHub()
+	cancels := make([]func(), 0, MaxStreamsPerUser)
+	for i := 0; i < MaxStreamsPerUser; i++ {
+		_, cancel, err := h.Subscribe("alice")
+		assert.NoError(t, err)
+		cancels = append(cancels, cancel)
+	}
+	_, _, err := h.Subscribe("alice")
+	assert.ErrorIs(t, err, entities.ErrTooManyEventStreams)
+
+	cancels[0]()
+	cancels[0]()
+	_, _, err = h.Subscribe("alice")
+	assert.NoError(t, err)
+}
+
+func TestHubDisconnectsSlowStream(t *testing.T) {
+	h := NewHub()
+	ch, cancel, err := h.Subscribe("alice")
+	assert.NoError(t, err)
+	defer cancel()
+
+	for i := 0; i <= streamBuffer; i++ {
+		h.Publish(entities.UserEvent{Username: "alice", Type: entities.UserEventBalanceUpdated})
+	}
+
+	n := 0
+	for range ch {
+		n++
+	}
+	assert.Equal(t, streamBuffer, n)
+}
+
+func TestHubDispatchUsers(t *testing.T) {
+	h := NewHub()
+	alice, _, err := h.Subscribe("alice")
+	assert.NoError(t, err)
+	bob, _, err := h.Subscribe("bob")
+	assert.NoError(t, err)
+
+	h.DispatchUsers("alice")
+	_, ok := <-alice
+	assert.False(t, ok)
+
+	h.Dispatch(`{"username":"bob","type":"balance.updated","data":{"balance":5}}`)
+	e := <-bob
+	assert.Equal(t, "balance.updated", e.Type)
+
+	// Отзыв не мешает новым потокам: их не пустит проверка токена
+	_, _, err = h.Subscribe("alice")
+	assert.NoError(t, err)
+}
+
+func TestHubClose(t *testing.T) {
+	h := NewHub()
+	ch, _, err := h.Subscribe("alice")
+	assert.NoError(t, err)
+
+	h.Close()
+	_, ok := <-ch
+	assert.False(t, ok)
+
+	ch, _, err = h.Subscribe("alice")
+	assert.NoError(t, err)
+	_, ok = <-ch
+	assert.False(t, ok)
+}
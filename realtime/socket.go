@@ -0,0 +1,220 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"ttavito/domain/entities"
+
+	"github.com/coder/websocket"
+)
+
+const (
+	// Сервер шлёт ping раз в socketPingInterval и отключает клиента,
+	// который не ответил на него за socketPongTimeout
+	socketPingInterval = 30 * time.Second
+	socketPongTimeout  = socketPingInterval
+	// Клиент, который столько не забирает отправленное, отключается
+	socketWriteTimeout = 10 * time.Second
+	// Клиент шлёт только подписки, большие сообщения не нужны
+	socketMaxMessage = 4 << 10
+)
+
+type socketSubscription struct {
+	events <-chan entities.UserEvent
+	cancel func()
+}
+
+type socketSession struct {
+	hub      *Hub
+	conn     *websocket.Conn
+	username string
+	subs     map[string]socketSubscription
+	// Закрывается, когда сессии пользователя отозваны
+	revoked <-chan struct{}
+	// Контекст чтения и записи. Отменяется, только когда сессия завершена:
+	// при отмене библиотека закрывает соединение без фрейма закрытия
+	ctx     context.Context
+	readErr chan error
+}
+
+// ServeSocket ведёт WebSocket-сессию пользователя: подписки на ленты, ping и закрытие.
+// Возвращается, когда соединение закрыто: клиентом, при остановке hub или ctx,
+// при отзыве сессий пользователя, по истечении токена (expiresAt, нулевое - без ограничения) или если клиент не успевает читать
+func (h *Hub) ServeSocket(ctx context.Context, conn *websocket.Conn, username string, expiresAt time.Time) {
+	connCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	revoked, unwatch := h.watch(username)
+	defer unwatch()
+
+	s := &socketSession{
+		hub:      h,
+		conn:     conn,
+		username: username,
+		subs:     make(map[string]socketSubscription),
+		revoked:  revoked,
+		ctx:      connCtx,
+		readErr:  make(chan error, 2),
+	}
+	defer conn.CloseNow()
+	defer s.unsubscribeAll()
+
+	conn.SetReadLimit(socketMaxMessage)
+
+	done := make(chan struct{})
+	defer close(done)
+	requests := make(chan entities.SocketRequest)
+	go func() {
+		for {
+			typ, data, err := conn.Read(connCtx)
+			if err != nil {
+				s.readErr <- err
+				return
+			}
+			var req entities.SocketRequest
+			if typ != websocket.MessageText || json.Unmarshal(data, &req) != nil {
+				req = entities.SocketRequest{}
+			}
+			select {
+			case requests <- req:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	// Pong читает горутина выше, поэтому ping ждёт ответа отдельно от отправки событий
+	go func() {
+		ticker := time.NewTicker(socketPingInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+			pingCtx, cancel := context.WithTimeout(connCtx, socketPongTimeout)
+			err := conn.Ping(pingCtx)
+			cancel()
+			if err != nil {
+				s.readErr <- err
+				return
+			}
+		}
+	}()
+
+	var expired <-chan time.Time
+	if !expiresAt.IsZero() {
+		timer := time.NewTimer(time.Until(expiresAt))
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	for {
+		var err error
+		select {
+		case <-ctx.Done():
+			s.close(websocket.StatusGoingAway, "server is shutting down")
+			return
+		case <-h.Done():
+			s.close(websocket.StatusGoingAway, "server is shutting down")
+			return
+		case <-expired:
+			s.close(websocket.StatusPolicyViolation, "token expired")
+			return
+		case <-revoked:
+			s.close(websocket.StatusPolicyViolation, "session revoked")
+			return
+		case err := <-s.readErr:
+			if websocket.CloseStatus(err) == -1 {
+				slog.Debug("WebSocket connection lost", "username", username, "error", err)
+			}
+			return
+		case req := <-requests:
+			err = s.handle(req)
+		case e, ok := <-s.subs[entities.SocketTopicWallet].events:
+			err = s.forward(entities.SocketTopicWallet, e, ok)
+		case e, ok := <-s.subs[entities.SocketTopicFeed].events:
+			err = s.forward(entities.SocketTopicFeed, e, ok)
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// forward отправляет событие ленты. Закрытый канал значит, что клиент отстал,
+// сессии отозваны или hub остановлен
+func (s *socketSession) forward(topic string, e entities.UserEvent, ok bool) error {
+	if !ok {
+		select {
+		case <-s.hub.Done():
+			s.close(websocket.StatusGoingAway, "server is shutting down")
+		case <-s.revoked:
+			s.close(websocket.StatusPolicyViolation, "session revoked")
+		default:
+			s.close(websocket.StatusTryAgainLater, "client is too slow")
+		}
+		return errors.New("subscription closed")
+	}
+	return s.send(entities.SocketMessage{Type: entities.SocketEvent, Topic: topic, Event: e.Type, Data: e.Data})
+}
+
+func (s *socketSession) handle(req entities.SocketRequest) error {
+	if req.Type != entities.SocketSubscribe && req.Type != entities.SocketUnsubscribe {
+		return s.send(entities.SocketMessage{Type: entities.SocketError, Error: "unknown message type"})
+	}
+	if req.Topic != entities.SocketTopicWallet && req.Topic != entities.SocketTopicFeed {
+		return s.send(entities.SocketMessage{Type: entities.SocketError, Topic: req.Topic, Error: "unknown topic"})
+	}
+
+	switch req.Type {
+	case entities.SocketSubscribe:
+		if _, ok := s.subs[req.Topic]; !ok {
+			var sub socketSubscription
+			if req.Topic == entities.SocketTopicWallet {
+				events, cancel, err := s.hub.Subscribe(s.username)
+				if err != nil {
+					return s.send(entities.SocketMessage{Type: entities.SocketError, Topic: req.Topic, Error: err.Error()})
+				}
+				sub = socketSubscription{events: events, cancel: cancel}
+			} else {
+				events, cancel := s.hub.SubscribeFeed()
+				sub = socketSubscription{events: events, cancel: cancel}
+			}
+			s.subs[req.Topic] = sub
+		}
+		return s.send(entities.SocketMessage{Type: entities.SocketSubscribed, Topic: req.Topic})
+	default:
+		if sub, ok := s.subs[req.Topic]; ok {
+			delete(s.subs, req.Topic)
+			sub.cancel()
+		}
+		return s.send(entities.SocketMessage{Type: entities.SocketUnsubscribed, Topic: req.Topic})
+	}
+}
+
+// close выполняет закрытие по протоколу, чтобы клиент успел получить причину
+func (s *socketSession) close(code websocket.StatusCode, reason string) {
+	s.conn.Close(code, reason)
+}
+
+func (s *socketSession) send(msg entities.SocketMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(s.ctx, socketWriteTimeout)
+	defer cancel()
+	return s.conn.Write(ctx, websocket.MessageText, data)
+}
+
+func (s *socketSession) unsubscribeAll() {
+	for topic, sub := range s.subs {
+		delete(s.subs, topic)
+		sub.cancel()
+	}
+}
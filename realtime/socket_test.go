@@ -0,0 +1,189 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"ttavito/domain/entities"
+
+	"github.com/coder/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+func serveSocket(h *Hub, expiresAt time.Time) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+		h.ServeSocket(context.Background(), conn, "alice", expiresAt)
+	}))
+}
+
+func dialSocket(t *testing.T, server *httptest.Server) *websocket.Conn {
+	conn, _, err := websocket.Dial(context.Background(), server.URL, nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	return conn
+}
+
+// readMessage читает сообщение с таймаутом, чтобы зависший сервер не вешал тест
+func readMessage(conn *websocket.Conn) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, data, err := conn.Read(ctx)
+	return data, err
+}
+
+func request(t *testing.T, conn *websocket.Conn, typ, topic string) entities.SocketMessage {
+	data, _ := json.Marshal(entities.SocketRequest{Type: typ, Topic: topic})
+	if err := conn.Write(context.Background(), websocket.MessageText, data); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	return readSocket(t, conn)
+}
+
+func readSocket(t *testing.T, conn *websocket.Conn) entities.SocketMessage {
+	data, err := readMessage(conn)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	var msg entities.SocketMessage
+	assert.NoError(t, json.Unmarshal(data, &msg))
+	return msg
+}
+
+func TestSocketSubscriptions(t *testing.T) {
+	h := NewHub()
+	server := serveSocket(h, time.Time{})
+	defer server.Close()
+	conn := dialSocket(t, server)
+	defer conn.CloseNow()
+
+	assert.Equal(t, entities.SocketSubscribed, request(t, conn, entities.SocketSubscribe, entities.SocketTopicWallet).Type)
+	assert.Equal(t, entities.SocketSubscribed, request(t, conn, entities.SocketSubscribe, entities.SocketTopicFeed).Type)
+
+	h.Dispatch(`{"username":"alice","type":"balance.updated","data":{"balance":900}}`)
+	msg := readSocket(t, conn)
+	assert.Equal(t, entities.SocketEvent, msg.Type)
+	assert.Equal(t, entities.SocketTopicWallet, msg.Topic)
+	assert.Equal(t, entities.UserEventBalanceUpdated, msg.Event)
+	assert.JSONEq(t, `{"balance":900}`, string(msg.Data))
+
+	h.DispatchFeed(`{"fromUser":"bob","toUser":"carol","message":"thanks"}`)
+	msg = readSocket(t, conn)
+	assert.Equal(t, entities.SocketTopicFeed, msg.Topic)
+	assert.Equal(t, entities.FeedEventKudos, msg.Event)
+
+	assert.Equal(t, entities.SocketUnsubscribed, request(t, conn, entities.SocketUnsubscribe, entities.SocketTopicWallet).Type)
+	h.Dispatch(`{"username":"alice","type":"balance.updated","data":{"balance":800}}`)
+	h.DispatchFeed(`{"fromUser":"carol","toUser":"bob","message":"you too"}`)
+	msg = readSocket(t, conn)
+	assert.Equal(t, entities.SocketTopicFeed, msg.Topic)
+
+	msg = request(t, conn, entities.SocketSubscribe, "secrets")
+	assert.Equal(t, entities.SocketError, msg.Type)
+	msg = request(t, conn, "hello", entities.SocketTopicFeed)
+	assert.Equal(t, entities.SocketError, msg.Type)
+}
+
+func TestSocketStreamLimit(t *testing.T) {
+	h := NewHub()
+	for i := 0; i < MaxStreamsPerUser; i++ {
+		_, cancel, err := h.Subscribe("alice")
+		assert.NoError(t, err)
+		defer cancel()
+	}
+	server := serveSocket(h, time.Time{})
+	defer server.Close()
+	conn := dialSocket(t, server)
+	defer conn.CloseNow()
+
+	msg := request(t, conn, entities.SocketSubscribe, entities.SocketTopicWallet)
+	assert.Equal(t, entities.SocketError, msg.Type)
+	assert.Equal(t, entities.ErrTooManyEventStreams.Error(), msg.Error)
+}
+
+func TestSocketTokenExpiry(t *testing.T) {
+	h := NewHub()
+	server := serveSocket(h, time.Now().Add(100*time.Millisecond))
+	defer server.Close()
+	conn := dialSocket(t, server)
+	defer conn.CloseNow()
+
+	_, err := readMessage(conn)
+	var closeErr websocket.CloseError
+	if assert.ErrorAs(t, err, &closeErr) {
+		assert.Equal(t, websocket.StatusPolicyViolation, closeErr.Code)
+		assert.Equal(t, "token expired", closeErr.Reason)
+	}
+}
+
+func TestSocketHubClose(t *testing.T) {
+	h := NewHub()
+	server := serveSocket(h, time.Time{})
+	defer server.Close()
+	conn := dialSocket(t, server)
+	defer conn.CloseNow()
+
+	request(t, conn, entities.SocketSubscribe, entities.SocketTopicFeed)
+	h.Close()
+
+	_, err := readMessage(conn)
+	var closeErr websocket.CloseError
+	if assert.ErrorAs(t, err, &closeErr) {
+		assert.Equal(t, websocket.StatusGoingAway, closeErr.Code)
+	}
+}
+
+func TestSocketSessionRevoked(t *testing.T) {
+	h := NewHub()
+	server := serveSocket(h, time.Time{})
+	defer server.Close()
+	conn := dialSocket(t, server)
+	defer conn.CloseNow()
+
+	request(t, conn, entities.SocketSubscribe, entities.SocketTopicFeed)
+	h.DispatchUsers("alice")
+
+	_, err := readMessage(conn)
+	var closeErr websocket.CloseError
+	if assert.ErrorAs(t, err, &closeErr) {
+		assert.Equal(t, websocket.StatusPolicyViolation, closeErr.Code)
+		assert.Equal(t, "session revoked", closeErr.Reason)
+	}
+}
+
+func TestSocketSlowClient(t *testing.T) {
+	h := NewHub()
+	server := serveSocket(h, time.Time{})
+	defer server.Close()
+	conn := dialSocket(t, server)
+	defer conn.CloseNow()
+
+	request(t, conn, entities.SocketSubscribe, entities.SocketTopicFeed)
+
+	// Событий больше, чем помещается в очередь, а клиент их не читает
+	payload := `{"message":"` + strings.Repeat("x", 1<<10) + `"}`
+	for i := 0; i < 50*streamBuffer; i++ {
+		h.DispatchFeed(payload)
+	}
+
+	for {
+		_, err := readMessage(conn)
+		if err == nil {
+			continue
+		}
+		var closeErr websocket.CloseError
+		if assert.ErrorAs(t, err, &closeErr) {
+			assert.Equal(t, websocket.StatusTryAgainLater, closeErr.Code)
+		}
+		return
+	}
+}
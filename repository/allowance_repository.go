@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"ttavito/domain/entities"
+)
+
+// Вставка и пополнение баланса выполняются одним запросом: пользователи,
+// уже получившие пособие за период, отсекаются ON CONFLICT и баланс им не меняется
+const issueAllowanceQuery = `
+WITH issued AS (
+	INSERT INTO allowance_issuances (period, username, amount)
+	SELECT $1, username, $2::int FROM users WHERE deactivated_at IS NULL
+	ON CONFLICT (period, username) DO NOTHING
+	RETURNING username
+), lots AS (
+	INSERT INTO coin_lots (username, amount, remaining, source, expires_at)
+	SELECT username, $2::int, $2::int, $3, $4::timestamptz FROM issued
+)
+UPDATE users SET balance = balance + $2::int
+FROM issued
+WHERE users.username = issued.username
+RETURNING users.username`
+
+// IssueAllowance начисляет amount всем пользователям, ещё не получившим пособие за period,
+// и возвращает число начислений. Каждое начисление попадает в журнал действий
+func (r *EntityRepo) IssueAllowance(ctx context.Context, period string, amount int) (count int, err error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to start transaction: %v", err)
+	}
+
+	defer func() {
+		if err != nil {
+			slog.Error("Failed to issue allowance", "period", period, "error", err)
+			tx.Rollback(ctx)
+		} else {
+			err = tx.Commit(ctx)
+			if err == nil {
+				slog.Info("success issue allowance", "period", period, "amount", amount, "count", count)
+			}
+		}
+	}()
+
+	rows, err := tx.Query(ctx, issueAllowanceQuery, period, amount, lotSourceAllowance, r.lotExpiresAt(time.Now()))
+	if err != nil {
+		return 0, fmt.Errorf("failed to issue allowance: %v", err)
+	}
+	var usernames []string
+	for rows.Next() {
+		var username string
+		if err = rows.Scan(&username); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan username: %v", err)
+		}
+		usernames = append(usernames, username)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to issue allowance: %v", err)
+	}
+
+	for _, username := range usernames {
+		err = r.writeAudit(ctx, tx, entities.AuditActorSystem, entities.AuditCoinsAllowance, username,
+			auditPayload{"period": period, "amount": amount})
+		if err != nil {
+			return 0, err
+		}
+	}
+	return len(usernames), nil
+}
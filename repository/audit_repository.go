@@ -0,0 +1,277 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"ttavito/audit"
+	"ttavito/domain/entities"
+	"ttavito/domain/interfaces"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+)
+
+type auditPayload map[string]any
+
+// auditChainLock - ключ advisory-блокировки воркера, который связывает записи в цепочку
+const auditChainLock = 0x61756469
+
+// writeAudit добавляет событие в журнал. Вызывается в транзакции самого действия,
+// поэтому откат действия откатывает и запись о нём. Хеш записи считает позже ChainAuditEvents,
+// так что действия не ждут друг друга из-за журнала
+func (r *EntityRepo) writeAudit(ctx context.Context, db interfaces.DB, actor, action, target string, payload auditPayload) error {
+	if payload == nil {
+		payload = auditPayload{}
+	}
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit payload: %v", err)
+	}
+	meta := audit.MetaFrom(ctx)
+
+	q, args, _ := r.builder.Insert("audit_events").
+		Columns("actor", "action", "target", "payload", "request_id", "client_ip", "created_at").
+		Values(actor, action, target, sq.Expr("?::jsonb", string(raw)), meta.RequestID, meta.ClientIP, time.Now().UTC()).
+		ToSql()
+	_, err = db.Exec(ctx, q, args...)
+	if err != nil {
+		return fmt.Errorf("failed to write audit event %s: %v", action, err)
+	}
+	return nil
+}
+
+// ChainAuditEvents связывает в цепочку до limit ещё не связанных записей по порядку id
+// и возвращает, сколько связано. Хеш считается от записи, прочитанной из базы.
+// Если цепочку сейчас достраивает другая реплика, возвращает 0
+func (r *EntityRepo) ChainAuditEvents(ctx context.Context, limit int) (count int, err error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to start transaction: %v", err)
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		} else {
+			err = tx.Commit(ctx)
+		}
+	}()
+
+	var locked bool
+	err = tx.QueryRow(ctx, "SELECT pg_try_advisory_xact_lock($1)", auditChainLock).Scan(&locked)
+	if err != nil {
+		return 0, fmt.Errorf("failed to lock audit chain: %v", err)
+	}
+	if !locked {
+		return 0, nil
+	}
+
+	var prevHash string
+	var seq int64
+	head, err := r.auditHead(ctx, tx)
+	if err != nil {
+		return 0, err
+	}
+	if head != nil {
+		prevHash, seq = head.Hash, head.Seq
+	}
+
+	q, args, _ := r.builder.Select(auditEventColumns...).
+		From("audit_events").
+		Where(sq.Eq{"chain_seq": nil}).
+		OrderBy("id").
+		Limit(uint64(limit)).
+		ToSql()
+
+	rows, err := tx.Query(ctx, q, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch unchained audit events: %v", err)
+	}
+	var pending []entities.AuditEvent
+	for rows.Next() {
+		e, err := scanAuditEvent(rows)
+		if err != nil {
+			rows.Close()
+			return 0, err
+		}
+		pending = append(pending, *e)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to fetch unchained audit events: %v", err)
+	}
+
+	for _, e := range pending {
+		e.PrevHash = prevHash
+		e.Hash, err = audit.Hash(e)
+		if err != nil {
+			return 0, fmt.Errorf("audit event %d: %w", e.ID, err)
+		}
+		seq++
+
+		q, args, _ = r.builder.Update("audit_events").
+			Set("prev_hash", e.PrevHash).
+			Set("hash", e.Hash).
+			Set("chain_seq", seq).
+			Where(sq.Eq{"id": e.ID}).
+			ToSql()
+		_, err = tx.Exec(ctx, q, args...)
+		if err != nil {
+			return 0, fmt.Errorf("failed to chain audit event %d: %v", e.ID, err)
+		}
+		prevHash = e.Hash
+	}
+	return len(pending), nil
+}
+
+// writeAuditAlone пишет событие, которое не сопровождает изменение данных, в отдельной транзакции
+func (r *EntityRepo) writeAuditAlone(ctx context.Context, actor, action, target string, payload auditPayload) (err error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %v", err)
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		} else {
+			err = tx.Commit(ctx)
+		}
+	}()
+
+	return r.writeAudit(ctx, tx, actor, action, target, payload)
+}
+
+func (r *EntityRepo) auditHead(ctx context.Context, db interfaces.DB) (*entities.AuditHead, error) {
+	q, args, _ := r.builder.Select("id", "chain_seq", "hash").
+		From("audit_events").
+		Where(sq.Gt{"chain_seq": 0}).
+		OrderBy("chain_seq DESC").
+		Limit(1).
+		ToSql()
+
+	var head entities.AuditHead
+	err := db.QueryRow(ctx, q, args...).Scan(&head.ID, &head.Seq, &head.Hash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to fetch audit chain head: %v", err)
+	}
+	return &head, nil
+}
+
+// GetAuditHead возвращает последнюю запись цепочки или nil, если цепочка пуста
+func (r *EntityRepo) GetAuditHead(ctx context.Context) (*entities.AuditHead, error) {
+	return r.auditHead(ctx, r.db)
+}
+
+// ScanAuditEvents передаёт fn записи журнала в порядке цепочки, не загружая их в память:
+// сначала записи, сделанные до цепочки, затем связанные. Ещё не связанные записи пропускаются
+func (r *EntityRepo) ScanAuditEvents(ctx context.Context, fn func(entities.AuditEvent) error) error {
+	q, args, _ := r.builder.Select(auditEventColumns...).
+		From("audit_events").
+		Where(sq.NotEq{"chain_seq": nil}).
+		OrderBy("chain_seq", "id").
+		ToSql()
+
+	rows, err := r.db.Query(ctx, q, args...)
+	if err != nil {
+		return fmt.Errorf("failed to fetch audit events: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		e, err := scanAuditEvent(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(*e); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to fetch audit events: %v", err)
+	}
+	return nil
+}
+
+var auditEventColumns = []string{
+	"id", "actor", "action", "target", "payload::text", "request_id", "client_ip", "created_at",
+	"COALESCE(prev_hash, '')", "COALESCE(hash, '')",
+}
+
+func scanAuditEvent(row pgx.Row) (*entities.AuditEvent, error) {
+	var e entities.AuditEvent
+	var payload string
+	err := row.Scan(&e.ID, &e.Actor, &e.Action, &e.Target, &payload, &e.RequestID, &e.ClientIP, &e.CreatedAt,
+		&e.PrevHash, &e.Hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan audit event: %v", err)
+	}
+	e.Payload = json.RawMessage(payload)
+	return &e, nil
+}
+
+func (r *EntityRepo) GetAuditEvents(ctx context.Context, req entities.AuditQuery) (*entities.AuditEventsResponse, error) {
+	query := r.builder.Select(auditEventColumns...).
+		From("audit_events").
+		OrderBy("id DESC").
+		// Лишняя строка показывает, есть ли следующая страница
+		Limit(uint64(req.Limit) + 1)
+
+	if req.Actor != "" {
+		query = query.Where(sq.Eq{"actor": req.Actor})
+	}
+	if prefix, ok := strings.CutSuffix(req.Action, "*"); ok {
+		query = query.Where(sq.Like{"action": likeEscaper.Replace(prefix) + "%"})
+	} else if req.Action != "" {
+		query = query.Where(sq.Eq{"action": req.Action})
+	}
+	if req.Target != "" {
+		query = query.Where(sq.Eq{"target": req.Target})
+	}
+	if req.RequestID != "" {
+		query = query.Where(sq.Eq{"request_id": req.RequestID})
+	}
+	if req.From != nil {
+		query = query.Where(sq.GtOrEq{"created_at": *req.From})
+	}
+	if req.To != nil {
+		query = query.Where(sq.Lt{"created_at": *req.To})
+	}
+	if req.BeforeID > 0 {
+		query = query.Where(sq.Lt{"id": req.BeforeID})
+	}
+	q, args, _ := query.ToSql()
+
+	rows, err := r.db.Query(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch audit events: %v", err)
+	}
+	defer rows.Close()
+
+	res := &entities.AuditEventsResponse{Events: []entities.AuditEvent{}}
+	for rows.Next() {
+		e, err := scanAuditEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		res.Events = append(res.Events, *e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch audit events: %v", err)
+	}
+
+	if len(res.Events) > req.Limit {
+		res.Events = res.Events[:req.Limit]
+		next := res.Events[req.Limit-1].ID
+		res.NextBeforeID = &next
+	}
+	return res, nil
+}
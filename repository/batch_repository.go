@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"ttavito/domain/entities"
+)
+
+// Ошибки перевода, текст которых можно показать отправителю
+var batchRowErrors = []error{
+	entities.ErrTransferToSelf,
+	entities.ErrNotEnoughBalance,
+	entities.ErrUserNotFound,
+	entities.ErrUserDeactivated,
+	entities.ErrAccountFrozen,
+	entities.ErrRecipientFrozen,
+}
+
+// batchRowError - причина отказа для ответа. Текст прочих ошибок может содержать
+// подробности запроса к базе, поэтому вместо него отдаётся общее сообщение
+func batchRowError(err error) string {
+	var violation *entities.PolicyViolation
+	if errors.As(err, &violation) {
+		return violation.Message
+	}
+	for _, known := range batchRowErrors {
+		if errors.Is(err, known) {
+			return known.Error()
+		}
+	}
+	return entities.BatchErrorTransferFailed
+}
+
+// BatchSendCoin выполняет все переводы в одной транзакции: либо проходят все, либо ни один.
+// Ответ заполняется и при ошибке, чтобы было видно, на каком получателе пакет откатился.
+func (r *EntityRepo) BatchSendCoin(ctx context.Context, senderUsername string, req entities.BatchSendCoinRequest) (res *entities.BatchSendCoinResponse, err error) {
+	res = &entities.BatchSendCoinResponse{
+		Results: make([]entities.BatchTransferResult, len(req.Transfers)),
+	}
+	for i, t := range req.Transfers {
+		res.Results[i] = entities.BatchTransferResult{
+			ToUser: t.ToUser,
+			Amount: t.Amount,
+			Status: entities.BatchStatusRolledBack,
+		}
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return res, fmt.Errorf("failed to start transaction: %v", err)
+	}
+
+	defer func() {
+		if err != nil {
+			slog.Error("Failed to send coin batch", "error", err)
+			tx.Rollback(ctx)
+			for i := range res.Results {
+				res.Results[i].TransferID = ""
+				if res.Results[i].Status == entities.BatchStatusSent {
+					res.Results[i].Status = entities.BatchStatusRolledBack
+				}
+			}
+			res.Total = 0
+		} else {
+			err = tx.Commit(ctx)
+			if err == nil {
+				slog.Info("success send coin batch", "count", len(req.Transfers))
+			}
+		}
+	}()
+
+	for i, t := range req.Transfers {
+		var transferID string
+		transferID, err = r.transfer(ctx, tx, senderUsername, t.ToUser, t.Amount, t.Message)
+		if err != nil {
+			res.Results[i].Status = entities.BatchStatusFailed
+			res.Results[i].Error = batchRowError(err)
+			return res, fmt.Errorf("transfer to %s failed: %w", t.ToUser, err)
+		}
+
+		res.Results[i].Status = entities.BatchStatusSent
+		res.Results[i].TransferID = transferID
+		res.Total += t.Amount
+	}
+
+	err = r.writeAudit(ctx, tx, senderUsername, entities.AuditTransferBatch, "",
+		auditPayload{"total": res.Total, "transfers": res.Results})
+	if err != nil {
+		return res, err
+	}
+
+	return res, nil
+}
@@ -0,0 +1,418 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"ttavito/domain/entities"
+	"ttavito/domain/interfaces"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	budgetEntryAllocation  = "allocation"
+	budgetEntryRecognition = "recognition"
+)
+
+func (r *EntityRepo) CreateDepartment(ctx context.Context, admin string, req entities.DepartmentRequest) (res *entities.Department, err error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %v", err)
+	}
+
+	defer func() {
+		if err != nil {
+			slog.Error("Failed to create department", "department", req.Name, "error", err)
+			tx.Rollback(ctx)
+		} else {
+			err = tx.Commit(ctx)
+			if err == nil {
+				slog.Info("success create department", "department", req.Name, "manager", req.Manager)
+			}
+		}
+	}()
+
+	err = r.ensureActiveUser(ctx, tx, req.Manager)
+	if err != nil {
+		return nil, err
+	}
+
+	q, args, _ := r.builder.Insert("departments").
+		Columns("name", "manager_username", "budget").
+		Values(req.Name, req.Manager, req.Budget).
+		Suffix("ON CONFLICT (name) DO NOTHING RETURNING created_at").
+		ToSql()
+
+	res = &entities.Department{
+		Name:    req.Name,
+		Manager: req.Manager,
+		Budget:  req.Budget,
+		Members: []string{},
+	}
+	err = tx.QueryRow(ctx, q, args...).Scan(&res.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, entities.ErrDepartmentExists
+		}
+		return nil, fmt.Errorf("failed to add department: %v", err)
+	}
+
+	if req.Budget > 0 {
+		_, _, err = r.insertBudgetEntry(ctx, tx, req.Name, budgetEntryAllocation, admin, nil, req.Budget, "")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = r.writeAudit(ctx, tx, admin, entities.AuditAdminDepartmentCreate, req.Name,
+		auditPayload{"manager": req.Manager, "budget": req.Budget})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// AddDepartmentMembers переводит пользователей в отдел, прежнее членство заменяется
+func (r *EntityRepo) AddDepartmentMembers(ctx context.Context, admin string, req entities.DepartmentMembersRequest) (res *entities.Department, err error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %v", err)
+	}
+
+	defer func() {
+		if err != nil {
+			slog.Error("Failed to add department members", "department", req.Department, "error", err)
+			tx.Rollback(ctx)
+		} else {
+			err = tx.Commit(ctx)
+			if err == nil {
+				slog.Info("success add department members", "department", req.Department, "count", len(req.Usernames))
+			}
+		}
+	}()
+
+	_, err = r.lockDepartment(ctx, tx, req.Department)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, username := range req.Usernames {
+		err = r.ensureActiveUser(ctx, tx, username)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", username, err)
+		}
+
+		q, args, _ := r.builder.Insert("department_members").
+			Columns("username", "department_name").
+			Values(username, req.Department).
+			Suffix("ON CONFLICT (username) DO UPDATE SET department_name = EXCLUDED.department_name").
+			ToSql()
+		_, err = tx.Exec(ctx, q, args...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to add department member: %v", err)
+		}
+	}
+
+	err = r.writeAudit(ctx, tx, admin, entities.AuditAdminDepartmentMember, req.Department,
+		auditPayload{"usernames": req.Usernames})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.getDepartment(ctx, tx, req.Department)
+}
+
+func (r *EntityRepo) AllocateDepartmentBudget(ctx context.Context, admin string, req entities.BudgetAllocationRequest) (res *entities.Department, err error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %v", err)
+	}
+
+	defer func() {
+		if err != nil {
+			slog.Error("Failed to allocate department budget", "department", req.Department, "error", err)
+			tx.Rollback(ctx)
+		} else {
+			err = tx.Commit(ctx)
+			if err == nil {
+				slog.Info("success allocate department budget", "department", req.Department, "amount", req.Amount)
+			}
+		}
+	}()
+
+	q, args, _ := r.builder.Update("departments").
+		Set("budget", sq.Expr("budget + ?", req.Amount)).
+		Where(sq.Eq{"name": req.Department}).
+		ToSql()
+	tag, err := tx.Exec(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update department budget: %v", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, entities.ErrDepartmentNotFound
+	}
+
+	_, _, err = r.insertBudgetEntry(ctx, tx, req.Department, budgetEntryAllocation, admin, nil, req.Amount, "")
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.writeAudit(ctx, tx, admin, entities.AuditAdminDepartmentBudget, req.Department, auditPayload{"amount": req.Amount})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.getDepartment(ctx, tx, req.Department)
+}
+
+// RecognizeFromBudget зачисляет монеты сотруднику отдела из бюджета отдела.
+// Личный баланс руководителя не меняется, лимиты переводов не применяются.
+func (r *EntityRepo) RecognizeFromBudget(ctx context.Context, manager string, req entities.RecognitionRequest) (res *entities.Recognition, err error) {
+	if manager == req.ToUser {
+		return nil, entities.ErrTransferToSelf
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %v", err)
+	}
+
+	defer func() {
+		if err != nil {
+			slog.Error("Failed to recognize from budget", "department", req.Department, "error", err)
+			tx.Rollback(ctx)
+		} else {
+			err = tx.Commit(ctx)
+			if err == nil {
+				slog.Info("success recognize from budget", "department", req.Department, "to", req.ToUser, "amount", req.Amount)
+			}
+		}
+	}()
+
+	dep, err := r.lockDepartment(ctx, tx, req.Department)
+	if err != nil {
+		return nil, err
+	}
+	if dep.Manager != manager {
+		return nil, entities.ErrNotDepartmentManager
+	}
+
+	q, args, _ := r.builder.Select("1").
+		From("department_members").
+		Where(sq.Eq{"username": req.ToUser, "department_name": req.Department}).
+		ToSql()
+	var member int
+	err = tx.QueryRow(ctx, q, args...).Scan(&member)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, entities.ErrNotDepartmentMember
+		}
+		return nil, fmt.Errorf("failed to check department member: %v", err)
+	}
+
+	err = r.checkNotFrozen(ctx, tx, req.ToUser, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if dep.Budget < req.Amount {
+		return nil, entities.ErrBudgetExhausted
+	}
+
+	q, args, _ = r.builder.Update("departments").
+		Set("budget", sq.Expr("budget - ?", req.Amount)).
+		Where(sq.Eq{"name": req.Department}).
+		ToSql()
+	_, err = tx.Exec(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update department budget: %v", err)
+	}
+
+	// Как и при переводе, увольнение получателя перепроверяется под блокировкой строки
+	q, args, _ = r.builder.Update("users").
+		Set("balance", sq.Expr("balance + ?", req.Amount)).
+		Where(sq.Eq{"username": req.ToUser, "deactivated_at": nil}).
+		ToSql()
+	tag, err := tx.Exec(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update receiver's balance: %v", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, r.ensureActiveUser(ctx, tx, req.ToUser)
+	}
+
+	err = r.creditLot(ctx, tx, req.ToUser, req.Amount, lotSourceBudget, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	res = &entities.Recognition{
+		Department: req.Department,
+		FromUser:   manager,
+		ToUser:     req.ToUser,
+		Amount:     req.Amount,
+		Message:    req.Message,
+		BudgetLeft: dep.Budget - req.Amount,
+	}
+	res.ID, res.CreatedAt, err = r.insertBudgetEntry(ctx, tx, req.Department, budgetEntryRecognition, manager, &req.ToUser, req.Amount, req.Message)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.writeCoinsTransferred(ctx, tx, res.ID, manager, req.ToUser, req.Amount, req.Message)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.writeAudit(ctx, tx, manager, entities.AuditTransferRecognition, req.ToUser,
+		auditPayload{"recognitionId": res.ID, "department": req.Department, "amount": req.Amount, "message": req.Message})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (r *EntityRepo) GetDepartmentBudgetReport(ctx context.Context, req entities.DepartmentReportRequest) (*entities.DepartmentBudgetReport, error) {
+	dep, err := r.getDepartment(ctx, r.db, req.Department)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &entities.DepartmentBudgetReport{
+		Department: dep.Name,
+		Manager:    dep.Manager,
+		Month:      req.Month.Format("2006-01"),
+		Remaining:  dep.Budget,
+		Recipients: []entities.RecognitionSummary{},
+	}
+
+	inMonth := sq.And{
+		sq.Eq{"department_name": req.Department},
+		sq.GtOrEq{"created_at": req.Month},
+		sq.Lt{"created_at": req.Month.AddDate(0, 1, 0)},
+	}
+
+	q, args, _ := r.builder.Select(
+		"COALESCE(SUM(amount) FILTER (WHERE kind = 'allocation'), 0)",
+		"COALESCE(SUM(amount) FILTER (WHERE kind = 'recognition'), 0)",
+		"COUNT(*) FILTER (WHERE kind = 'recognition')",
+	).
+		From("department_budget_entries").
+		Where(inMonth).
+		ToSql()
+
+	err = r.db.QueryRow(ctx, q, args...).Scan(&res.Allocated, &res.Spent, &res.Recognitions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get department budget totals: %v", err)
+	}
+
+	q, args, _ = r.builder.Select("recipient_username", "SUM(amount)", "COUNT(*)").
+		From("department_budget_entries").
+		Where(inMonth).
+		Where(sq.Eq{"kind": budgetEntryRecognition}).
+		GroupBy("recipient_username").
+		OrderBy("SUM(amount) DESC", "recipient_username").
+		ToSql()
+
+	rows, err := r.db.Query(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get department recognitions: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var s entities.RecognitionSummary
+		if err := rows.Scan(&s.ToUser, &s.Amount, &s.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan recognition summary: %v", err)
+		}
+		res.Recipients = append(res.Recipients, s)
+	}
+
+	return res, rows.Err()
+}
+
+// lockDepartment блокирует строку отдела до конца транзакции, чтобы бюджет не ушёл в минус
+func (r *EntityRepo) lockDepartment(ctx context.Context, tx pgx.Tx, name string) (*entities.Department, error) {
+	q, args, _ := r.builder.Select("name", "manager_username", "budget", "created_at").
+		From("departments").
+		Where(sq.Eq{"name": name}).
+		Suffix("FOR UPDATE").
+		ToSql()
+
+	var dep entities.Department
+	err := tx.QueryRow(ctx, q, args...).Scan(&dep.Name, &dep.Manager, &dep.Budget, &dep.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, entities.ErrDepartmentNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch department: %v", err)
+	}
+	return &dep, nil
+}
+
+func (r *EntityRepo) getDepartment(ctx context.Context, db interfaces.DB, name string) (*entities.Department, error) {
+	q, args, _ := r.builder.Select("name", "manager_username", "budget", "created_at").
+		From("departments").
+		Where(sq.Eq{"name": name}).
+		ToSql()
+
+	var dep entities.Department
+	err := db.QueryRow(ctx, q, args...).Scan(&dep.Name, &dep.Manager, &dep.Budget, &dep.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, entities.ErrDepartmentNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch department: %v", err)
+	}
+
+	q, args, _ = r.builder.Select("username").
+		From("department_members").
+		Where(sq.Eq{"department_name": name}).
+		OrderBy("username").
+		ToSql()
+
+	rows, err := db.Query(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get department members: %v", err)
+	}
+	defer rows.Close()
+
+	dep.Members = []string{}
+	for rows.Next() {
+		var username string
+		if err := rows.Scan(&username); err != nil {
+			return nil, fmt.Errorf("failed to scan department member: %v", err)
+		}
+		dep.Members = append(dep.Members, username)
+	}
+
+	return &dep, rows.Err()
+}
+
+func (r *EntityRepo) insertBudgetEntry(ctx context.Context, tx pgx.Tx, department, kind, actor string, recipient *string, amount int, message string) (string, time.Time, error) {
+	var entryMessage *string
+	if message != "" {
+		entryMessage = &message
+	}
+
+	q, args, _ := r.builder.Insert("department_budget_entries").
+		Columns("department_name", "kind", "actor_username", "recipient_username", "amount", "message").
+		Values(department, kind, actor, recipient, amount, entryMessage).
+		Suffix("RETURNING id::text, created_at").
+		ToSql()
+
+	var id string
+	var createdAt time.Time
+	err := tx.QueryRow(ctx, q, args...).Scan(&id, &createdAt)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to add budget entry: %v", err)
+	}
+	return id, createdAt, nil
+}
@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"ttavito/domain/entities"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+// Сколько отправителей и товаров попадает в письмо
+const digestTopLimit = 3
+
+// Переводы за неделю без сторно. Время переводится в timestamptz, чтобы сравнивать его с границами недели
+const digestTransfersQuery = `
+SELECT
+	COALESCE(SUM(amount) FILTER (WHERE receiver_username = $1), 0),
+	COUNT(*) FILTER (WHERE receiver_username = $1),
+	COALESCE(SUM(amount) FILTER (WHERE sender_username = $1), 0),
+	COUNT(*) FILTER (WHERE sender_username = $1)
+FROM transfers
+WHERE (sender_username = $1 OR receiver_username = $1)
+  AND reversal_of IS NULL
+  AND created_at::timestamptz >= $2 AND created_at::timestamptz < $3`
+
+const digestTopSendersQuery = `
+SELECT sender_username, SUM(amount) AS total
+FROM transfers
+WHERE receiver_username = $1
+  AND reversal_of IS NULL
+  AND created_at::timestamptz >= $2 AND created_at::timestamptz < $3
+GROUP BY sender_username
+ORDER BY total DESC, sender_username
+LIMIT $4`
+
+// Покупки, оплаченные пользователем, в том числе подарки
+const digestPurchasesQuery = `
+SELECT p.product_name, COUNT(*), SUM(pr.price)
+FROM purchases p
+JOIN products pr ON pr.product_name = p.product_name
+WHERE p.buyer_username = $1
+  AND p.created_at::timestamptz >= $2 AND p.created_at::timestamptz < $3
+GROUP BY p.product_name
+ORDER BY COUNT(*) DESC, p.product_name`
+
+func (r *EntityRepo) GetDigestEnabled(ctx context.Context, username string) (bool, error) {
+	q, args, _ := r.builder.Select().
+		Column(sq.Expr("NOT EXISTS (SELECT 1 FROM digest_optouts WHERE username = ?)", username)).
+		ToSql()
+
+	var enabled bool
+	if err := r.db.QueryRow(ctx, q, args...).Scan(&enabled); err != nil {
+		return false, fmt.Errorf("failed to fetch digest settings: %v", err)
+	}
+	return enabled, nil
+}
+
+func (r *EntityRepo) SetDigestEnabled(ctx context.Context, username string, enabled bool) error {
+	var q string
+	var args []any
+	if enabled {
+		q, args, _ = r.builder.Delete("digest_optouts").Where(sq.Eq{"username": username}).ToSql()
+	} else {
+		q, args, _ = r.builder.Insert("digest_optouts").
+			Columns("username").
+			Values(username).
+			Suffix("ON CONFLICT (username) DO NOTHING").
+			ToSql()
+	}
+
+	if _, err := r.db.Exec(ctx, q, args...); err != nil {
+		return fmt.Errorf("failed to update digest settings: %v", err)
+	}
+	return nil
+}
+
+// GetDigestRecipients возвращает пользователей, которым ещё не отправлялась сводка за неделю weekStart.
+// Отказавшиеся и уволенные пропускаются
+func (r *EntityRepo) GetDigestRecipients(ctx context.Context, weekStart time.Time, limit int) ([]string, error) {
+	q, args, _ := r.builder.Select("u.username").
+		From("users u").
+		Where("NOT EXISTS (SELECT 1 FROM digest_optouts o WHERE o.username = u.username)").
+		Where("NOT EXISTS (SELECT 1 FROM user_offboardings f WHERE f.username = u.username)").
+		Where("NOT EXISTS (SELECT 1 FROM digest_sends s WHERE s.username = u.username AND s.week_start = ?)", weekStart).
+		OrderBy("u.username").
+		Limit(uint64(limit)).
+		ToSql()
+
+	rows, err := r.db.Query(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch digest recipients: %v", err)
+	}
+	defer rows.Close()
+
+	var res []string
+	for rows.Next() {
+		var username string
+		if err := rows.Scan(&username); err != nil {
+			return nil, fmt.Errorf("failed to scan digest recipient: %v", err)
+		}
+		res = append(res, username)
+	}
+	return res, rows.Err()
+}
+
+// ClaimDigest занимает отправку сводки за неделю. false - её уже отправила другая реплика
+func (r *EntityRepo) ClaimDigest(ctx context.Context, username string, weekStart time.Time) (bool, error) {
+	q, args, _ := r.builder.Insert("digest_sends").
+		Columns("username", "week_start").
+		Values(username, weekStart).
+		Suffix("ON CONFLICT (username, week_start) DO NOTHING").
+		ToSql()
+
+	tag, err := r.db.Exec(ctx, q, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim digest: %v", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// ReleaseDigest снимает отметку, чтобы сводку отправил следующий запуск
+func (r *EntityRepo) ReleaseDigest(ctx context.Context, username string, weekStart time.Time) error {
+	q, args, _ := r.builder.Delete("digest_sends").
+		Where(sq.Eq{"username": username, "week_start": weekStart}).
+		ToSql()
+
+	if _, err := r.db.Exec(ctx, q, args...); err != nil {
+		return fmt.Errorf("failed to release digest: %v", err)
+	}
+	return nil
+}
+
+// GetDigestActivity собирает активность пользователя за [from, to)
+func (r *EntityRepo) GetDigestActivity(ctx context.Context, username string, from, to time.Time) (*entities.DigestActivity, error) {
+	a := &entities.DigestActivity{Username: username, WeekStart: from, WeekEnd: to}
+
+	q, args, _ := r.builder.Select("COALESCE(p.display_name, u.username)", "u.balance").
+		From("users u").
+		LeftJoin("user_profiles p ON p.username = u.username").
+		Where(sq.Eq{"u.username": username}).
+		ToSql()
+	if err := r.db.QueryRow(ctx, q, args...).Scan(&a.DisplayName, &a.Balance); err != nil {
+		return nil, fmt.Errorf("failed to fetch user: %v", err)
+	}
+
+	err := r.db.QueryRow(ctx, digestTransfersQuery, username, from, to).
+		Scan(&a.Received, &a.ReceivedCount, &a.Sent, &a.SentCount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate transfers: %v", err)
+	}
+
+	rows, err := r.db.Query(ctx, digestTopSendersQuery, username, from, to, digestTopLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch top senders: %v", err)
+	}
+	for rows.Next() {
+		var c entities.DigestCounterparty
+		if err := rows.Scan(&c.Username, &c.Amount); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan sender: %v", err)
+		}
+		a.TopSenders = append(a.TopSenders, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch top senders: %v", err)
+	}
+
+	rows, err = r.db.Query(ctx, digestPurchasesQuery, username, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate purchases: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var item entities.DigestItem
+		var spent int
+		if err := rows.Scan(&item.Item, &item.Count, &spent); err != nil {
+			return nil, fmt.Errorf("failed to scan purchase: %v", err)
+		}
+		a.Spent += spent
+		a.PurchaseCount += item.Count
+		if len(a.Items) < digestTopLimit {
+			a.Items = append(a.Items, item)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to aggregate purchases: %v", err)
+	}
+	return a, nil
+}
@@ -0,0 +1,239 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"ttavito/domain/entities"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+)
+
+// ImportOrgChart применяет файл оргструктуры одной транзакцией. Сотрудники, которых нет
+// в файле, не меняются. При dryRun все изменения выполняются и откатываются,
+// так что проверяются и ограничения базы.
+func (r *EntityRepo) ImportOrgChart(ctx context.Context, entries []entities.OrgChartEntry, dryRun bool) (res *entities.OrgChartImportResult, err error) {
+	if err = r.checkOrgChartUsers(ctx, entries); err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %v", err)
+	}
+
+	defer func() {
+		if err != nil || dryRun {
+			if err != nil {
+				slog.Error("Failed to import org chart", "error", err)
+			}
+			tx.Rollback(ctx)
+		} else {
+			err = tx.Commit(ctx)
+			if err == nil {
+				slog.Info("success import org chart", "departments", res.Departments, "teams", res.Teams, "users", res.Users)
+			}
+		}
+	}()
+
+	res = &entities.OrgChartImportResult{DryRun: dryRun, Users: len(entries)}
+
+	heads := make(map[string]string)
+	leads := make(map[string]string)
+	teamDepartment := make(map[string]string)
+	var departments, teams []string
+	for _, e := range entries {
+		if !slices.Contains(departments, e.Department) {
+			departments = append(departments, e.Department)
+		}
+		if e.Team != "" && !slices.Contains(teams, e.Team) {
+			teams = append(teams, e.Team)
+			teamDepartment[e.Team] = e.Department
+		}
+		switch e.Role {
+		case entities.OrgRoleDepartmentHead:
+			heads[e.Department] = e.Username
+		case entities.OrgRoleTeamLead:
+			leads[e.Team] = e.Username
+		}
+	}
+
+	for _, name := range departments {
+		err = r.upsertDepartment(ctx, tx, name, heads[name])
+		if err != nil {
+			return nil, err
+		}
+	}
+	res.Departments = len(departments)
+
+	for _, name := range teams {
+		var lead *string
+		if username, ok := leads[name]; ok {
+			lead = &username
+		}
+
+		q, args, _ := r.builder.Insert("teams").
+			Columns("name", "department_name", "lead_username").
+			Values(name, teamDepartment[name], lead).
+			Suffix("ON CONFLICT (name) DO UPDATE SET department_name = EXCLUDED.department_name, lead_username = EXCLUDED.lead_username").
+			ToSql()
+		_, err = tx.Exec(ctx, q, args...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to save team %s: %v", name, err)
+		}
+	}
+	res.Teams = len(teams)
+
+	for _, e := range entries {
+		err = r.applyOrgChartEntry(ctx, tx, e)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", e.Username, err)
+		}
+	}
+
+	// Оргструктуру загружает оператор через CLI, поэтому автор - system
+	err = r.writeAudit(ctx, tx, entities.AuditActorSystem, entities.AuditAdminOrgChartImport, "",
+		auditPayload{"departments": res.Departments, "teams": res.Teams, "users": res.Users})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// checkOrgChartUsers проверяет, что все сотрудники и руководители из файла уже есть в users
+func (r *EntityRepo) checkOrgChartUsers(ctx context.Context, entries []entities.OrgChartEntry) error {
+	var usernames []string
+	for _, e := range entries {
+		usernames = append(usernames, e.Username)
+		if e.Manager != "" {
+			usernames = append(usernames, e.Manager)
+		}
+	}
+
+	known, err := r.existingUsers(ctx, usernames)
+	if err != nil {
+		return err
+	}
+
+	var unknown []string
+	for _, username := range usernames {
+		if !known[username] && !slices.Contains(unknown, username) {
+			unknown = append(unknown, username)
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("%w: %s", entities.ErrOrgChartUnknownUsers, strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// upsertDepartment создаёт отдел или меняет его руководителя. Без head существующий
+// отдел остаётся как есть, а новый создать нельзя: у отдела всегда есть руководитель.
+func (r *EntityRepo) upsertDepartment(ctx context.Context, tx pgx.Tx, name, head string) error {
+	if head == "" {
+		_, err := r.getDepartment(ctx, tx, name)
+		if errors.Is(err, entities.ErrDepartmentNotFound) {
+			return fmt.Errorf("department %s: %w", name, entities.ErrOrgChartNoDepartmentHead)
+		}
+		return err
+	}
+
+	q, args, _ := r.builder.Insert("departments").
+		Columns("name", "manager_username").
+		Values(name, head).
+		Suffix("ON CONFLICT (name) DO UPDATE SET manager_username = EXCLUDED.manager_username").
+		ToSql()
+	_, err := tx.Exec(ctx, q, args...)
+	if err != nil {
+		return fmt.Errorf("failed to save department %s: %v", name, err)
+	}
+	return nil
+}
+
+func (r *EntityRepo) applyOrgChartEntry(ctx context.Context, tx pgx.Tx, e entities.OrgChartEntry) error {
+	q, args, _ := r.builder.Insert("department_members").
+		Columns("username", "department_name").
+		Values(e.Username, e.Department).
+		Suffix("ON CONFLICT (username) DO UPDATE SET department_name = EXCLUDED.department_name").
+		ToSql()
+	_, err := tx.Exec(ctx, q, args...)
+	if err != nil {
+		return fmt.Errorf("failed to save department member: %v", err)
+	}
+
+	if e.Team != "" {
+		q, args, _ = r.builder.Insert("team_members").
+			Columns("username", "team_name").
+			Values(e.Username, e.Team).
+			Suffix("ON CONFLICT (username) DO UPDATE SET team_name = EXCLUDED.team_name").
+			ToSql()
+	} else {
+		q, args, _ = r.builder.Delete("team_members").
+			Where(sq.Eq{"username": e.Username}).
+			ToSql()
+	}
+	_, err = tx.Exec(ctx, q, args...)
+	if err != nil {
+		return fmt.Errorf("failed to save team member: %v", err)
+	}
+
+	var manager *string
+	if e.Manager != "" {
+		manager = &e.Manager
+	}
+
+	q, args, _ = r.builder.Update("users").
+		Set("manager_username", manager).
+		Where(sq.Eq{"username": e.Username}).
+		ToSql()
+	_, err = tx.Exec(ctx, q, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update manager: %v", err)
+	}
+	return nil
+}
+
+func (r *EntityRepo) GetTeam(ctx context.Context, name string) (*entities.Team, error) {
+	q, args, _ := r.builder.Select("name", "department_name", "COALESCE(lead_username, '')").
+		From("teams").
+		Where(sq.Eq{"name": name}).
+		ToSql()
+
+	var team entities.Team
+	err := r.db.QueryRow(ctx, q, args...).Scan(&team.Name, &team.Department, &team.Lead)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, entities.ErrTeamNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch team: %v", err)
+	}
+
+	q, args, _ = r.builder.Select("username").
+		From("team_members").
+		Where(sq.Eq{"team_name": name}).
+		OrderBy("username").
+		ToSql()
+
+	rows, err := r.db.Query(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team members: %v", err)
+	}
+	defer rows.Close()
+
+	team.Members = []string{}
+	for rows.Next() {
+		var username string
+		if err := rows.Scan(&username); err != nil {
+			return nil, fmt.Errorf("failed to scan team member: %v", err)
+		}
+		team.Members = append(team.Members, username)
+	}
+
+	return &team, rows.Err()
+}
@@ -2,30 +2,96 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"time"
 
+	"ttavito/config"
 	"ttavito/domain/entities"
 	"ttavito/domain/interfaces"
+	"ttavito/policy"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/jackc/pgx/v5"
 )
 
 type EntityRepo struct {
-	db      interfaces.DB
-	builder sq.StatementBuilderType
+	db             interfaces.DB
+	builder        sq.StatementBuilderType
+	transferPolicy policy.Rules
+	coinTTL        time.Duration
+	expiryWarning  time.Duration
+	grantMaxAmount int
 }
 
-func NewEntityRepo(db interfaces.DB) interfaces.ShopRepository {
+type Option func(*EntityRepo)
+
+// WithTransferPolicy включает лимиты на исходящие переводы
+func WithTransferPolicy(rules policy.Rules) Option {
+	return func(r *EntityRepo) {
+		r.transferPolicy = rules
+	}
+}
+
+// WithCoinTTL включает сгорание монет через ttl после поступления
+func WithCoinTTL(ttl time.Duration) Option {
+	return func(r *EntityRepo) {
+		r.coinTTL = ttl
+	}
+}
+
+// WithExpiryWarning задаёт, за сколько до сгорания монеты попадают в expiringSoon
+func WithExpiryWarning(d time.Duration) Option {
+	return func(r *EntityRepo) {
+		r.expiryWarning = d
+	}
+}
+
+// WithGrantMaxAmount ограничивает сумму одного начисления администратором, 0 - без ограничения
+func WithGrantMaxAmount(amount int) Option {
+	return func(r *EntityRepo) {
+		r.grantMaxAmount = amount
+	}
+}
+
+// ConfigOptions собирает настройки репозитория из конфига. Сервис и утилиты командной строки
+// создают репозиторий с ними, чтобы, например, монеты из CLI сгорали так же, как из API
+func ConfigOptions(cfg *config.Config) []Option {
+	return []Option{
+		WithTransferPolicy(policy.Rules{
+			MaxAmount:           cfg.TransferMaxAmount,
+			DailyLimit:          cfg.TransferDailyLimit,
+			WeeklyLimit:         cfg.TransferWeeklyLimit,
+			RecipientDailyLimit: cfg.TransferRecipientDailyLimit,
+			RecipientCooldown:   cfg.TransferRecipientCooldown,
+		}),
+		WithCoinTTL(cfg.CoinTTL),
+		WithExpiryWarning(cfg.CoinExpiryWarning),
+		WithGrantMaxAmount(cfg.GrantMaxAmount),
+	}
+}
+
+func NewEntityRepo(db interfaces.DB, opts ...Option) interfaces.ShopRepository {
 	slog.Info("Repository created")
-	return &EntityRepo{
-		db:      db,
-		builder: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	r := &EntityRepo{
+		db:            db,
+		builder:       sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+		expiryWarning: DefaultExpiryWarning,
+	}
+	for _, opt := range opts {
+		opt(r)
 	}
+	return r
 }
 
 func (r *EntityRepo) BuyItem(ctx context.Context, username, item string) error {
+	return r.purchase(ctx, username, username, item, "")
+}
+
+// purchase списывает цену товара с buyer и записывает покупку на owner.
+// Для обычной покупки buyer и owner совпадают, для подарка owner - получатель.
+func (r *EntityRepo) purchase(ctx context.Context, buyer, owner, item, message string) (err error) {
 	tx, err := r.db.Begin(ctx)
 	if err != nil {
 		slog.Error("failed to start transaction", "error", err)
@@ -51,12 +117,16 @@ func (r *EntityRepo) BuyItem(ctx context.Context, username, item string) error {
 	var price int
 	err = tx.QueryRow(ctx, selectPriceQuery, args...).Scan(&price)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entities.ErrProductNotFound
+		}
 		return fmt.Errorf("failed to fetch product price: %v", err)
 	}
 
 	selectBalanceQuery, args, _ := r.builder.Select("balance").
 		From("users").
-		Where(sq.Eq{"username": username}).
+		Where(sq.Eq{"username": buyer}).
+		Suffix("FOR UPDATE").
 		ToSql()
 	var balance int
 	err = tx.QueryRow(ctx, selectBalanceQuery, args...).Scan(&balance)
@@ -64,31 +134,117 @@ func (r *EntityRepo) BuyItem(ctx context.Context, username, item string) error {
 		return fmt.Errorf("failed to fetch user balance: %v", err)
 	}
 
+	err = r.checkNotFrozen(ctx, tx, buyer, false)
+	if err != nil {
+		return err
+	}
+
 	if balance < price {
-		return fmt.Errorf("not enough balance to buy the product")
+		return entities.ErrNotEnoughBalance
+	}
+
+	if owner != buyer {
+		err = r.ensureActiveUser(ctx, tx, owner)
+		if err != nil {
+			return err
+		}
 	}
 
 	updateBalanceQuery, args, _ := r.builder.Update("users").
 		Set("balance", sq.Expr("balance - ?", price)).
-		Where(sq.Eq{"username": username}).
+		Where(sq.Eq{"username": buyer}).
 		ToSql()
 	_, err = tx.Exec(ctx, updateBalanceQuery, args...)
 	if err != nil {
 		return fmt.Errorf("failed to update user balance: %v", err)
 	}
 
+	err = r.debitLots(ctx, tx, buyer, price)
+	if err != nil {
+		return err
+	}
+
+	var giftMessage *string
+	if message != "" {
+		giftMessage = &message
+	}
+
 	insertPurchaseQuery, args, _ := r.builder.Insert("purchases").
-		Columns("username", "product_name").
-		Values(username, item).
+		Columns("username", "product_name", "buyer_username", "gift_message").
+		Values(owner, item, buyer, giftMessage).
 		ToSql()
 	_, err = tx.Exec(ctx, insertPurchaseQuery, args...)
 	if err != nil {
 		return fmt.Errorf("failed to insert purchase record: %v", err)
 	}
 
+	err = r.writeOutbox(ctx, tx, entities.EventItemPurchased, buyer,
+		entities.ItemPurchasedEvent{Buyer: buyer, Owner: owner, Item: item, Price: price})
+	if err != nil {
+		return err
+	}
+
+	if owner != buyer {
+		return r.writeAudit(ctx, tx, buyer, entities.AuditPurchaseGift, owner,
+			auditPayload{"item": item, "price": price, "message": message})
+	}
+	return r.writeAudit(ctx, tx, buyer, entities.AuditPurchaseBuy, buyer, auditPayload{"item": item, "price": price})
+}
+
+// ensureActiveUser возвращает ErrUserNotFound, если пользователя нет,
+// и ErrUserDeactivated, если он уволен и не может получать монеты
+func (r *EntityRepo) ensureActiveUser(ctx context.Context, db interfaces.DB, username string) error {
+	q, args, _ := r.builder.Select("deactivated_at IS NOT NULL").
+		From("users").
+		Where(sq.Eq{"username": username}).
+		ToSql()
+
+	var deactivated bool
+	err := db.QueryRow(ctx, q, args...).Scan(&deactivated)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entities.ErrUserNotFound
+		}
+		return fmt.Errorf("failed to check user: %v", err)
+	}
+	if deactivated {
+		return entities.ErrUserDeactivated
+	}
 	return nil
 }
 
+// lockUsers блокирует строки пользователей в порядке логинов и возвращает их балансы.
+// Переводы между двумя пользователями блокируют строки только так, иначе встречные
+// переводы и сторно могли бы взять блокировки в разном порядке и зависнуть
+func (r *EntityRepo) lockUsers(ctx context.Context, tx pgx.Tx, usernames ...string) (map[string]int, error) {
+	q, args, _ := r.builder.Select("username", "balance").
+		From("users").
+		Where(sq.Eq{"username": usernames}).
+		OrderBy("username").
+		Suffix("FOR UPDATE").
+		ToSql()
+
+	rows, err := tx.Query(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock users: %v", err)
+	}
+	defer rows.Close()
+
+	balances := make(map[string]int, len(usernames))
+	for rows.Next() {
+		var username string
+		var balance int
+		if err := rows.Scan(&username, &balance); err != nil {
+			return nil, fmt.Errorf("failed to lock users: %v", err)
+		}
+		balances[username] = balance
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to lock users: %v", err)
+	}
+	return balances, nil
+}
+
 func (r *EntityRepo) GetInfo(ctx context.Context, username string) (*entities.InfoResponse, error) {
 	var res entities.InfoResponse
 
@@ -128,17 +284,43 @@ func (r *EntityRepo) GetInfo(ctx context.Context, username string) (*entities.In
 		return nil, fmt.Errorf("failed to get user transactions: %w", err)
 	}
 
+	res.Gifts.Sent, res.Gifts.Received, err = r.GetUserGifts(ctx, username)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user gifts: %w", err)
+	}
+
+	res.ExpiringSoon, err = r.GetExpiringCoins(ctx, username, time.Now().Add(r.expiryWarning))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get expiring coins: %w", err)
+	}
+
+	res.Frozen, err = r.getFreezeStatus(ctx, r.db, username)
+	if err != nil {
+		return nil, err
+	}
+
 	return &res, nil
 }
 
+const createUserQuery = `
+WITH created AS (
+	INSERT INTO users (username, user_password) VALUES ($1, $2)
+	RETURNING username, balance
+)
+INSERT INTO coin_lots (username, amount, remaining, source, expires_at)
+SELECT username, balance, balance, $3, $4::timestamptz
+FROM created
+WHERE balance > 0`
+
 func (r *EntityRepo) Auth(ctx context.Context, username, password string) (bool, error) {
 	var existingPassword string
-	q, args, _ := r.builder.Select("user_password").
+	var deactivated bool
+	q, args, _ := r.builder.Select("user_password", "deactivated_at IS NOT NULL").
 		From("users").
 		Where(sq.Eq{"username": username}).
 		ToSql()
 
-	err := r.db.QueryRow(ctx, q, args...).Scan(&existingPassword)
+	err := r.db.QueryRow(ctx, q, args...).Scan(&existingPassword, &deactivated)
 
 	defer func() {
 		if err != nil {
@@ -150,21 +332,26 @@ func (r *EntityRepo) Auth(ctx context.Context, username, password string) (bool,
 
 	if err == nil {
 		if existingPassword != password {
-			return false, nil
+			err = r.writeAuditAlone(ctx, username, entities.AuditAuthLoginFailed, username, auditPayload{"reason": "invalid_password"})
+			return false, err
 		}
-		return true, nil
+		// Уволенный сотрудник не может войти, даже зная пароль
+		if deactivated {
+			err = r.writeAuditAlone(ctx, username, entities.AuditAuthLoginFailed, username, auditPayload{"reason": "deactivated"})
+			if err != nil {
+				return false, err
+			}
+			return false, entities.ErrUserDeactivated
+		}
+		err = r.writeAuditAlone(ctx, username, entities.AuditAuthLogin, username, nil)
+		return err == nil, err
 	}
 
 	if err.Error() != pgx.ErrNoRows.Error() {
 		return false, err
 	}
 
-	q, args, _ = r.builder.Insert("users").
-		Columns("username", "user_password").
-		Values(username, password).
-		ToSql()
-
-	_, err = r.db.Exec(ctx, q, args...)
+	err = r.register(ctx, username, password)
 	if err != nil {
 		return false, err
 	}
@@ -172,7 +359,30 @@ func (r *EntityRepo) Auth(ctx context.Context, username, password string) (bool,
 	return true, nil
 }
 
-func (r *EntityRepo) SendCoin(ctx context.Context, senderUsername string, recipientUsername string, amount int) error {
+func (r *EntityRepo) register(ctx context.Context, username, password string) (err error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %v", err)
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		} else {
+			err = tx.Commit(ctx)
+		}
+	}()
+
+	// Стартовый баланс из DEFAULT колонки сразу оформляется партией монет
+	_, err = tx.Exec(ctx, createUserQuery, username, password, lotSourceInitial, r.lotExpiresAt(time.Now()))
+	if err != nil {
+		return err
+	}
+
+	return r.writeAudit(ctx, tx, username, entities.AuditAuthRegister, username, nil)
+}
+
+func (r *EntityRepo) SendCoin(ctx context.Context, senderUsername string, recipientUsername string, amount int, message string) (err error) {
 	tx, err := r.db.Begin(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to start transaction: %v", err)
@@ -190,62 +400,107 @@ func (r *EntityRepo) SendCoin(ctx context.Context, senderUsername string, recipi
 		}
 	}()
 
-	query, args, _ := r.builder.Select("balance").
-		From("users").
-		Where(sq.Eq{"username": senderUsername}).
-		ToSql()
+	transferID, err := r.transfer(ctx, tx, senderUsername, recipientUsername, amount, message)
+	if err != nil {
+		return err
+	}
 
-	var senderBalance int
-	err = tx.QueryRow(ctx, query, args...).Scan(&senderBalance)
+	return r.writeAudit(ctx, tx, senderUsername, entities.AuditTransferSend, recipientUsername,
+		auditPayload{"transferId": transferID, "amount": amount, "message": message})
+}
+
+// transfer переводит монеты внутри уже открытой транзакции и возвращает id перевода
+func (r *EntityRepo) transfer(ctx context.Context, tx pgx.Tx, senderUsername string, recipientUsername string, amount int, message string) (string, error) {
+	if senderUsername == recipientUsername {
+		return "", entities.ErrTransferToSelf
+	}
+
+	balances, err := r.lockUsers(ctx, tx, senderUsername, recipientUsername)
 	if err != nil {
-		return fmt.Errorf("unable to get sender's balance: %v sender %v", err, senderBalance)
+		return "", err
+	}
+	senderBalance, ok := balances[senderUsername]
+	if !ok {
+		return "", fmt.Errorf("unable to get sender's balance: %v", pgx.ErrNoRows)
+	}
+
+	err = r.checkTransferNotFrozen(ctx, tx, senderUsername, recipientUsername)
+	if err != nil {
+		return "", err
 	}
 
 	if senderBalance < amount {
-		err = fmt.Errorf("not enough balance for the transfer")
-		return err
+		return "", entities.ErrNotEnoughBalance
 	}
 
+	err = r.checkTransferPolicy(ctx, tx, senderUsername, recipientUsername, amount)
+	if err != nil {
+		return "", err
+	}
+
+	return r.moveCoins(ctx, tx, senderUsername, recipientUsername, amount, message)
+}
+
+// moveCoins списывает монеты отправителя и зачисляет их получателю без проверок баланса
+// и лимитов. Строка отправителя должна быть уже заблокирована вызывающим.
+// Событие coins.transferred пишется здесь же, чтобы его не пропустил ни один вид перевода.
+func (r *EntityRepo) moveCoins(ctx context.Context, tx pgx.Tx, senderUsername string, recipientUsername string, amount int, message string) (string, error) {
 	updateSenderBalance, args, _ := r.builder.Update("users").
-		Set("balance", senderBalance-amount).
+		Set("balance", sq.Expr("balance - ?", amount)).
 		Where(sq.Eq{"username": senderUsername}).
 		ToSql()
-	_, err = tx.Exec(ctx, updateSenderBalance, args...)
-
+	_, err := tx.Exec(ctx, updateSenderBalance, args...)
 	if err != nil {
-		return fmt.Errorf("failed to update sender's balance: %v", err)
+		return "", fmt.Errorf("failed to update sender's balance: %v", err)
 	}
 
-	query, args, _ = r.builder.Select("balance").
-		From("users").
-		Where(sq.Eq{"username": recipientUsername}).
-		ToSql()
-
-	var receiverBalance int
-	err = tx.QueryRow(ctx, query, args...).Scan(&receiverBalance)
+	err = r.debitLots(ctx, tx, senderUsername, amount)
 	if err != nil {
-		return fmt.Errorf("unable to get receiver's balance: %v", err)
+		return "", err
 	}
 
+	// Условие на deactivated_at перепроверяется после ожидания блокировки,
+	// поэтому перевод не пройдёт одновременно с увольнением получателя
 	updateReceiverBalance, args, _ := r.builder.Update("users").
-		Set("balance", receiverBalance+amount).
-		Where(sq.Eq{"username": recipientUsername}).
+		Set("balance", sq.Expr("balance + ?", amount)).
+		Where(sq.Eq{"username": recipientUsername, "deactivated_at": nil}).
 		ToSql()
-	_, err = tx.Exec(ctx, updateReceiverBalance, args...)
+	tag, err := tx.Exec(ctx, updateReceiverBalance, args...)
+	if err != nil {
+		return "", fmt.Errorf("failed to update receiver's balance: %v", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return "", r.ensureActiveUser(ctx, tx, recipientUsername)
+	}
+
+	err = r.creditLot(ctx, tx, recipientUsername, amount, lotSourceTransfer, time.Now())
 	if err != nil {
-		return fmt.Errorf("failed to update receiver's balance: %v", err)
+		return "", err
+	}
+
+	var transferMessage *string
+	if message != "" {
+		transferMessage = &message
 	}
 
 	transferQuery, args, _ := r.builder.Insert("transfers").
-		Columns("sender_username", "receiver_username", "amount").
-		Values(senderUsername, recipientUsername, amount).
+		Columns("sender_username", "receiver_username", "amount", "message").
+		Values(senderUsername, recipientUsername, amount, transferMessage).
+		Suffix("RETURNING id::text").
 		ToSql()
-	_, err = tx.Exec(ctx, transferQuery, args...)
+
+	var transferID string
+	err = tx.QueryRow(ctx, transferQuery, args...).Scan(&transferID)
 	if err != nil {
-		return fmt.Errorf("failed to add in transfers: %v", err)
+		return "", fmt.Errorf("failed to add in transfers: %v", err)
 	}
 
-	return nil
+	err = r.writeCoinsTransferred(ctx, tx, transferID, senderUsername, recipientUsername, amount, message)
+	if err != nil {
+		return "", err
+	}
+
+	return transferID, nil
 }
 
 func (r *EntityRepo) GetUserInventory(ctx context.Context, username string) ([]entities.ItemResponse, error) {
@@ -278,9 +533,10 @@ func (r *EntityRepo) GetUserInventory(ctx context.Context, username string) ([]e
 func (r *EntityRepo) GetUserTransactions(ctx context.Context, username string) ([]entities.SentResponse, []entities.ReceivedResponse, error) {
 	// Транзакции от пользователя
 	qSent, args, _ := r.builder.
-		Select("receiver_username", "amount").
-		From("transfers").
-		Where(sq.Eq{"sender_username": username}).
+		Select("t.id::text", "t.receiver_username", "COALESCE(p.display_name, t.receiver_username)", "t.amount", "COALESCE(t.message, '')", "COALESCE(t.reversal_of::text, '')").
+		From("transfers t").
+		LeftJoin("user_profiles p ON p.username = t.receiver_username").
+		Where(sq.Eq{"t.sender_username": username}).
 		ToSql()
 
 	rowsSent, err := r.db.Query(ctx, qSent, args...)
@@ -292,16 +548,17 @@ func (r *EntityRepo) GetUserTransactions(ctx context.Context, username string) (
 	var sent []entities.SentResponse
 	for rowsSent.Next() {
 		var t entities.SentResponse
-		if err := rowsSent.Scan(&t.ToUser, &t.Amount); err != nil {
+		if err := rowsSent.Scan(&t.ID, &t.ToUser, &t.ToDisplayName, &t.Amount, &t.Message, &t.ReversalOf); err != nil {
 			return nil, nil, err
 		}
 		sent = append(sent, t)
 	}
 
 	qReceived, args, _ := r.builder.
-		Select("sender_username", "amount").
-		From("transfers").
-		Where(sq.Eq{"receiver_username": username}).
+		Select("t.id::text", "t.sender_username", "COALESCE(p.display_name, t.sender_username)", "t.amount", "COALESCE(t.message, '')", "COALESCE(t.reversal_of::text, '')").
+		From("transfers t").
+		LeftJoin("user_profiles p ON p.username = t.sender_username").
+		Where(sq.Eq{"t.receiver_username": username}).
 		ToSql()
 
 	rowsReceived, err := r.db.Query(ctx, qReceived, args...)
@@ -313,7 +570,7 @@ func (r *EntityRepo) GetUserTransactions(ctx context.Context, username string) (
 	var received []entities.ReceivedResponse
 	for rowsReceived.Next() {
 		var t entities.ReceivedResponse
-		if err := rowsReceived.Scan(&t.FromUser, &t.Amount); err != nil {
+		if err := rowsReceived.Scan(&t.ID, &t.FromUser, &t.FromDisplayName, &t.Amount, &t.Message, &t.ReversalOf); err != nil {
 			return nil, nil, err
 		}
 		received = append(received, t)
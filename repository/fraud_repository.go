@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"ttavito/domain/entities"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+)
+
+// Переводы за окно без сторно и сторнированных: их уже разобрал администратор.
+// Время переводится в timestamptz, чтобы сравнивать его с моментом регистрации.
+const fraudTransfersQuery = `
+SELECT t.id::text, t.sender_username, t.receiver_username, t.amount, t.created_at::timestamptz, u.created_at
+FROM transfers t
+JOIN users u ON u.username = t.sender_username
+WHERE t.created_at > LOCALTIMESTAMP - make_interval(secs => $1)
+  AND t.reversal_of IS NULL
+  AND NOT EXISTS (SELECT 1 FROM transfers r WHERE r.reversal_of = t.id)
+ORDER BY t.created_at`
+
+var fraudFindingColumns = []string{
+	"id::text", "kind", "username", "participants", "transfer_ids::text[]", "amount", "details", "status",
+	"detected_at", "COALESCE(reviewed_by, '')", "reviewed_at", "COALESCE(review_note, '')",
+}
+
+func scanFraudFinding(row pgx.Row) (*entities.FraudFinding, error) {
+	var f entities.FraudFinding
+	err := row.Scan(&f.ID, &f.Kind, &f.Username, &f.Participants, &f.TransferIDs, &f.Amount, &f.Details, &f.Status,
+		&f.DetectedAt, &f.ReviewedBy, &f.ReviewedAt, &f.ReviewNote)
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// GetFraudTransfers возвращает переводы за последние window для поиска схем
+func (r *EntityRepo) GetFraudTransfers(ctx context.Context, window time.Duration) ([]entities.FraudTransfer, error) {
+	rows, err := r.db.Query(ctx, fraudTransfersQuery, window.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch transfers: %v", err)
+	}
+	defer rows.Close()
+
+	var res []entities.FraudTransfer
+	for rows.Next() {
+		var t entities.FraudTransfer
+		if err := rows.Scan(&t.ID, &t.From, &t.To, &t.Amount, &t.CreatedAt, &t.SenderRegisteredAt); err != nil {
+			return nil, fmt.Errorf("failed to scan transfer: %v", err)
+		}
+		res = append(res, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch transfers: %v", err)
+	}
+	return res, nil
+}
+
+// SaveFraudFindings добавляет находки в очередь и возвращает, сколько из них новые.
+// Находка пропускается, если её fingerprint уже известен или если находка того же вида
+// на того же пользователя уже содержит хотя бы один из её переводов, даже если её разобрали.
+func (r *EntityRepo) SaveFraudFindings(ctx context.Context, findings []entities.FraudFinding) (int, error) {
+	added := 0
+	for _, f := range findings {
+		known := sq.Select("1").
+			From("fraud_findings").
+			Where(sq.Eq{"kind": f.Kind, "username": f.Username}).
+			Where("transfer_ids && ?::uuid[]", f.TransferIDs)
+		q, args, _ := r.builder.Insert("fraud_findings").
+			Columns("fingerprint", "kind", "username", "participants", "transfer_ids", "amount", "details").
+			Select(sq.Select().
+				Column("?::varchar", f.Fingerprint).
+				Column("?::varchar", f.Kind).
+				Column("?::varchar", f.Username).
+				Column("?::varchar[]", f.Participants).
+				Column("?::uuid[]", f.TransferIDs).
+				Column("?::int", f.Amount).
+				Column("?::varchar", f.Details).
+				Where(sq.Expr("NOT EXISTS (?)", known))).
+			Suffix("ON CONFLICT (fingerprint) DO NOTHING").
+			ToSql()
+
+		tag, err := r.db.Exec(ctx, q, args...)
+		if err != nil {
+			return added, fmt.Errorf("failed to add fraud finding: %v", err)
+		}
+		if tag.RowsAffected() > 0 {
+			added++
+			slog.Warn("Fraud finding detected", "kind", f.Kind, "username", f.Username, "details", f.Details)
+		}
+	}
+	return added, nil
+}
+
+func (r *EntityRepo) GetFraudFindings(ctx context.Context, req entities.FraudFindingsRequest) (*entities.FraudFindingsResponse, error) {
+	query := r.builder.Select(fraudFindingColumns...).
+		From("fraud_findings").
+		OrderBy("detected_at DESC", "id").
+		Limit(uint64(req.Limit))
+	if req.Status != "" {
+		query = query.Where(sq.Eq{"status": req.Status})
+	}
+	if req.Kind != "" {
+		query = query.Where(sq.Eq{"kind": req.Kind})
+	}
+	q, args, _ := query.ToSql()
+
+	rows, err := r.db.Query(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch fraud findings: %v", err)
+	}
+	defer rows.Close()
+
+	res := &entities.FraudFindingsResponse{Findings: []entities.FraudFinding{}}
+	for rows.Next() {
+		f, err := scanFraudFinding(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan fraud finding: %v", err)
+		}
+		res.Findings = append(res.Findings, *f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch fraud findings: %v", err)
+	}
+	return res, nil
+}
+
+// ReviewFraudFinding закрывает открытую находку решением администратора
+func (r *EntityRepo) ReviewFraudFinding(ctx context.Context, admin string, req entities.FraudReviewRequest) (res *entities.FraudFinding, err error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %v", err)
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		} else {
+			err = tx.Commit(ctx)
+			if err == nil {
+				slog.Info("success review fraud finding", "id", req.ID, "status", req.Status)
+			}
+		}
+	}()
+
+	q, args, _ := r.builder.Update("fraud_findings").
+		Set("status", req.Status).
+		Set("reviewed_by", admin).
+		Set("reviewed_at", sq.Expr("CURRENT_TIMESTAMP")).
+		Set("review_note", req.Note).
+		Where(sq.Eq{"id": req.ID, "status": entities.FraudStatusOpen}).
+		Suffix("RETURNING " + strings.Join(fraudFindingColumns, ", ")).
+		ToSql()
+
+	res, err = scanFraudFinding(tx.QueryRow(ctx, q, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		q, args, _ = r.builder.Select("1").From("fraud_findings").Where(sq.Eq{"id": req.ID}).ToSql()
+		var exists int
+		err = tx.QueryRow(ctx, q, args...).Scan(&exists)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, entities.ErrFraudFindingNotFound
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch fraud finding: %v", err)
+		}
+		return nil, entities.ErrFraudFindingReviewed
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to review fraud finding: %v", err)
+	}
+
+	err = r.writeAudit(ctx, tx, admin, entities.AuditAdminFraudReview, res.Username,
+		auditPayload{"findingId": res.ID, "kind": res.Kind, "status": req.Status, "note": req.Note})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"ttavito/domain/entities"
+	"ttavito/domain/interfaces"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+)
+
+var accountFreezeColumns = []string{
+	"id::text", "username", "reason", "block_incoming", "frozen_by", "frozen_at",
+	"COALESCE(unfrozen_by, '')", "unfrozen_at", "COALESCE(unfreeze_reason, '')",
+}
+
+func scanAccountFreeze(row pgx.Row) (*entities.AccountFreeze, error) {
+	var f entities.AccountFreeze
+	err := row.Scan(&f.ID, &f.Username, &f.Reason, &f.BlockIncoming, &f.FrozenBy, &f.FrozenAt,
+		&f.UnfrozenBy, &f.UnfrozenAt, &f.UnfreezeReason)
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// FreezeAccount запрещает пользователю тратить монеты до разморозки.
+// Строка пользователя блокируется, поэтому уже начатые покупки и переводы успеют завершиться,
+// а следующие увидят заморозку.
+func (r *EntityRepo) FreezeAccount(ctx context.Context, admin string, req entities.FreezeRequest) (res *entities.AccountFreeze, err error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %v", err)
+	}
+
+	defer func() {
+		if err != nil {
+			slog.Error("Failed to freeze account", "username", req.Username, "error", err)
+			tx.Rollback(ctx)
+		} else {
+			err = tx.Commit(ctx)
+			if err == nil {
+				slog.Info("success freeze account", "username", req.Username, "block_incoming", req.BlockIncoming)
+			}
+		}
+	}()
+
+	q, args, _ := r.builder.Select("1").
+		From("users").
+		Where(sq.Eq{"username": req.Username}).
+		Suffix("FOR UPDATE").
+		ToSql()
+
+	var exists int
+	err = tx.QueryRow(ctx, q, args...).Scan(&exists)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, entities.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to lock user: %v", err)
+	}
+
+	q, args, _ = r.builder.Insert("account_freezes").
+		Columns("username", "reason", "block_incoming", "frozen_by").
+		Values(req.Username, req.Reason, req.BlockIncoming, admin).
+		Suffix("ON CONFLICT (username) WHERE unfrozen_at IS NULL DO NOTHING RETURNING " + strings.Join(accountFreezeColumns, ", ")).
+		ToSql()
+
+	res, err = scanAccountFreeze(tx.QueryRow(ctx, q, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, entities.ErrAlreadyFrozen
+		}
+		return nil, fmt.Errorf("failed to add account freeze: %v", err)
+	}
+
+	err = r.writeAudit(ctx, tx, admin, entities.AuditAdminFreeze, req.Username,
+		auditPayload{"freezeId": res.ID, "reason": req.Reason, "blockIncoming": req.BlockIncoming})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (r *EntityRepo) UnfreezeAccount(ctx context.Context, admin string, req entities.UnfreezeRequest) (res *entities.AccountFreeze, err error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %v", err)
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		} else {
+			err = tx.Commit(ctx)
+			if err == nil {
+				slog.Info("success unfreeze account", "username", req.Username)
+			}
+		}
+	}()
+
+	q, args, _ := r.builder.Update("account_freezes").
+		Set("unfrozen_by", admin).
+		Set("unfrozen_at", sq.Expr("CURRENT_TIMESTAMP")).
+		Set("unfreeze_reason", req.Reason).
+		Where(sq.Eq{"username": req.Username, "unfrozen_at": nil}).
+		Suffix("RETURNING " + strings.Join(accountFreezeColumns, ", ")).
+		ToSql()
+
+	res, err = scanAccountFreeze(tx.QueryRow(ctx, q, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, entities.ErrAccountNotFrozen
+		}
+		return nil, fmt.Errorf("failed to unfreeze account: %v", err)
+	}
+
+	err = r.writeAudit(ctx, tx, admin, entities.AuditAdminUnfreeze, req.Username,
+		auditPayload{"freezeId": res.ID, "reason": req.Reason})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// checkNotFrozen возвращает ErrAccountFrozen для замороженного отправителя и ErrRecipientFrozen
+// для получателя, у которого заморожены и входящие переводы. Проверка отправителя надёжна,
+// только если его строка в users уже заблокирована в этой транзакции.
+func (r *EntityRepo) checkNotFrozen(ctx context.Context, db interfaces.DB, username string, incoming bool) error {
+	status, err := r.getFreezeStatus(ctx, db, username)
+	if err != nil || status == nil {
+		return err
+	}
+
+	if !incoming {
+		return entities.ErrAccountFrozen
+	}
+	if status.BlockIncoming {
+		return entities.ErrRecipientFrozen
+	}
+	return nil
+}
+
+func (r *EntityRepo) checkTransferNotFrozen(ctx context.Context, db interfaces.DB, sender, recipient string) error {
+	if err := r.checkNotFrozen(ctx, db, sender, false); err != nil {
+		return err
+	}
+	return r.checkNotFrozen(ctx, db, recipient, true)
+}
+
+func (r *EntityRepo) getFreezeStatus(ctx context.Context, db interfaces.DB, username string) (*entities.FreezeStatus, error) {
+	q, args, _ := r.builder.Select("reason", "block_incoming", "frozen_at").
+		From("account_freezes").
+		Where(sq.Eq{"username": username, "unfrozen_at": nil}).
+		ToSql()
+
+	var status entities.FreezeStatus
+	err := db.QueryRow(ctx, q, args...).Scan(&status.Reason, &status.BlockIncoming, &status.FrozenAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to fetch account freeze: %v", err)
+	}
+	return &status, nil
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+
+	"ttavito/domain/entities"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+func (r *EntityRepo) GiftItem(ctx context.Context, buyer string, req entities.GiftRequest) error {
+	return r.purchase(ctx, buyer, req.ToUser, req.Item, req.Message)
+}
+
+func (r *EntityRepo) GetUserGifts(ctx context.Context, username string) ([]entities.GiftSentResponse, []entities.GiftReceivedResponse, error) {
+	// Подарки, купленные пользователем для других
+	qSent, args, _ := r.builder.
+		Select("username", "product_name", "COALESCE(gift_message, '')").
+		From("purchases").
+		Where(sq.And{
+			sq.Eq{"buyer_username": username},
+			sq.NotEq{"username": username},
+		}).
+		OrderBy("created_at").
+		ToSql()
+
+	rowsSent, err := r.db.Query(ctx, qSent, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rowsSent.Close()
+
+	var sent []entities.GiftSentResponse
+	for rowsSent.Next() {
+		var g entities.GiftSentResponse
+		if err := rowsSent.Scan(&g.ToUser, &g.Item, &g.Message); err != nil {
+			return nil, nil, err
+		}
+		sent = append(sent, g)
+	}
+
+	qReceived, args, _ := r.builder.
+		Select("buyer_username", "product_name", "COALESCE(gift_message, '')").
+		From("purchases").
+		Where(sq.And{
+			sq.Eq{"username": username},
+			sq.NotEq{"buyer_username": username},
+		}).
+		OrderBy("created_at").
+		ToSql()
+
+	rowsReceived, err := r.db.Query(ctx, qReceived, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rowsReceived.Close()
+
+	var received []entities.GiftReceivedResponse
+	for rowsReceived.Next() {
+		var g entities.GiftReceivedResponse
+		if err := rowsReceived.Scan(&g.FromUser, &g.Item, &g.Message); err != nil {
+			return nil, nil, err
+		}
+		received = append(received, g)
+	}
+
+	return sent, received, nil
+}
@@ -0,0 +1,221 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"ttavito/domain/entities"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+)
+
+func (r *EntityRepo) GrantCoins(ctx context.Context, admin string, req entities.GrantRequest) (res *entities.Grant, err error) {
+	if err := r.checkGrantAmount(req.Amount); err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %v", err)
+	}
+
+	defer func() {
+		if err != nil {
+			slog.Error("Failed to grant coins", "to", req.ToUser, "error", err)
+			tx.Rollback(ctx)
+		} else {
+			err = tx.Commit(ctx)
+			if err == nil {
+				slog.Info("success grant coins", "id", res.ID, "by", admin, "to", req.ToUser, "amount", req.Amount)
+			}
+		}
+	}()
+
+	res, err = r.grant(ctx, tx, admin, nil, req.ToUser, req.Amount, req.Reason)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.writeAudit(ctx, tx, admin, entities.AuditAdminGrant, req.ToUser,
+		auditPayload{"grantId": res.ID, "amount": req.Amount, "reason": req.Reason})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// BulkGrantCoins сначала проверяет все строки и пишет в базу, только если ошибок нет.
+// При DryRun база не меняется, в ответе - статус каждой строки.
+func (r *EntityRepo) BulkGrantCoins(ctx context.Context, admin string, req entities.BulkGrantRequest) (res *entities.BulkGrantResponse, err error) {
+	res = &entities.BulkGrantResponse{
+		DryRun: req.DryRun,
+		Rows:   append([]entities.BulkGrantRow(nil), req.Rows...),
+	}
+
+	var usernames []string
+	for i := range res.Rows {
+		row := &res.Rows[i]
+		if row.Status == entities.GrantRowInvalid {
+			continue
+		}
+		if err := r.checkGrantAmount(row.Amount); err != nil {
+			row.Status = entities.GrantRowInvalid
+			row.Error = err.Error()
+			continue
+		}
+		usernames = append(usernames, row.ToUser)
+	}
+
+	known, err := r.existingUsers(ctx, usernames)
+	if err != nil {
+		return nil, err
+	}
+
+	res.Valid = true
+	for i := range res.Rows {
+		row := &res.Rows[i]
+		switch {
+		case row.Status == entities.GrantRowInvalid:
+		case !known[row.ToUser]:
+			row.Status = entities.GrantRowUnknownUser
+			row.Error = entities.ErrUserNotFound.Error()
+		default:
+			row.Status = entities.GrantRowValid
+			res.Total += row.Amount
+			continue
+		}
+		res.Valid = false
+	}
+
+	if !res.Valid {
+		return res, entities.ErrBulkGrantInvalid
+	}
+	if req.DryRun {
+		return res, nil
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %v", err)
+	}
+
+	defer func() {
+		if err != nil {
+			slog.Error("Failed to bulk grant coins", "error", err)
+			tx.Rollback(ctx)
+		} else {
+			err = tx.Commit(ctx)
+			if err == nil {
+				slog.Info("success bulk grant coins", "batch", res.BatchID, "by", admin, "rows", len(res.Rows), "total", res.Total)
+			}
+		}
+	}()
+
+	err = tx.QueryRow(ctx, "SELECT uuid_generate_v4()::text").Scan(&res.BatchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate batch id: %v", err)
+	}
+
+	for i := range res.Rows {
+		row := &res.Rows[i]
+		var g *entities.Grant
+		g, err = r.grant(ctx, tx, admin, &res.BatchID, row.ToUser, row.Amount, row.Reason)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", row.Line, err)
+		}
+		row.Status = entities.GrantRowGranted
+		row.GrantID = g.ID
+	}
+
+	err = r.writeAudit(ctx, tx, admin, entities.AuditAdminBulkGrant, "",
+		auditPayload{"batchId": res.BatchID, "rows": len(res.Rows), "total": res.Total})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// existingUsers возвращает множество тех usernames, что есть в users и не уволены
+func (r *EntityRepo) existingUsers(ctx context.Context, usernames []string) (map[string]bool, error) {
+	res := make(map[string]bool, len(usernames))
+	if len(usernames) == 0 {
+		return res, nil
+	}
+
+	q, args, _ := r.builder.Select("username").
+		From("users").
+		Where(sq.Eq{"username": usernames, "deactivated_at": nil}).
+		ToSql()
+
+	dbRows, err := r.db.Query(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check users: %v", err)
+	}
+	defer dbRows.Close()
+
+	for dbRows.Next() {
+		var username string
+		if err := dbRows.Scan(&username); err != nil {
+			return nil, fmt.Errorf("failed to scan username: %v", err)
+		}
+		res[username] = true
+	}
+	return res, dbRows.Err()
+}
+
+func (r *EntityRepo) grant(ctx context.Context, tx pgx.Tx, admin string, batchID *string, toUser string, amount int, reason string) (*entities.Grant, error) {
+	q, args, _ := r.builder.Update("users").
+		Set("balance", sq.Expr("balance + ?", amount)).
+		Where(sq.Eq{"username": toUser, "deactivated_at": nil}).
+		ToSql()
+	tag, err := tx.Exec(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update receiver's balance: %v", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, r.ensureActiveUser(ctx, tx, toUser)
+	}
+
+	err = r.creditLot(ctx, tx, toUser, amount, lotSourceGrant, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	q, args, _ = r.builder.Insert("coin_grants").
+		Columns("batch_id", "granted_by", "username", "amount", "reason").
+		Values(batchID, admin, toUser, amount, reason).
+		Suffix("RETURNING id::text, created_at").
+		ToSql()
+
+	g := entities.Grant{
+		GrantedBy: admin,
+		ToUser:    toUser,
+		Amount:    amount,
+		Reason:    reason,
+	}
+	if batchID != nil {
+		g.BatchID = *batchID
+	}
+	err = tx.QueryRow(ctx, q, args...).Scan(&g.ID, &g.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add grant: %v", err)
+	}
+
+	err = r.writeCoinsTransferred(ctx, tx, g.ID, admin, toUser, amount, reason)
+	if err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+func (r *EntityRepo) checkGrantAmount(amount int) error {
+	if r.grantMaxAmount > 0 && amount > r.grantMaxAmount {
+		return fmt.Errorf("%w of %d", entities.ErrGrantAmountTooLarge, r.grantMaxAmount)
+	}
+	return nil
+}
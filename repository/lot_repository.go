@@ -0,0 +1,229 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"ttavito/domain/entities"
+	"ttavito/domain/interfaces"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+)
+
+// Откуда пришла партия монет
+const (
+	lotSourceInitial   = "initial"
+	lotSourceTransfer  = "transfer"
+	lotSourceRefund    = "refund"
+	lotSourceReversal  = "reversal"
+	lotSourceGrant     = "grant"
+	lotSourceAllowance = "allowance"
+	lotSourceBudget    = "budget"
+)
+
+const (
+	DefaultExpiryWarning = 7 * 24 * time.Hour
+
+	// Сколько пользователей обрабатывается за один проход сгорания
+	expireLotsBatchSize = 500
+)
+
+// Списание с самых старых партий: before - сколько лежит в партиях, выданных раньше текущей.
+// Партия затрагивается, пока before меньше суммы списания.
+const debitLotsQuery = `
+WITH ordered AS (
+	SELECT id, remaining,
+		SUM(remaining) OVER (ORDER BY issued_at, id) - remaining AS before
+	FROM coin_lots
+	WHERE username = $1 AND remaining > 0
+)
+UPDATE coin_lots l
+SET remaining = l.remaining - LEAST(o.remaining, $2::int - o.before)
+FROM ordered o
+WHERE l.id = o.id AND o.before < $2::int`
+
+// lotExpiresAt возвращает срок жизни партии, выданной в issuedAt, или nil, если монеты бессрочные
+func (r *EntityRepo) lotExpiresAt(issuedAt time.Time) *time.Time {
+	if r.coinTTL <= 0 {
+		return nil
+	}
+	expiresAt := issuedAt.Add(r.coinTTL)
+	return &expiresAt
+}
+
+// creditLot заводит партию на поступившие монеты, баланс меняет вызывающий
+func (r *EntityRepo) creditLot(ctx context.Context, db interfaces.DB, username string, amount int, source string, issuedAt time.Time) error {
+	q, args, _ := r.builder.Insert("coin_lots").
+		Columns("username", "amount", "remaining", "source", "issued_at", "expires_at").
+		Values(username, amount, amount, source, issuedAt, r.lotExpiresAt(issuedAt)).
+		ToSql()
+
+	_, err := db.Exec(ctx, q, args...)
+	if err != nil {
+		return fmt.Errorf("failed to add coin lot: %v", err)
+	}
+	return nil
+}
+
+// debitLots списывает amount с самых старых партий. Вызывающий должен держать
+// блокировку строки пользователя в users, иначе параллельные списания разойдутся.
+func (r *EntityRepo) debitLots(ctx context.Context, tx pgx.Tx, username string, amount int) error {
+	_, err := tx.Exec(ctx, debitLotsQuery, username, amount)
+	if err != nil {
+		return fmt.Errorf("failed to debit coin lots: %v", err)
+	}
+	return nil
+}
+
+// ExpireCoinLots списывает с балансов остатки просроченных партий и возвращает сумму сгоревших монет
+func (r *EntityRepo) ExpireCoinLots(ctx context.Context, now time.Time) (int, error) {
+	q, args, _ := r.builder.Select("DISTINCT username").
+		From("coin_lots").
+		Where(sq.Gt{"remaining": 0}).
+		Where(sq.LtOrEq{"expires_at": now}).
+		Limit(expireLotsBatchSize).
+		ToSql()
+
+	rows, err := r.db.Query(ctx, q, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch expired coin lots: %v", err)
+	}
+
+	var usernames []string
+	for rows.Next() {
+		var username string
+		if err := rows.Scan(&username); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan username: %v", err)
+		}
+		usernames = append(usernames, username)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to fetch expired coin lots: %v", err)
+	}
+
+	var total int
+	var errs []error
+	for _, username := range usernames {
+		expired, err := r.expireUserLots(ctx, username, now)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("user %s: %w", username, err))
+			continue
+		}
+		total += expired
+	}
+
+	return total, errors.Join(errs...)
+}
+
+// expireUserLots сжигает просроченные партии одного пользователя. Строка users блокируется
+// первой, как и при списаниях, поэтому сгорание не взаимоблокируется с покупками и переводами.
+func (r *EntityRepo) expireUserLots(ctx context.Context, username string, now time.Time) (expired int, err error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to start transaction: %v", err)
+	}
+
+	defer func() {
+		if err != nil {
+			slog.Error("Failed to expire coin lots", "username", username, "error", err)
+			tx.Rollback(ctx)
+		} else {
+			err = tx.Commit(ctx)
+			if err == nil && expired > 0 {
+				slog.Info("success expire coin lots", "username", username, "amount", expired)
+			}
+		}
+	}()
+
+	q, args, _ := r.builder.Select("1").
+		From("users").
+		Where(sq.Eq{"username": username}).
+		Suffix("FOR UPDATE").
+		ToSql()
+
+	var locked int
+	err = tx.QueryRow(ctx, q, args...).Scan(&locked)
+	if err != nil {
+		return 0, fmt.Errorf("failed to lock user: %v", err)
+	}
+
+	q, args, _ = r.builder.Update("coin_lots").
+		Set("expired_amount", sq.Expr("expired_amount + remaining")).
+		Set("remaining", 0).
+		Where(sq.Eq{"username": username}).
+		Where(sq.Gt{"remaining": 0}).
+		Where(sq.LtOrEq{"expires_at": now}).
+		Suffix("RETURNING expired_amount").
+		ToSql()
+
+	rows, err := tx.Query(ctx, q, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire coin lots: %v", err)
+	}
+	for rows.Next() {
+		var amount int
+		if err = rows.Scan(&amount); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan expired amount: %v", err)
+		}
+		expired += amount
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to expire coin lots: %v", err)
+	}
+
+	if expired == 0 {
+		return 0, nil
+	}
+
+	q, args, _ = r.builder.Update("users").
+		Set("balance", sq.Expr("GREATEST(balance - ?, 0)", expired)).
+		Where(sq.Eq{"username": username}).
+		ToSql()
+	_, err = tx.Exec(ctx, q, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to update user balance: %v", err)
+	}
+
+	err = r.writeAudit(ctx, tx, entities.AuditActorSystem, entities.AuditCoinsExpire, username, auditPayload{"amount": expired})
+	if err != nil {
+		return 0, err
+	}
+
+	return expired, nil
+}
+
+// GetExpiringCoins возвращает партии, которые сгорят до before, по возрастанию срока
+func (r *EntityRepo) GetExpiringCoins(ctx context.Context, username string, before time.Time) ([]entities.ExpiringCoins, error) {
+	q, args, _ := r.builder.Select("SUM(remaining)", "expires_at").
+		From("coin_lots").
+		Where(sq.Eq{"username": username}).
+		Where(sq.Gt{"remaining": 0}).
+		Where(sq.LtOrEq{"expires_at": before}).
+		GroupBy("expires_at").
+		OrderBy("expires_at").
+		ToSql()
+
+	rows, err := r.db.Query(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get expiring coins: %v", err)
+	}
+	defer rows.Close()
+
+	var res []entities.ExpiringCoins
+	for rows.Next() {
+		var c entities.ExpiringCoins
+		if err := rows.Scan(&c.Amount, &c.ExpiresAt); err != nil {
+			return nil, fmt.Errorf("failed to scan expiring coins: %v", err)
+		}
+		res = append(res, c)
+	}
+	return res, rows.Err()
+}
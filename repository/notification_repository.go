@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"ttavito/domain/entities"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+)
+
+// Уведомления пишут триггеры миграции 0023, здесь только чтение и отметки о прочтении
+
+var notificationColumns = []string{"id", "type", "data::text", "created_at", "read_at"}
+
+func scanNotification(row pgx.Row) (*entities.Notification, error) {
+	var n entities.Notification
+	var data string
+	if err := row.Scan(&n.ID, &n.Type, &data, &n.CreatedAt, &n.ReadAt); err != nil {
+		return nil, err
+	}
+	n.Data = json.RawMessage(data)
+	return &n, nil
+}
+
+func (r *EntityRepo) GetNotifications(ctx context.Context, username string, req entities.NotificationsRequest) (*entities.NotificationsResponse, error) {
+	query := r.builder.Select(notificationColumns...).
+		From("notifications").
+		Where(sq.Eq{"username": username}).
+		OrderBy("id DESC").
+		// Лишняя строка показывает, есть ли следующая страница
+		Limit(uint64(req.Limit) + 1)
+	if req.UnreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+	if req.BeforeID > 0 {
+		query = query.Where(sq.Lt{"id": req.BeforeID})
+	}
+	q, args, _ := query.ToSql()
+
+	rows, err := r.db.Query(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch notifications: %v", err)
+	}
+	defer rows.Close()
+
+	res := &entities.NotificationsResponse{Notifications: []entities.Notification{}}
+	for rows.Next() {
+		n, err := scanNotification(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan notification: %v", err)
+		}
+		res.Notifications = append(res.Notifications, *n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch notifications: %v", err)
+	}
+
+	if len(res.Notifications) > req.Limit {
+		res.Notifications = res.Notifications[:req.Limit]
+		next := res.Notifications[req.Limit-1].ID
+		res.NextBeforeID = &next
+	}
+
+	q, args, _ = r.builder.Select("COUNT(*)").
+		From("notifications").
+		Where(sq.Eq{"username": username}).
+		Where("read_at IS NULL").
+		ToSql()
+	if err := r.db.QueryRow(ctx, q, args...).Scan(&res.UnreadCount); err != nil {
+		return nil, fmt.Errorf("failed to count unread notifications: %v", err)
+	}
+	return res, nil
+}
+
+// MarkNotificationRead отмечает уведомление прочитанным. Повторная отметка не меняет время прочтения
+func (r *EntityRepo) MarkNotificationRead(ctx context.Context, username string, id int64) (*entities.Notification, error) {
+	q, args, _ := r.builder.Update("notifications").
+		Set("read_at", sq.Expr("COALESCE(read_at, CURRENT_TIMESTAMP)")).
+		Where(sq.Eq{"id": id, "username": username}).
+		Suffix("RETURNING " + strings.Join(notificationColumns, ", ")).
+		ToSql()
+
+	n, err := scanNotification(r.db.QueryRow(ctx, q, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, entities.ErrNotificationNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to mark notification read: %v", err)
+	}
+	return n, nil
+}
+
+// MarkNotificationsRead отмечает прочитанными все уведомления пользователя, upToID > 0 - только до него включительно
+func (r *EntityRepo) MarkNotificationsRead(ctx context.Context, username string, upToID int64) (int, error) {
+	query := r.builder.Update("notifications").
+		Set("read_at", sq.Expr("CURRENT_TIMESTAMP")).
+		Where(sq.Eq{"username": username}).
+		Where("read_at IS NULL")
+	if upToID > 0 {
+		query = query.Where(sq.LtOrEq{"id": upToID})
+	}
+	q, args, _ := query.ToSql()
+
+	tag, err := r.db.Exec(ctx, q, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark notifications read: %v", err)
+	}
+	return int(tag.RowsAffected()), nil
+}
@@ -0,0 +1,258 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"ttavito/domain/entities"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+)
+
+// OffboardUser увольняет сотрудника одной транзакцией: возвращает отправителям
+// неподтверждённые переводы, отменяет расписания, убирает из отделов и команд,
+// сжигает или распределяет остаток баланса и отзывает выданные токены.
+func (r *EntityRepo) OffboardUser(ctx context.Context, admin string, req entities.OffboardRequest) (res *entities.Offboarding, err error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %v", err)
+	}
+
+	defer func() {
+		if err != nil {
+			slog.Error("Failed to offboard user", "username", req.Username, "error", err)
+			tx.Rollback(ctx)
+		} else {
+			err = tx.Commit(ctx)
+			if err == nil {
+				slog.Info("success offboard user", "username", req.Username, "policy", req.BalancePolicy, "amount", res.BalanceAmount)
+			}
+		}
+	}()
+
+	// Блокировка строки пользователя не даёт параллельно тратить и получать монеты
+	q, args, _ := r.builder.Select("deactivated_at IS NOT NULL").
+		From("users").
+		Where(sq.Eq{"username": req.Username}).
+		Suffix("FOR UPDATE").
+		ToSql()
+
+	var deactivated bool
+	err = tx.QueryRow(ctx, q, args...).Scan(&deactivated)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, entities.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch user: %v", err)
+	}
+	if deactivated {
+		return nil, entities.ErrUserDeactivated
+	}
+
+	for _, recipient := range req.Recipients {
+		if recipient == req.Username {
+			return nil, fmt.Errorf("%w: %s", entities.ErrInvalidOffboardRecipient, recipient)
+		}
+		err = r.ensureActiveUser(ctx, tx, recipient)
+		if errors.Is(err, entities.ErrUserNotFound) || errors.Is(err, entities.ErrUserDeactivated) {
+			return nil, fmt.Errorf("%w: %s", entities.ErrInvalidOffboardRecipient, recipient)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	res = &entities.Offboarding{
+		Username:      req.Username,
+		OffboardedBy:  admin,
+		Reason:        req.Reason,
+		BalancePolicy: req.BalancePolicy,
+	}
+
+	res.PendingRefunded, err = r.refundUserPendingTransfers(ctx, tx, req.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	q, args, _ = r.builder.Update("scheduled_transfers").
+		Set("status", entities.ScheduleStatusCancelled).
+		Set("next_run_at", nil).
+		Where(sq.Or{sq.Eq{"sender_username": req.Username}, sq.Eq{"receiver_username": req.Username}}).
+		Where(sq.Eq{"status": []string{entities.ScheduleStatusActive, entities.ScheduleStatusPaused}}).
+		ToSql()
+	tag, err := tx.Exec(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel scheduled transfers: %v", err)
+	}
+	res.SchedulesCancelled = int(tag.RowsAffected())
+
+	for _, table := range []string{"department_members", "team_members"} {
+		q, args, _ = r.builder.Delete(table).Where(sq.Eq{"username": req.Username}).ToSql()
+		_, err = tx.Exec(ctx, q, args...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to remove from %s: %v", table, err)
+		}
+	}
+
+	// Баланс читается после возврата исходящих неподтверждённых переводов
+	q, args, _ = r.builder.Select("balance").From("users").Where(sq.Eq{"username": req.Username}).ToSql()
+	err = tx.QueryRow(ctx, q, args...).Scan(&res.BalanceAmount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch balance: %v", err)
+	}
+
+	if res.BalanceAmount > 0 {
+		if req.BalancePolicy == entities.BalancePolicyRedistribute {
+			res.Transfers, err = r.redistributeBalance(ctx, tx, req.Username, req.Recipients, res.BalanceAmount)
+		} else {
+			err = r.forfeitBalance(ctx, tx, req.Username, res.BalanceAmount)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	q, args, _ = r.builder.Update("users").
+		Set("deactivated_at", now).
+		Set("sessions_revoked_at", now).
+		Where(sq.Eq{"username": req.Username}).
+		ToSql()
+	_, err = tx.Exec(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to deactivate user: %v", err)
+	}
+
+	recipients := req.Recipients
+	if recipients == nil {
+		recipients = []string{}
+	}
+	q, args, _ = r.builder.Insert("user_offboardings").
+		Columns("username", "admin_username", "reason", "balance_policy", "balance_amount",
+			"recipients", "pending_refunded", "schedules_cancelled").
+		Values(req.Username, admin, req.Reason, req.BalancePolicy, res.BalanceAmount,
+			recipients, res.PendingRefunded, res.SchedulesCancelled).
+		Suffix("RETURNING id::text, created_at").
+		ToSql()
+	err = tx.QueryRow(ctx, q, args...).Scan(&res.ID, &res.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add offboarding record: %v", err)
+	}
+
+	err = r.writeAudit(ctx, tx, admin, entities.AuditAdminOffboard, req.Username, auditPayload{
+		"offboardingId": res.ID, "reason": req.Reason, "balancePolicy": req.BalancePolicy,
+		"balanceAmount": res.BalanceAmount, "recipients": recipients,
+		"pendingRefunded": res.PendingRefunded, "schedulesCancelled": res.SchedulesCancelled,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// refundUserPendingTransfers отклоняет входящие и исходящие неподтверждённые переводы
+func (r *EntityRepo) refundUserPendingTransfers(ctx context.Context, tx pgx.Tx, username string) (int, error) {
+	q, args, _ := r.builder.Select(pendingTransferColumns...).
+		From("pending_transfers").
+		Where(sq.Or{sq.Eq{"sender_username": username}, sq.Eq{"receiver_username": username}}).
+		Where(sq.Eq{"status": entities.PendingStatusPending}).
+		Suffix("FOR UPDATE").
+		ToSql()
+
+	rows, err := tx.Query(ctx, q, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch pending transfers: %v", err)
+	}
+
+	var pending []*entities.PendingTransfer
+	for rows.Next() {
+		p, err := scanPendingTransfer(rows)
+		if err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan pending transfer: %v", err)
+		}
+		pending = append(pending, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to fetch pending transfers: %v", err)
+	}
+
+	for _, p := range pending {
+		if err := r.refundPendingTransfer(ctx, tx, p); err != nil {
+			return 0, err
+		}
+
+		q, args, _ = r.builder.Update("pending_transfers").
+			Set("status", entities.PendingStatusDeclined).
+			Set("resolved_at", sq.Expr("CURRENT_TIMESTAMP")).
+			Where(sq.Eq{"id": p.ID}).
+			ToSql()
+		if _, err := tx.Exec(ctx, q, args...); err != nil {
+			return 0, fmt.Errorf("failed to update pending transfer: %v", err)
+		}
+	}
+
+	return len(pending), nil
+}
+
+// forfeitBalance списывает остаток в пользу магазина, в истории переводов он не появляется
+func (r *EntityRepo) forfeitBalance(ctx context.Context, tx pgx.Tx, username string, amount int) error {
+	q, args, _ := r.builder.Update("users").
+		Set("balance", 0).
+		Where(sq.Eq{"username": username}).
+		ToSql()
+	_, err := tx.Exec(ctx, q, args...)
+	if err != nil {
+		return fmt.Errorf("failed to forfeit balance: %v", err)
+	}
+
+	return r.debitLots(ctx, tx, username, amount)
+}
+
+// redistributeBalance делит остаток поровну, остаток от деления достаётся первым получателям.
+// Лимиты переводов не применяются: это не решение самого сотрудника.
+func (r *EntityRepo) redistributeBalance(ctx context.Context, tx pgx.Tx, username string, recipients []string, amount int) ([]entities.OffboardingTransfer, error) {
+	share, rest := amount/len(recipients), amount%len(recipients)
+
+	var res []entities.OffboardingTransfer
+	for i, recipient := range recipients {
+		part := share
+		if i < rest {
+			part++
+		}
+		if part == 0 {
+			continue
+		}
+
+		id, err := r.moveCoins(ctx, tx, username, recipient, part, "")
+		if err != nil {
+			return nil, fmt.Errorf("recipient %s: %w", recipient, err)
+		}
+		res = append(res, entities.OffboardingTransfer{ToUser: recipient, Amount: part, TransferID: id})
+	}
+	return res, nil
+}
+
+// SessionsRevokedAt возвращает момент отзыва токенов пользователя, нулевое время - токены не отзывались
+func (r *EntityRepo) SessionsRevokedAt(ctx context.Context, username string) (time.Time, error) {
+	q, args, _ := r.builder.Select("sessions_revoked_at").
+		From("users").
+		Where(sq.Eq{"username": username}).
+		ToSql()
+
+	var revokedAt *time.Time
+	err := r.db.QueryRow(ctx, q, args...).Scan(&revokedAt)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return time.Time{}, fmt.Errorf("failed to fetch sessions state: %v", err)
+	}
+	if revokedAt == nil {
+		return time.Time{}, nil
+	}
+	return *revokedAt, nil
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"ttavito/domain/entities"
+	"ttavito/domain/interfaces"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+// writeOutbox добавляет доменное событие в outbox в транзакции самого действия
+func (r *EntityRepo) writeOutbox(ctx context.Context, db interfaces.DB, eventType, key string, payload any) error {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode event %s: %v", eventType, err)
+	}
+
+	q, args, _ := r.builder.Insert("outbox_events").
+		Columns("event_type", "event_key", "payload").
+		Values(eventType, key, sq.Expr("?::jsonb", string(raw))).
+		ToSql()
+	_, err = db.Exec(ctx, q, args...)
+	if err != nil {
+		return fmt.Errorf("failed to write event %s: %v", eventType, err)
+	}
+	return nil
+}
+
+// writeCoinsTransferred пишет coins.transferred для любого зачисления монет пользователю.
+// Для начислений и поощрений id - это id начисления или поощрения, fromUser - кто их выдал
+func (r *EntityRepo) writeCoinsTransferred(ctx context.Context, db interfaces.DB, id, from, to string, amount int, message string) error {
+	return r.writeOutbox(ctx, db, entities.EventCoinsTransferred, to, entities.CoinsTransferredEvent{
+		TransferID: id, FromUser: from, ToUser: to, Amount: amount, Message: message,
+	})
+}
+
+// ClaimOutboxEvents забирает до limit неопубликованных событий и откладывает их повтор на lease.
+// Если реплика упадёт, не успев отметить события, их заберёт другая после истечения lease
+func (r *EntityRepo) ClaimOutboxEvents(ctx context.Context, limit int, lease time.Duration) ([]entities.OutboxEvent, error) {
+	// Подзапрос собирается с плейсхолдерами ?, нумерацию проставит внешний запрос
+	due, dueArgs, _ := sq.Select("id").
+		From("outbox_events").
+		Where(sq.Eq{"published_at": nil}).
+		Where("next_attempt_at <= CURRENT_TIMESTAMP").
+		OrderBy("id").
+		Limit(uint64(limit)).
+		Suffix("FOR UPDATE SKIP LOCKED").
+		ToSql()
+
+	q, args, _ := r.builder.Update("outbox_events").
+		Set("attempts", sq.Expr("attempts + 1")).
+		Set("next_attempt_at", time.Now().Add(lease)).
+		Where(sq.Expr("id IN ("+due+")", dueArgs...)).
+		Suffix("RETURNING id, event_type, event_key, payload::text, created_at, attempts").
+		ToSql()
+
+	rows, err := r.db.Query(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim outbox events: %v", err)
+	}
+	defer rows.Close()
+
+	var res []entities.OutboxEvent
+	for rows.Next() {
+		var e entities.OutboxEvent
+		var payload string
+		err := rows.Scan(&e.ID, &e.Type, &e.Key, &payload, &e.CreatedAt, &e.Attempts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan outbox event: %v", err)
+		}
+		e.Payload = json.RawMessage(payload)
+		res = append(res, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to claim outbox events: %v", err)
+	}
+
+	// RETURNING не сохраняет порядок подзапроса
+	slices.SortFunc(res, func(a, b entities.OutboxEvent) int { return cmp.Compare(a.ID, b.ID) })
+	return res, nil
+}
+
+func (r *EntityRepo) MarkOutboxPublished(ctx context.Context, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	q, args, _ := r.builder.Update("outbox_events").
+		Set("published_at", sq.Expr("CURRENT_TIMESTAMP")).
+		Set("last_error", nil).
+		Where(sq.Eq{"id": ids}).
+		ToSql()
+	_, err := r.db.Exec(ctx, q, args...)
+	if err != nil {
+		return fmt.Errorf("failed to mark outbox events published: %v", err)
+	}
+	return nil
+}
+
+func (r *EntityRepo) RetryOutboxEvent(ctx context.Context, id int64, lastError string, nextAttemptAt time.Time) error {
+	q, args, _ := r.builder.Update("outbox_events").
+		Set("last_error", lastError).
+		Set("next_attempt_at", nextAttemptAt).
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	_, err := r.db.Exec(ctx, q, args...)
+	if err != nil {
+		return fmt.Errorf("failed to reschedule outbox event: %v", err)
+	}
+	return nil
+}
+
+// DeleteOutboxPublished удаляет события, опубликованные раньше before
+func (r *EntityRepo) DeleteOutboxPublished(ctx context.Context, before time.Time) (int, error) {
+	q, args, _ := r.builder.Delete("outbox_events").
+		Where(sq.Lt{"published_at": before}).
+		ToSql()
+	tag, err := r.db.Exec(ctx, q, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete published outbox events: %v", err)
+	}
+	return int(tag.RowsAffected()), nil
+}
@@ -0,0 +1,386 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"ttavito/domain/entities"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+)
+
+// Сколько просроченных переводов обрабатывается за один проход
+const expirePendingBatchSize = 500
+
+var pendingTransferColumns = []string{
+	"id::text", "sender_username", "receiver_username", "amount", "COALESCE(message, '')",
+	"status", "COALESCE(transfer_id::text, '')", "created_at", "expires_at",
+}
+
+func scanPendingTransfer(row pgx.Row) (*entities.PendingTransfer, error) {
+	var p entities.PendingTransfer
+	err := row.Scan(&p.ID, &p.FromUser, &p.ToUser, &p.Amount, &p.Message,
+		&p.Status, &p.TransferID, &p.CreatedAt, &p.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// CreatePendingTransfer замораживает сумму у отправителя до решения получателя
+func (r *EntityRepo) CreatePendingTransfer(ctx context.Context, senderUsername string, req entities.SendCoinRequest, expiresAt time.Time) (res *entities.PendingTransfer, err error) {
+	if senderUsername == req.ToUser {
+		return nil, entities.ErrTransferToSelf
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %v", err)
+	}
+
+	defer func() {
+		if err != nil {
+			slog.Error("Failed to create pending transfer", "error", err)
+			tx.Rollback(ctx)
+		} else {
+			err = tx.Commit(ctx)
+			if err == nil {
+				slog.Info("success create pending transfer", "id", res.ID)
+			}
+		}
+	}()
+
+	q, args, _ := r.builder.Select("balance").
+		From("users").
+		Where(sq.Eq{"username": senderUsername}).
+		Suffix("FOR UPDATE").
+		ToSql()
+
+	var senderBalance int
+	err = tx.QueryRow(ctx, q, args...).Scan(&senderBalance)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get sender's balance: %v", err)
+	}
+
+	err = r.checkTransferNotFrozen(ctx, tx, senderUsername, req.ToUser)
+	if err != nil {
+		return nil, err
+	}
+
+	if senderBalance < req.Amount {
+		return nil, entities.ErrNotEnoughBalance
+	}
+
+	err = r.ensureActiveUser(ctx, tx, req.ToUser)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.checkTransferPolicy(ctx, tx, senderUsername, req.ToUser, req.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	q, args, _ = r.builder.Update("users").
+		Set("balance", sq.Expr("balance - ?", req.Amount)).
+		Where(sq.Eq{"username": senderUsername}).
+		ToSql()
+	_, err = tx.Exec(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hold sender's balance: %v", err)
+	}
+
+	err = r.debitLots(ctx, tx, senderUsername, req.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	var message *string
+	if req.Message != "" {
+		message = &req.Message
+	}
+
+	q, args, _ = r.builder.Insert("pending_transfers").
+		Columns("sender_username", "receiver_username", "amount", "message", "expires_at").
+		Values(senderUsername, req.ToUser, req.Amount, message, expiresAt).
+		Suffix("RETURNING " + strings.Join(pendingTransferColumns, ", ")).
+		ToSql()
+
+	res, err = scanPendingTransfer(tx.QueryRow(ctx, q, args...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to add pending transfer: %v", err)
+	}
+
+	err = r.writeAudit(ctx, tx, senderUsername, entities.AuditTransferPendingCreate, req.ToUser,
+		auditPayload{"pendingId": res.ID, "amount": req.Amount, "message": req.Message, "expiresAt": expiresAt})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (r *EntityRepo) GetPendingTransfers(ctx context.Context, username string) (*entities.PendingTransfersResponse, error) {
+	var res entities.PendingTransfersResponse
+	var err error
+
+	res.Incoming, err = r.listPendingTransfers(ctx, sq.Eq{"receiver_username": username, "status": entities.PendingStatusPending})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get incoming pending transfers: %w", err)
+	}
+
+	res.Outgoing, err = r.listPendingTransfers(ctx, sq.Eq{"sender_username": username, "status": entities.PendingStatusPending})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get outgoing pending transfers: %w", err)
+	}
+
+	return &res, nil
+}
+
+func (r *EntityRepo) listPendingTransfers(ctx context.Context, where sq.Eq) ([]entities.PendingTransfer, error) {
+	q, args, _ := r.builder.Select(pendingTransferColumns...).
+		From("pending_transfers").
+		Where(where).
+		OrderBy("created_at").
+		ToSql()
+
+	rows, err := r.db.Query(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []entities.PendingTransfer
+	for rows.Next() {
+		p, err := scanPendingTransfer(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, *p)
+	}
+
+	return res, rows.Err()
+}
+
+// AcceptPendingTransfer зачисляет замороженную сумму получателю и создаёт обычный перевод
+func (r *EntityRepo) AcceptPendingTransfer(ctx context.Context, username, id string) (*entities.PendingTransfer, error) {
+	return r.resolvePendingTransfer(ctx, username, id, entities.PendingStatusAccepted)
+}
+
+// DeclinePendingTransfer возвращает замороженную сумму отправителю
+func (r *EntityRepo) DeclinePendingTransfer(ctx context.Context, username, id string) (*entities.PendingTransfer, error) {
+	return r.resolvePendingTransfer(ctx, username, id, entities.PendingStatusDeclined)
+}
+
+func (r *EntityRepo) resolvePendingTransfer(ctx context.Context, username, id, status string) (res *entities.PendingTransfer, err error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %v", err)
+	}
+
+	defer func() {
+		if err != nil {
+			slog.Error("Failed to resolve pending transfer", "id", id, "status", status, "error", err)
+			tx.Rollback(ctx)
+		} else {
+			err = tx.Commit(ctx)
+			if err == nil {
+				slog.Info("success resolve pending transfer", "id", id, "status", status)
+			}
+		}
+	}()
+
+	q, args, _ := r.builder.Select(pendingTransferColumns...).
+		From("pending_transfers").
+		Where(sq.Eq{"id": id, "receiver_username": username}).
+		Suffix("FOR UPDATE").
+		ToSql()
+
+	res, err = scanPendingTransfer(tx.QueryRow(ctx, q, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, entities.ErrPendingTransferNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch pending transfer: %v", err)
+	}
+
+	if res.Status != entities.PendingStatusPending {
+		return nil, entities.ErrPendingTransferResolved
+	}
+
+	// Просроченный перевод вернёт отправителю фоновый процесс
+	if status == entities.PendingStatusAccepted && !res.ExpiresAt.After(time.Now()) {
+		return nil, entities.ErrPendingTransferExpired
+	}
+
+	update := r.builder.Update("pending_transfers").
+		Set("status", status).
+		Set("resolved_at", sq.Expr("CURRENT_TIMESTAMP")).
+		Where(sq.Eq{"id": id})
+
+	if status == entities.PendingStatusAccepted {
+		err = r.checkNotFrozen(ctx, tx, res.ToUser, true)
+		if err != nil {
+			return nil, err
+		}
+		res.TransferID, err = r.completePendingTransfer(ctx, tx, res)
+		if err != nil {
+			return nil, err
+		}
+		update = update.Set("transfer_id", res.TransferID)
+	} else {
+		err = r.refundPendingTransfer(ctx, tx, res)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	q, args, _ = update.ToSql()
+	_, err = tx.Exec(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update pending transfer: %v", err)
+	}
+
+	action := entities.AuditTransferPendingDecline
+	if status == entities.PendingStatusAccepted {
+		action = entities.AuditTransferPendingAccept
+	}
+	err = r.writeAudit(ctx, tx, username, action, res.FromUser,
+		auditPayload{"pendingId": res.ID, "amount": res.Amount, "transferId": res.TransferID})
+	if err != nil {
+		return nil, err
+	}
+
+	res.Status = status
+	return res, nil
+}
+
+func (r *EntityRepo) completePendingTransfer(ctx context.Context, tx pgx.Tx, p *entities.PendingTransfer) (string, error) {
+	q, args, _ := r.builder.Update("users").
+		Set("balance", sq.Expr("balance + ?", p.Amount)).
+		Where(sq.Eq{"username": p.ToUser}).
+		ToSql()
+	_, err := tx.Exec(ctx, q, args...)
+	if err != nil {
+		return "", fmt.Errorf("failed to update receiver's balance: %v", err)
+	}
+
+	err = r.creditLot(ctx, tx, p.ToUser, p.Amount, lotSourceTransfer, time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	var message *string
+	if p.Message != "" {
+		message = &p.Message
+	}
+
+	q, args, _ = r.builder.Insert("transfers").
+		Columns("sender_username", "receiver_username", "amount", "message").
+		Values(p.FromUser, p.ToUser, p.Amount, message).
+		Suffix("RETURNING id::text").
+		ToSql()
+
+	var transferID string
+	err = tx.QueryRow(ctx, q, args...).Scan(&transferID)
+	if err != nil {
+		return "", fmt.Errorf("failed to add in transfers: %v", err)
+	}
+
+	err = r.writeCoinsTransferred(ctx, tx, transferID, p.FromUser, p.ToUser, p.Amount, p.Message)
+	if err != nil {
+		return "", err
+	}
+	return transferID, nil
+}
+
+func (r *EntityRepo) refundPendingTransfer(ctx context.Context, tx pgx.Tx, p *entities.PendingTransfer) error {
+	q, args, _ := r.builder.Update("users").
+		Set("balance", sq.Expr("balance + ?", p.Amount)).
+		Where(sq.Eq{"username": p.FromUser}).
+		ToSql()
+	_, err := tx.Exec(ctx, q, args...)
+	if err != nil {
+		return fmt.Errorf("failed to refund sender's balance: %v", err)
+	}
+
+	// Срок жизни считается от создания перевода, чтобы отклонённый перевод не продлевал монеты
+	return r.creditLot(ctx, tx, p.FromUser, p.Amount, lotSourceRefund, p.CreatedAt)
+}
+
+// ExpirePendingTransfers возвращает отправителям суммы просроченных переводов.
+// SKIP LOCKED позволяет запускать очистку на нескольких репликах одновременно.
+func (r *EntityRepo) ExpirePendingTransfers(ctx context.Context, now time.Time) (count int, err error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to start transaction: %v", err)
+	}
+
+	defer func() {
+		if err != nil {
+			slog.Error("Failed to expire pending transfers", "error", err)
+			tx.Rollback(ctx)
+		} else {
+			err = tx.Commit(ctx)
+		}
+	}()
+
+	q, args, _ := r.builder.Select(pendingTransferColumns...).
+		From("pending_transfers").
+		Where(sq.Eq{"status": entities.PendingStatusPending}).
+		Where(sq.LtOrEq{"expires_at": now}).
+		OrderBy("expires_at").
+		Limit(expirePendingBatchSize).
+		Suffix("FOR UPDATE SKIP LOCKED").
+		ToSql()
+
+	rows, err := tx.Query(ctx, q, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch expired pending transfers: %v", err)
+	}
+
+	var expired []*entities.PendingTransfer
+	for rows.Next() {
+		var p *entities.PendingTransfer
+		p, err = scanPendingTransfer(rows)
+		if err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan pending transfer: %v", err)
+		}
+		expired = append(expired, p)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to fetch expired pending transfers: %v", err)
+	}
+
+	for _, p := range expired {
+		err = r.refundPendingTransfer(ctx, tx, p)
+		if err != nil {
+			return 0, err
+		}
+
+		q, args, _ = r.builder.Update("pending_transfers").
+			Set("status", entities.PendingStatusExpired).
+			Set("resolved_at", sq.Expr("CURRENT_TIMESTAMP")).
+			Where(sq.Eq{"id": p.ID}).
+			ToSql()
+		_, err = tx.Exec(ctx, q, args...)
+		if err != nil {
+			return 0, fmt.Errorf("failed to expire pending transfer: %v", err)
+		}
+
+		err = r.writeAudit(ctx, tx, entities.AuditActorSystem, entities.AuditTransferPendingExpire, p.FromUser,
+			auditPayload{"pendingId": p.ID, "toUser": p.ToUser, "amount": p.Amount})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return len(expired), nil
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"ttavito/domain/interfaces"
+	"ttavito/policy"
+)
+
+// Исходящие переводы отправителя за последнюю неделю вместе с ещё не подтверждёнными
+// (их сумма уже заморожена). Сторно не считается: это действие администратора.
+// Кулдаун смотрит только на эту неделю, поэтому длиннее недели он не бывает.
+const transferStatsQuery = `
+SELECT
+	COALESCE(SUM(amount) FILTER (WHERE created_at > LOCALTIMESTAMP - INTERVAL '1 day'), 0),
+	COALESCE(SUM(amount), 0),
+	COALESCE(SUM(amount) FILTER (WHERE receiver_username = $2 AND created_at > LOCALTIMESTAMP - INTERVAL '1 day'), 0),
+	EXTRACT(EPOCH FROM LOCALTIMESTAMP - MAX(created_at) FILTER (WHERE receiver_username = $2))::float8
+FROM (
+	SELECT receiver_username, amount, created_at
+	FROM transfers
+	WHERE sender_username = $1 AND reversal_of IS NULL
+	UNION ALL
+	SELECT receiver_username, amount, created_at::timestamp
+	FROM pending_transfers
+	WHERE sender_username = $1 AND status = 'pending'
+) t
+WHERE created_at > LOCALTIMESTAMP - INTERVAL '7 days'`
+
+// checkTransferPolicy вызывается после блокировки строки отправителя,
+// поэтому параллельные переводы одного отправителя видят суммы друг друга
+func (r *EntityRepo) checkTransferPolicy(ctx context.Context, db interfaces.DB, sender, recipient string, amount int) error {
+	if !r.transferPolicy.Enabled() {
+		return nil
+	}
+
+	var stats policy.Stats
+	var sinceLast *float64
+	err := db.QueryRow(ctx, transferStatsQuery, sender, recipient).
+		Scan(&stats.SentLastDay, &stats.SentLastWeek, &stats.SentToRecipientLastDay, &sinceLast)
+	if err != nil {
+		return fmt.Errorf("failed to get transfer stats: %v", err)
+	}
+
+	if sinceLast != nil {
+		d := time.Duration(*sinceLast * float64(time.Second))
+		stats.SinceLastToRecipient = &d
+	}
+
+	return r.transferPolicy.Check(amount, stats)
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"ttavito/domain/entities"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+)
+
+// GetProfile собирает профиль из user_profiles и справочника. Без профиля
+// отображаемым именем считается username
+func (r *EntityRepo) GetProfile(ctx context.Context, username string) (*entities.Profile, error) {
+	q, args, _ := r.builder.Select(
+		"u.username",
+		"COALESCE(p.display_name, u.username)",
+		"COALESCE(p.title, '')",
+		"COALESCE(dm.department_name, '')",
+		"COALESCE(tm.team_name, '')",
+		"COALESCE(u.manager_username, '')",
+		"p.avatar_updated_at",
+	).
+		From("users u").
+		LeftJoin("user_profiles p ON p.username = u.username").
+		LeftJoin("department_members dm ON dm.username = u.username").
+		LeftJoin("team_members tm ON tm.username = u.username").
+		Where(sq.Eq{"u.username": username}).
+		ToSql()
+
+	var p entities.Profile
+	err := r.db.QueryRow(ctx, q, args...).Scan(&p.Username, &p.DisplayName, &p.Title, &p.Department, &p.Team, &p.Manager, &p.AvatarUpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, entities.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch profile: %v", err)
+	}
+
+	return &p, nil
+}
+
+// UpdateProfile заменяет отображаемое имя и должность. Пустое значение сохраняется как NULL
+func (r *EntityRepo) UpdateProfile(ctx context.Context, username string, req entities.UpdateProfileRequest) error {
+	q, args, _ := r.builder.Insert("user_profiles").
+		Columns("username", "display_name", "title").
+		Values(username, nullIfEmpty(req.DisplayName), nullIfEmpty(req.Title)).
+		Suffix("ON CONFLICT (username) DO UPDATE SET display_name = EXCLUDED.display_name, title = EXCLUDED.title, updated_at = CURRENT_TIMESTAMP").
+		ToSql()
+
+	_, err := r.db.Exec(ctx, q, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update profile: %v", err)
+	}
+
+	slog.Info("success update profile", "username", username)
+	return nil
+}
+
+// SetAvatar запоминает ключ загруженного аватара. Сам файл хранится в FileStore
+func (r *EntityRepo) SetAvatar(ctx context.Context, username, key, contentType string) error {
+	q, args, _ := r.builder.Insert("user_profiles").
+		Columns("username", "avatar_key", "avatar_content_type", "avatar_updated_at").
+		Values(username, key, contentType, sq.Expr("CURRENT_TIMESTAMP")).
+		Suffix("ON CONFLICT (username) DO UPDATE SET avatar_key = EXCLUDED.avatar_key, " +
+			"avatar_content_type = EXCLUDED.avatar_content_type, avatar_updated_at = EXCLUDED.avatar_updated_at, updated_at = CURRENT_TIMESTAMP").
+		ToSql()
+
+	_, err := r.db.Exec(ctx, q, args...)
+	if err != nil {
+		return fmt.Errorf("failed to set avatar: %v", err)
+	}
+
+	slog.Info("success set avatar", "username", username)
+	return nil
+}
+
+func (r *EntityRepo) GetAvatarKey(ctx context.Context, username string) (key, contentType string, err error) {
+	q, args, _ := r.builder.Select("avatar_key", "avatar_content_type").
+		From("user_profiles").
+		Where(sq.Eq{"username": username}).
+		Where(sq.NotEq{"avatar_key": nil}).
+		ToSql()
+
+	err = r.db.QueryRow(ctx, q, args...).Scan(&key, &contentType)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", "", entities.ErrAvatarNotFound
+		}
+		return "", "", fmt.Errorf("failed to fetch avatar: %v", err)
+	}
+
+	return key, contentType, nil
+}
+
+func nullIfEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"ttavito/domain/entities"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+)
+
+// ReverseTransfer создаёт компенсирующий перевод от получателя обратно отправителю.
+// Без allowPartial операция откатывается, если получатель уже потратил монеты,
+// с allowPartial возвращается столько, сколько есть на балансе получателя.
+func (r *EntityRepo) ReverseTransfer(ctx context.Context, admin string, req entities.ReverseTransferRequest) (res *entities.ReversalResponse, err error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %v", err)
+	}
+
+	defer func() {
+		if err != nil {
+			slog.Error("Failed to reverse transfer", "transfer_id", req.TransferID, "error", err)
+			tx.Rollback(ctx)
+		} else {
+			err = tx.Commit(ctx)
+			if err == nil {
+				slog.Info("success reverse transfer", "transfer_id", req.TransferID)
+			}
+		}
+	}()
+
+	// Блокируем исходный перевод, чтобы два сторно не прошли параллельно
+	q, args, _ := r.builder.Select("sender_username", "receiver_username", "amount", "reversal_of IS NOT NULL").
+		From("transfers").
+		Where(sq.Eq{"id": req.TransferID}).
+		Suffix("FOR UPDATE").
+		ToSql()
+
+	var sender, receiver string
+	var amount int
+	var isReversal bool
+	err = tx.QueryRow(ctx, q, args...).Scan(&sender, &receiver, &amount, &isReversal)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, entities.ErrTransferNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch transfer: %v", err)
+	}
+
+	if isReversal {
+		return nil, entities.ErrCannotReverseReversal
+	}
+
+	q, args, _ = r.builder.Select("COALESCE(SUM(amount), 0)").
+		From("transfers").
+		Where(sq.Eq{"reversal_of": req.TransferID}).
+		ToSql()
+
+	var reversed int
+	err = tx.QueryRow(ctx, q, args...).Scan(&reversed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch reversed amount: %v", err)
+	}
+
+	remaining := amount - reversed
+	if remaining <= 0 {
+		return nil, entities.ErrTransferAlreadyReversed
+	}
+
+	balances, err := r.lockUsers(ctx, tx, sender, receiver)
+	if err != nil {
+		return nil, err
+	}
+	receiverBalance := balances[receiver]
+
+	// Монеты возвращаются отправителю, поэтому он проверяется как получатель перевода
+	err = r.ensureActiveUser(ctx, tx, sender)
+	if err != nil {
+		return nil, err
+	}
+	err = r.checkNotFrozen(ctx, tx, sender, true)
+	if err != nil {
+		return nil, err
+	}
+
+	reverseAmount := remaining
+	if receiverBalance < remaining {
+		if !req.AllowPartial || receiverBalance == 0 {
+			return nil, entities.ErrReversalInsufficientFunds
+		}
+		reverseAmount = receiverBalance
+	}
+
+	q, args, _ = r.builder.Update("users").
+		Set("balance", sq.Expr("balance - ?", reverseAmount)).
+		Where(sq.Eq{"username": receiver}).
+		ToSql()
+	_, err = tx.Exec(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update receiver's balance: %v", err)
+	}
+
+	err = r.debitLots(ctx, tx, receiver, reverseAmount)
+	if err != nil {
+		return nil, err
+	}
+
+	q, args, _ = r.builder.Update("users").
+		Set("balance", sq.Expr("balance + ?", reverseAmount)).
+		Where(sq.Eq{"username": sender}).
+		ToSql()
+	_, err = tx.Exec(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update sender's balance: %v", err)
+	}
+
+	err = r.creditLot(ctx, tx, sender, reverseAmount, lotSourceReversal, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	q, args, _ = r.builder.Insert("transfers").
+		Columns("sender_username", "receiver_username", "amount", "reversal_of").
+		Values(receiver, sender, reverseAmount, req.TransferID).
+		Suffix("RETURNING id::text").
+		ToSql()
+
+	res = &entities.ReversalResponse{
+		ReversalOf: req.TransferID,
+		FromUser:   receiver,
+		ToUser:     sender,
+		Amount:     reverseAmount,
+		Partial:    reverseAmount < remaining,
+	}
+	err = tx.QueryRow(ctx, q, args...).Scan(&res.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add reversal in transfers: %v", err)
+	}
+
+	err = r.writeCoinsTransferred(ctx, tx, res.ID, receiver, sender, reverseAmount, "")
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.writeAudit(ctx, tx, admin, entities.AuditAdminReverse, req.TransferID, auditPayload{
+		"reversalId": res.ID, "fromUser": receiver, "toUser": sender, "amount": reverseAmount, "partial": res.Partial,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
@@ -0,0 +1,265 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"ttavito/domain/entities"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+)
+
+var scheduledTransferColumns = []string{
+	"id::text", "sender_username", "receiver_username", "amount", "COALESCE(message, '')",
+	"COALESCE(cron_expr, '')", "status", "next_run_at", "last_run_at", "COALESCE(last_status, '')",
+	"COALESCE(last_error, '')", "failure_count", "created_at",
+}
+
+func scanScheduledTransfer(row pgx.Row) (*entities.ScheduledTransfer, error) {
+	var s entities.ScheduledTransfer
+	err := row.Scan(&s.ID, &s.FromUser, &s.ToUser, &s.Amount, &s.Message,
+		&s.Cron, &s.Status, &s.NextRunAt, &s.LastRunAt, &s.LastStatus,
+		&s.LastError, &s.FailureCount, &s.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *EntityRepo) CreateScheduledTransfer(ctx context.Context, senderUsername string, req entities.ScheduledTransferRequest, nextRunAt time.Time) (*entities.ScheduledTransfer, error) {
+	if senderUsername == req.ToUser {
+		return nil, entities.ErrTransferToSelf
+	}
+
+	err := r.ensureActiveUser(ctx, r.db, req.ToUser)
+	if err != nil {
+		return nil, err
+	}
+
+	var message, cronExpr *string
+	if req.Message != "" {
+		message = &req.Message
+	}
+	if req.Cron != "" {
+		cronExpr = &req.Cron
+	}
+
+	q, args, _ := r.builder.Insert("scheduled_transfers").
+		Columns("sender_username", "receiver_username", "amount", "message", "cron_expr", "next_run_at").
+		Values(senderUsername, req.ToUser, req.Amount, message, cronExpr, nextRunAt).
+		Suffix("RETURNING " + strings.Join(scheduledTransferColumns, ", ")).
+		ToSql()
+
+	res, err := scanScheduledTransfer(r.db.QueryRow(ctx, q, args...))
+	if err != nil {
+		slog.Error("Failed to create scheduled transfer", "error", err)
+		return nil, fmt.Errorf("failed to add scheduled transfer: %v", err)
+	}
+
+	slog.Info("success create scheduled transfer", "id", res.ID)
+	return res, nil
+}
+
+func (r *EntityRepo) GetScheduledTransfers(ctx context.Context, username string) ([]entities.ScheduledTransfer, error) {
+	q, args, _ := r.builder.Select(scheduledTransferColumns...).
+		From("scheduled_transfers").
+		Where(sq.Eq{"sender_username": username}).
+		OrderBy("created_at").
+		ToSql()
+
+	return r.queryScheduledTransfers(ctx, q, args)
+}
+
+func (r *EntityRepo) GetScheduledTransfer(ctx context.Context, username, id string) (*entities.ScheduledTransfer, error) {
+	q, args, _ := r.builder.Select(scheduledTransferColumns...).
+		From("scheduled_transfers").
+		Where(sq.Eq{"id": id, "sender_username": username}).
+		ToSql()
+
+	res, err := scanScheduledTransfer(r.db.QueryRow(ctx, q, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, entities.ErrScheduledTransferNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch scheduled transfer: %v", err)
+	}
+	return res, nil
+}
+
+// UpdateScheduledTransferStatus меняет статус, только если он не изменился с момента чтения
+func (r *EntityRepo) UpdateScheduledTransferStatus(ctx context.Context, id, fromStatus, toStatus string, nextRunAt *time.Time) error {
+	update := r.builder.Update("scheduled_transfers").
+		Set("status", toStatus).
+		Where(sq.Eq{"id": id, "status": fromStatus})
+	if nextRunAt != nil {
+		update = update.Set("next_run_at", *nextRunAt)
+	}
+
+	q, args, _ := update.ToSql()
+	tag, err := r.db.Exec(ctx, q, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update scheduled transfer: %v", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return entities.ErrInvalidScheduleTransition
+	}
+
+	slog.Info("success update scheduled transfer", "id", id, "status", toStatus)
+	return nil
+}
+
+func (r *EntityRepo) GetDueScheduledTransfers(ctx context.Context, now time.Time, limit int) ([]entities.ScheduledTransfer, error) {
+	q, args, _ := r.builder.Select(scheduledTransferColumns...).
+		From("scheduled_transfers").
+		Where(sq.Eq{"status": entities.ScheduleStatusActive}).
+		Where(sq.LtOrEq{"next_run_at": now}).
+		OrderBy("next_run_at").
+		Limit(uint64(limit)).
+		ToSql()
+
+	return r.queryScheduledTransfers(ctx, q, args)
+}
+
+func (r *EntityRepo) queryScheduledTransfers(ctx context.Context, q string, args []any) ([]entities.ScheduledTransfer, error) {
+	rows, err := r.db.Query(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scheduled transfers: %v", err)
+	}
+	defer rows.Close()
+
+	var res []entities.ScheduledTransfer
+	for rows.Next() {
+		s, err := scanScheduledTransfer(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan scheduled transfer: %v", err)
+		}
+		res = append(res, *s)
+	}
+	return res, rows.Err()
+}
+
+// RunScheduledTransfer выполняет один запуск расписания. Перевод делается в точке сохранения,
+// поэтому нехватка монет не откатывает запись о запуске. nextRunAt == nil означает,
+// что запусков больше не будет. Если расписание уже обработала другая реплика, возвращается nil.
+func (r *EntityRepo) RunScheduledTransfer(ctx context.Context, s entities.ScheduledTransfer, now time.Time, nextRunAt *time.Time) (run *entities.ScheduledTransferRun, err error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %v", err)
+	}
+
+	defer func() {
+		if err != nil {
+			slog.Error("Failed to run scheduled transfer", "id", s.ID, "error", err)
+			tx.Rollback(ctx)
+		} else {
+			err = tx.Commit(ctx)
+		}
+	}()
+
+	q, args, _ := r.builder.Select("1").
+		From("scheduled_transfers").
+		Where(sq.Eq{"id": s.ID, "status": entities.ScheduleStatusActive}).
+		Where(sq.LtOrEq{"next_run_at": now}).
+		Suffix("FOR UPDATE SKIP LOCKED").
+		ToSql()
+
+	var locked int
+	err = tx.QueryRow(ctx, q, args...).Scan(&locked)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to lock scheduled transfer: %v", err)
+	}
+
+	run = &entities.ScheduledTransferRun{
+		ScheduleID: s.ID,
+		RunAt:      now,
+		Status:     entities.ScheduleRunSuccess,
+	}
+
+	sp, err := tx.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create savepoint: %v", err)
+	}
+
+	transferID, transferErr := r.transfer(ctx, sp, s.FromUser, s.ToUser, s.Amount, s.Message)
+	if transferErr != nil {
+		sp.Rollback(ctx)
+		var violation *entities.PolicyViolation
+		switch {
+		case errors.Is(transferErr, entities.ErrNotEnoughBalance):
+			run.Status = entities.ScheduleRunInsufficientBalance
+		case errors.As(transferErr, &violation):
+			run.Status = entities.ScheduleRunPolicyViolation
+		case errors.Is(transferErr, entities.ErrAccountFrozen), errors.Is(transferErr, entities.ErrRecipientFrozen):
+			run.Status = entities.ScheduleRunAccountFrozen
+		default:
+			run.Status = entities.ScheduleRunFailed
+		}
+		run.Error = transferErr.Error()
+	} else {
+		err = sp.Commit(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to release savepoint: %v", err)
+		}
+		run.TransferID = transferID
+	}
+
+	var runTransferID, runError *string
+	if run.TransferID != "" {
+		runTransferID = &run.TransferID
+	}
+	if run.Error != "" {
+		runError = &run.Error
+	}
+
+	q, args, _ = r.builder.Insert("scheduled_transfer_runs").
+		Columns("schedule_id", "run_at", "status", "transfer_id", "error").
+		Values(s.ID, now, run.Status, runTransferID, runError).
+		ToSql()
+	_, err = tx.Exec(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add scheduled transfer run: %v", err)
+	}
+
+	status := entities.ScheduleStatusActive
+	if nextRunAt == nil {
+		status = entities.ScheduleStatusCompleted
+		if run.Status != entities.ScheduleRunSuccess && s.Cron == "" {
+			status = entities.ScheduleStatusFailed
+		}
+	}
+
+	update := r.builder.Update("scheduled_transfers").
+		Set("status", status).
+		Set("next_run_at", nextRunAt).
+		Set("last_run_at", now).
+		Set("last_status", run.Status).
+		Set("last_error", runError).
+		Where(sq.Eq{"id": s.ID})
+	if run.Status != entities.ScheduleRunSuccess {
+		update = update.Set("failure_count", sq.Expr("failure_count + 1"))
+	}
+
+	q, args, _ = update.ToSql()
+	_, err = tx.Exec(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update scheduled transfer: %v", err)
+	}
+
+	err = r.writeAudit(ctx, tx, entities.AuditActorSystem, entities.AuditTransferScheduledRun, s.FromUser, auditPayload{
+		"scheduleId": s.ID, "toUser": s.ToUser, "amount": s.Amount,
+		"status": run.Status, "transferId": run.TransferID, "error": run.Error,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return run, nil
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"ttavito/domain/entities"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// SearchUsers ищет получателей перевода. Сначала идут совпадения по началу логина или имени,
+// затем похожие по триграммам (оператор % с порогом pg_trgm.similarity_threshold).
+// Уволенные (deactivated_at из миграции увольнений) и сам пользователь в выдачу не попадают.
+func (r *EntityRepo) SearchUsers(ctx context.Context, requester string, req entities.UserSearchRequest) (*entities.UserSearchResponse, error) {
+	prefix := likeEscaper.Replace(req.Query) + "%"
+
+	q, args, _ := r.builder.Select("u.username", "COALESCE(p.display_name, u.username)").
+		From("users u").
+		LeftJoin("user_profiles p ON p.username = u.username").
+		Where(sq.Eq{"u.deactivated_at": nil}).
+		Where(sq.NotEq{"u.username": requester}).
+		Where(sq.Or{
+			sq.Expr("u.username ILIKE ?", prefix),
+			sq.Expr("p.display_name ILIKE ?", prefix),
+			sq.Expr("u.username % ?", req.Query),
+			sq.Expr("p.display_name % ?", req.Query),
+		}).
+		// Без профиля display_name - NULL, и без COALESCE всё условие стало бы NULL,
+		// а NULL при DESC идёт первым
+		OrderByClause("(u.username ILIKE ? OR COALESCE(p.display_name ILIKE ?, false)) DESC", prefix, prefix).
+		OrderByClause("GREATEST(similarity(u.username, ?), COALESCE(similarity(p.display_name, ?), 0)) DESC", req.Query, req.Query).
+		OrderBy("u.username").
+		// Лишняя строка показывает, есть ли следующая страница
+		Limit(uint64(req.Limit) + 1).
+		Offset(uint64(req.Offset)).
+		ToSql()
+
+	rows, err := r.db.Query(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search users: %v", err)
+	}
+	defer rows.Close()
+
+	res := &entities.UserSearchResponse{Users: []entities.UserSearchResult{}}
+	for rows.Next() {
+		var u entities.UserSearchResult
+		if err := rows.Scan(&u.Username, &u.DisplayName); err != nil {
+			return nil, fmt.Errorf("failed to scan user: %v", err)
+		}
+		res.Users = append(res.Users, u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to search users: %v", err)
+	}
+
+	if len(res.Users) > req.Limit {
+		res.Users = res.Users[:req.Limit]
+		next := req.Offset + req.Limit
+		res.NextOffset = &next
+	}
+
+	return res, nil
+}
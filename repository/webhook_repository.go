@@ -0,0 +1,357 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"ttavito/domain/entities"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+)
+
+// Совпадает с размером колонки webhook_deliveries.last_error
+const maxWebhookErrorLength = 500
+
+var webhookColumns = []string{"id::text", "url", "event_types", "created_by", "created_at"}
+
+func scanWebhook(row pgx.Row) (*entities.WebhookSubscription, error) {
+	var s entities.WebhookSubscription
+	err := row.Scan(&s.ID, &s.URL, &s.EventTypes, &s.CreatedBy, &s.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+var webhookDeliveryColumns = []string{
+	"d.id::text", "d.subscription_id::text", "d.event_id", "d.event_type", "d.status", "d.attempts",
+	"CASE WHEN d.status = 'pending' THEN d.next_attempt_at END", "COALESCE(d.last_status_code, 0)",
+	"COALESCE(d.last_error, '')", "d.created_at", "d.delivered_at",
+}
+
+func scanWebhookDelivery(row pgx.Row, extra ...any) (*entities.WebhookDelivery, error) {
+	var d entities.WebhookDelivery
+	dst := []any{&d.ID, &d.SubscriptionID, &d.EventID, &d.EventType, &d.Status, &d.Attempts,
+		&d.NextAttemptAt, &d.LastStatusCode, &d.LastError, &d.CreatedAt, &d.DeliveredAt}
+	err := row.Scan(append(dst, extra...)...)
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+func (r *EntityRepo) CreateWebhook(ctx context.Context, admin string, req entities.WebhookRequest) (res *entities.WebhookSubscription, err error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %v", err)
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		} else {
+			err = tx.Commit(ctx)
+			if err == nil {
+				slog.Info("success create webhook", "id", res.ID, "by", admin)
+			}
+		}
+	}()
+
+	q, args, _ := r.builder.Insert("webhook_subscriptions").
+		Columns("url", "event_types", "secret", "created_by").
+		Values(req.URL, req.EventTypes, req.Secret, admin).
+		Suffix("RETURNING " + strings.Join(webhookColumns, ", ")).
+		ToSql()
+
+	res, err = scanWebhook(tx.QueryRow(ctx, q, args...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to add webhook: %v", err)
+	}
+	res.Secret = req.Secret
+
+	err = r.writeAudit(ctx, tx, admin, entities.AuditAdminWebhookCreate, "",
+		auditPayload{"webhookId": res.ID, "url": res.URL, "eventTypes": res.EventTypes})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (r *EntityRepo) GetWebhooks(ctx context.Context) ([]entities.WebhookSubscription, error) {
+	q, args, _ := r.builder.Select(webhookColumns...).
+		From("webhook_subscriptions").
+		Where(sq.Eq{"deleted_at": nil}).
+		OrderBy("created_at").
+		ToSql()
+
+	rows, err := r.db.Query(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhooks: %v", err)
+	}
+	defer rows.Close()
+
+	res := []entities.WebhookSubscription{}
+	for rows.Next() {
+		s, err := scanWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook: %v", err)
+		}
+		res = append(res, *s)
+	}
+	return res, rows.Err()
+}
+
+// DeleteWebhook отключает подписку. Недоставленные события ей больше не отправляются
+// и остаются в журнале как dead
+func (r *EntityRepo) DeleteWebhook(ctx context.Context, admin, id string) (err error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %v", err)
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		} else {
+			err = tx.Commit(ctx)
+			if err == nil {
+				slog.Info("success delete webhook", "id", id, "by", admin)
+			}
+		}
+	}()
+
+	q, args, _ := r.builder.Update("webhook_subscriptions").
+		Set("deleted_at", sq.Expr("CURRENT_TIMESTAMP")).
+		Where(sq.Eq{"id": id, "deleted_at": nil}).
+		ToSql()
+	tag, err := tx.Exec(ctx, q, args...)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %v", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return entities.ErrWebhookNotFound
+	}
+
+	q, args, _ = r.builder.Update("webhook_deliveries").
+		Set("status", entities.WebhookDeliveryDead).
+		Set("last_error", "webhook deleted").
+		Where(sq.Eq{"subscription_id": id, "status": entities.WebhookDeliveryPending}).
+		ToSql()
+	_, err = tx.Exec(ctx, q, args...)
+	if err != nil {
+		return fmt.Errorf("failed to cancel webhook deliveries: %v", err)
+	}
+
+	return r.writeAudit(ctx, tx, admin, entities.AuditAdminWebhookDelete, "", auditPayload{"webhookId": id})
+}
+
+const enqueueWebhookDeliveriesQuery = `
+INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload)
+SELECT id, $1, $2, $3::jsonb
+FROM webhook_subscriptions
+WHERE deleted_at IS NULL AND $2::varchar = ANY(event_types)
+ON CONFLICT (subscription_id, event_id) DO NOTHING`
+
+// EnqueueWebhookDeliveries создаёт доставку события каждой подписке на его тип.
+// Повторно опубликованное событие доставок не дублирует
+func (r *EntityRepo) EnqueueWebhookDeliveries(ctx context.Context, e entities.OutboxEvent) (int, error) {
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode event: %v", err)
+	}
+
+	tag, err := r.db.Exec(ctx, enqueueWebhookDeliveriesQuery, e.ID, e.Type, string(payload))
+	if err != nil {
+		return 0, fmt.Errorf("failed to enqueue webhook deliveries: %v", err)
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+const claimWebhookDeliveriesQuery = `
+UPDATE webhook_deliveries d
+SET attempts = d.attempts + 1, next_attempt_at = $1
+FROM webhook_subscriptions s
+WHERE s.id = d.subscription_id AND d.id IN (
+    SELECT id FROM webhook_deliveries
+    WHERE status = 'pending' AND next_attempt_at <= CURRENT_TIMESTAMP
+    ORDER BY next_attempt_at
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING `
+
+// ClaimWebhookDeliveries забирает до limit доставок, которым пора уйти, и откладывает их повтор на lease
+func (r *EntityRepo) ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]entities.WebhookDelivery, error) {
+	q := claimWebhookDeliveriesQuery + strings.Join(webhookDeliveryColumns, ", ") + ", s.url, s.secret, d.payload::text"
+
+	rows, err := r.db.Query(ctx, q, time.Now().Add(lease), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %v", err)
+	}
+	defer rows.Close()
+
+	var res []entities.WebhookDelivery
+	for rows.Next() {
+		var url, secret, payload string
+		d, err := scanWebhookDelivery(rows, &url, &secret, &payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %v", err)
+		}
+		d.URL, d.Secret, d.Payload = url, secret, json.RawMessage(payload)
+		res = append(res, *d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %v", err)
+	}
+	return res, nil
+}
+
+func (r *EntityRepo) CompleteWebhookDelivery(ctx context.Context, id string, statusCode int) error {
+	q, args, _ := r.builder.Update("webhook_deliveries").
+		Set("status", entities.WebhookDeliveryDelivered).
+		Set("last_status_code", statusCode).
+		Set("last_error", nil).
+		Set("delivered_at", sq.Expr("CURRENT_TIMESTAMP")).
+		Where(sq.Eq{"id": id, "status": entities.WebhookDeliveryPending}).
+		ToSql()
+	_, err := r.db.Exec(ctx, q, args...)
+	if err != nil {
+		return fmt.Errorf("failed to complete webhook delivery: %v", err)
+	}
+	return nil
+}
+
+// FailWebhookDelivery записывает неудачную попытку. nextAttemptAt == nil - попытки кончились
+func (r *EntityRepo) FailWebhookDelivery(ctx context.Context, id string, statusCode int, lastError string, nextAttemptAt *time.Time) error {
+	if runes := []rune(lastError); len(runes) > maxWebhookErrorLength {
+		lastError = string(runes[:maxWebhookErrorLength])
+	}
+	var code *int
+	if statusCode != 0 {
+		code = &statusCode
+	}
+
+	update := r.builder.Update("webhook_deliveries").
+		Set("last_status_code", code).
+		Set("last_error", lastError).
+		Where(sq.Eq{"id": id, "status": entities.WebhookDeliveryPending})
+	if nextAttemptAt != nil {
+		update = update.Set("next_attempt_at", *nextAttemptAt)
+	} else {
+		update = update.Set("status", entities.WebhookDeliveryDead)
+	}
+
+	q, args, _ := update.ToSql()
+	_, err := r.db.Exec(ctx, q, args...)
+	if err != nil {
+		return fmt.Errorf("failed to record webhook delivery failure: %v", err)
+	}
+	return nil
+}
+
+// GetWebhookDeliveries - журнал доставок подписки, новые сверху. Доступен и для удалённой подписки
+func (r *EntityRepo) GetWebhookDeliveries(ctx context.Context, req entities.WebhookDeliveriesRequest) (*entities.WebhookDeliveriesResponse, error) {
+	q, args, _ := r.builder.Select("1").From("webhook_subscriptions").Where(sq.Eq{"id": req.SubscriptionID}).ToSql()
+	var exists int
+	err := r.db.QueryRow(ctx, q, args...).Scan(&exists)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, entities.ErrWebhookNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch webhook: %v", err)
+	}
+
+	query := r.builder.Select(webhookDeliveryColumns...).
+		From("webhook_deliveries d").
+		Where(sq.Eq{"d.subscription_id": req.SubscriptionID}).
+		OrderBy("d.created_at DESC").
+		Limit(uint64(req.Limit))
+	if req.Status != "" {
+		query = query.Where(sq.Eq{"d.status": req.Status})
+	}
+	q, args, _ = query.ToSql()
+
+	rows, err := r.db.Query(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook deliveries: %v", err)
+	}
+	defer rows.Close()
+
+	res := &entities.WebhookDeliveriesResponse{Deliveries: []entities.WebhookDelivery{}}
+	for rows.Next() {
+		d, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %v", err)
+		}
+		res.Deliveries = append(res.Deliveries, *d)
+	}
+	return res, rows.Err()
+}
+
+// RetryWebhookDelivery возвращает доставку из dead в очередь с новым счётчиком попыток
+func (r *EntityRepo) RetryWebhookDelivery(ctx context.Context, admin, id string) (res *entities.WebhookDelivery, err error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %v", err)
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		} else {
+			err = tx.Commit(ctx)
+			if err == nil {
+				slog.Info("success retry webhook delivery", "id", id, "by", admin)
+			}
+		}
+	}()
+
+	// Доставку удалённой подписки перезапускать некуда
+	q, args, _ := r.builder.Update("webhook_deliveries d").
+		Set("status", entities.WebhookDeliveryPending).
+		Set("attempts", 0).
+		Set("next_attempt_at", sq.Expr("CURRENT_TIMESTAMP")).
+		Where(sq.Eq{"d.id": id, "d.status": entities.WebhookDeliveryDead}).
+		Where("EXISTS (SELECT 1 FROM webhook_subscriptions s WHERE s.id = d.subscription_id AND s.deleted_at IS NULL)").
+		Suffix("RETURNING " + strings.Join(webhookDeliveryColumns, ", ")).
+		ToSql()
+
+	res, err = scanWebhookDelivery(tx.QueryRow(ctx, q, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		q, args, _ = r.builder.Select("s.deleted_at IS NOT NULL").
+			From("webhook_deliveries d").
+			Join("webhook_subscriptions s ON s.id = d.subscription_id").
+			Where(sq.Eq{"d.id": id}).
+			ToSql()
+		var deleted bool
+		err = tx.QueryRow(ctx, q, args...).Scan(&deleted)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, entities.ErrWebhookDeliveryNotFound
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch webhook delivery: %v", err)
+		}
+		if deleted {
+			return nil, entities.ErrWebhookNotFound
+		}
+		return nil, entities.ErrWebhookDeliveryNotDead
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to retry webhook delivery: %v", err)
+	}
+
+	err = r.writeAudit(ctx, tx, admin, entities.AuditAdminWebhookRetry, "",
+		auditPayload{"deliveryId": res.ID, "webhookId": res.SubscriptionID, "eventId": res.EventID})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
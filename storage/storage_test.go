@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"ttavito/domain/interfaces"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalStore(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	_, err = store.Get(ctx, "avatars/missing")
+	assert.ErrorIs(t, err, interfaces.ErrObjectNotFound)
+
+	err = store.Put(ctx, "avatars/abc", []byte("png"), "image/png")
+	assert.NoError(t, err)
+
+	data, err := store.Get(ctx, "avatars/abc")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("png"), data)
+
+	err = store.Put(ctx, "../escape", []byte("x"), "text/plain")
+	assert.Error(t, err)
+}
+
+func TestS3StorePut(t *testing.T) {
+	var authorization, contentType string
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		contentType = r.Header.Get("Content-Type")
+		body, _ = io.ReadAll(r.Body)
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.Equal(t, "/avatars/users/abc", r.URL.Path)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	store, err := NewS3Store(S3Config{Endpoint: server.URL, Bucket: "avatars", AccessKey: "key", SecretKey: "secret"})
+	assert.NoError(t, err)
+
+	err = store.Put(context.Background(), "users/abc", []byte("avatar-bytes"), "image/png")
+
+	assert.NoError(t, err)
+	// По http клиент подписывает тело по частям, сами данные идут внутри
+	assert.Contains(t, string(body), "avatar-bytes")
+	assert.Equal(t, "image/png", contentType)
+	assert.True(t, strings.HasPrefix(authorization, "AWS4-HMAC-SHA256 Credential=key/"), authorization)
+	assert.Contains(t, authorization, "/us-east-1/s3/aws4_request")
+}
+
+func TestS3StoreGet(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/avatars/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Last-Modified", time.Date(2025, time.February, 14, 10, 30, 0, 0, time.UTC).Format(http.TimeFormat))
+		w.Header().Set("ETag", `"etag"`)
+		io.WriteString(w, "png")
+	}))
+	defer server.Close()
+
+	store, err := NewS3Store(S3Config{Endpoint: server.URL, Bucket: "avatars", AccessKey: "key", SecretKey: "secret"})
+	assert.NoError(t, err)
+
+	data, err := store.Get(context.Background(), "abc")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("png"), data)
+
+	_, err = store.Get(context.Background(), "missing")
+	assert.ErrorIs(t, err, interfaces.ErrObjectNotFound)
+}
+
+func TestNewS3StoreInvalidEndpoint(t *testing.T) {
+	_, err := NewS3Store(S3Config{Endpoint: "minio:9000", Bucket: "avatars"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,86 @@
+package integration_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"ttavito/config"
+	"ttavito/database"
+	"ttavito/repository"
+	"ttavito/usecase"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestAuditChainConcurrentWrites пишет журнал из многих транзакций одновременно, пока работает
+// воркер цепочки, и проверяет, что в итоге все записи связаны в одну непрерывную цепочку
+func TestAuditChainConcurrentWrites(t *testing.T) {
+	cfg := config.LoadConfig()
+	pool, err := database.NewPostgresDB(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create connection pool: %v", err)
+	}
+	defer pool.Close()
+
+	ctx := context.Background()
+	repo := repository.NewEntityRepo(pool)
+	api := usecase.NewUsecase(repo)
+
+	const writers = 50
+	suffix := fmt.Sprint(time.Now().UnixNano())
+
+	done := make(chan struct{})
+	chainerErr := make(chan error, 1)
+	go func() {
+		for {
+			select {
+			case <-done:
+				chainerErr <- nil
+				return
+			default:
+			}
+			if err := api.ChainAuditEvents(ctx); err != nil {
+				chainerErr <- err
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			from := fmt.Sprintf("audit_from_%d_%s", i, suffix)
+			to := fmt.Sprintf("audit_to_%d_%s", i, suffix)
+			for _, username := range []string{from, to} {
+				if _, err := repo.Auth(ctx, username, "pass"); err != nil {
+					t.Errorf("auth %s: %v", username, err)
+					return
+				}
+			}
+			if err := repo.SendCoin(ctx, from, to, 1, ""); err != nil {
+				t.Errorf("send coin %s: %v", from, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(done)
+	assert.NoError(t, <-chainerErr)
+
+	// Последний проход подбирает записи, закоммиченные после остановки воркера
+	assert.NoError(t, api.ChainAuditEvents(ctx))
+
+	var unchained int
+	err = pool.QueryRow(ctx, `SELECT COUNT(*) FROM audit_events WHERE chain_seq IS NULL`).Scan(&unchained)
+	assert.NoError(t, err)
+	assert.Zero(t, unchained)
+
+	report, err := api.VerifyAuditChain(ctx, nil, nil)
+	assert.NoError(t, err)
+	// Регистрация двух пользователей и перевод на каждого писателя
+	assert.GreaterOrEqual(t, report.Checked, 3*writers)
+}
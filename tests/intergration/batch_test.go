@@ -0,0 +1,51 @@
+package integration_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"ttavito/config"
+	"ttavito/database"
+	"ttavito/domain/entities"
+	"ttavito/repository"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestBatchSendCoinRowError проверяет, что в ответе по строке пакета - текст известной ошибки,
+// а не ошибка запроса к базе
+func TestBatchSendCoinRowError(t *testing.T) {
+	cfg := config.LoadConfig()
+	pool, err := database.NewPostgresDB(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create connection pool: %v", err)
+	}
+	defer pool.Close()
+
+	ctx := context.Background()
+	repo := repository.NewEntityRepo(pool)
+
+	suffix := fmt.Sprint(time.Now().UnixNano())
+	alice, bob := "batch_alice_"+suffix, "batch_bob_"+suffix
+	for _, username := range []string{alice, bob} {
+		if _, err := repo.Auth(ctx, username, "pass"); err != nil {
+			t.Fatalf("Failed to create user %s: %v", username, err)
+		}
+	}
+
+	res, err := repo.BatchSendCoin(ctx, alice, entities.BatchSendCoinRequest{
+		Transfers: []entities.SendCoinRequest{
+			{ToUser: bob, Amount: 10},
+			{ToUser: "ghost_" + suffix, Amount: 10},
+		},
+	})
+	assert.ErrorIs(t, err, entities.ErrUserNotFound)
+	if assert.NotNil(t, res) && assert.Len(t, res.Results, 2) {
+		assert.Equal(t, entities.BatchStatusRolledBack, res.Results[0].Status)
+		assert.Empty(t, res.Results[0].Error)
+		assert.Equal(t, entities.BatchStatusFailed, res.Results[1].Status)
+		assert.Equal(t, entities.ErrUserNotFound.Error(), res.Results[1].Error)
+	}
+}
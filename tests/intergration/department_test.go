@@ -0,0 +1,66 @@
+package integration_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"ttavito/config"
+	"ttavito/database"
+	"ttavito/domain/entities"
+	"ttavito/repository"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRecognizeFromBudgetRecipientChecks проверяет, что поощрение из бюджета не зачисляет
+// монеты замороженному и уволенному получателю и не тратит на них бюджет
+func TestRecognizeFromBudgetRecipientChecks(t *testing.T) {
+	cfg := config.LoadConfig()
+	pool, err := database.NewPostgresDB(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create connection pool: %v", err)
+	}
+	defer pool.Close()
+
+	ctx := context.Background()
+	repo := repository.NewEntityRepo(pool)
+
+	suffix := fmt.Sprint(time.Now().UnixNano())
+	user := func(name string) string {
+		username := name + "_" + suffix
+		if _, err := repo.Auth(ctx, username, "pass"); err != nil {
+			t.Fatalf("Failed to create user %s: %v", username, err)
+		}
+		return username
+	}
+	admin, lead, frozen, gone := user("admin"), user("lead"), user("frozen"), user("gone")
+
+	dep := "recognitions_" + suffix
+	_, err = repo.CreateDepartment(ctx, admin, entities.DepartmentRequest{Name: dep, Manager: lead, Budget: 100})
+	if err != nil {
+		t.Fatalf("Failed to create department: %v", err)
+	}
+	_, err = repo.AddDepartmentMembers(ctx, admin, entities.DepartmentMembersRequest{Department: dep, Usernames: []string{frozen, gone}})
+	if err != nil {
+		t.Fatalf("Failed to add department members: %v", err)
+	}
+
+	_, err = repo.FreezeAccount(ctx, admin, entities.FreezeRequest{Username: frozen, Reason: "review", BlockIncoming: true})
+	assert.NoError(t, err)
+	// Увольнение убирает из отдела, поэтому здесь сотрудник уволен в обход него, как при гонке с увольнением
+	_, err = pool.Exec(ctx, `UPDATE users SET deactivated_at = CURRENT_TIMESTAMP WHERE username = $1`, gone)
+	assert.NoError(t, err)
+
+	_, err = repo.RecognizeFromBudget(ctx, lead, entities.RecognitionRequest{Department: dep, ToUser: frozen, Amount: 10})
+	assert.ErrorIs(t, err, entities.ErrRecipientFrozen)
+
+	_, err = repo.RecognizeFromBudget(ctx, lead, entities.RecognitionRequest{Department: dep, ToUser: gone, Amount: 10})
+	assert.ErrorIs(t, err, entities.ErrUserDeactivated)
+
+	var budget int
+	err = pool.QueryRow(ctx, `SELECT budget FROM departments WHERE name = $1`, dep).Scan(&budget)
+	assert.NoError(t, err)
+	assert.Equal(t, 100, budget)
+}
@@ -0,0 +1,56 @@
+package integration_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"ttavito/config"
+	"ttavito/database"
+	"ttavito/domain/entities"
+	"ttavito/repository"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestGrantMaxAmount проверяет, что начисление больше GRANT_MAX_AMOUNT отклоняется,
+// а в массовом начислении такая строка помечается invalid
+func TestGrantMaxAmount(t *testing.T) {
+	cfg := config.LoadConfig()
+	pool, err := database.NewPostgresDB(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create connection pool: %v", err)
+	}
+	defer pool.Close()
+
+	ctx := context.Background()
+	repo := repository.NewEntityRepo(pool, repository.WithGrantMaxAmount(500))
+
+	suffix := fmt.Sprint(time.Now().UnixNano())
+	admin, user := "admin_"+suffix, "user_"+suffix
+	for _, username := range []string{admin, user} {
+		if _, err := repo.Auth(ctx, username, "pass"); err != nil {
+			t.Fatalf("Failed to create user %s: %v", username, err)
+		}
+	}
+
+	_, err = repo.GrantCoins(ctx, admin, entities.GrantRequest{ToUser: user, Amount: 501, Reason: "typo"})
+	assert.ErrorIs(t, err, entities.ErrGrantAmountTooLarge)
+
+	res, err := repo.BulkGrantCoins(ctx, admin, entities.BulkGrantRequest{Rows: []entities.BulkGrantRow{
+		{Line: 1, ToUser: user, Amount: 500, Reason: "release"},
+		{Line: 2, ToUser: user, Amount: 501, Reason: "typo"},
+	}})
+	assert.ErrorIs(t, err, entities.ErrBulkGrantInvalid)
+	if assert.NotNil(t, res) && assert.Len(t, res.Rows, 2) {
+		assert.Equal(t, entities.GrantRowValid, res.Rows[0].Status)
+		assert.Equal(t, entities.GrantRowInvalid, res.Rows[1].Status)
+		assert.Equal(t, "grant amount exceeds the maximum of 500", res.Rows[1].Error)
+	}
+
+	var balance int
+	err = pool.QueryRow(ctx, `SELECT balance FROM users WHERE username = $1`, user).Scan(&balance)
+	assert.NoError(t, err)
+	assert.Equal(t, 1000, balance)
+}
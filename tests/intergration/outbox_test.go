@@ -0,0 +1,162 @@
+package integration_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"ttavito/config"
+	"ttavito/database"
+	"ttavito/domain/entities"
+	"ttavito/repository"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCoinsTransferredOutbox проходит по всем путям, которые зачисляют монеты,
+// и проверяет, что каждый записал coins.transferred в outbox
+func TestCoinsTransferredOutbox(t *testing.T) {
+	cfg := config.LoadConfig()
+	pool, err := database.NewPostgresDB(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create connection pool: %v", err)
+	}
+	defer pool.Close()
+
+	ctx := context.Background()
+	repo := repository.NewEntityRepo(pool)
+
+	suffix := fmt.Sprint(time.Now().UnixNano())
+	user := func(name string) string {
+		username := name + "_" + suffix
+		if _, err := repo.Auth(ctx, username, "pass"); err != nil {
+			t.Fatalf("Failed to create user %s: %v", username, err)
+		}
+		return username
+	}
+	admin, alice, bob, carol := user("admin"), user("alice"), user("bob"), user("carol")
+
+	paths := []struct {
+		name string
+		run  func() (string, error)
+	}{
+		{"send coin", func() (string, error) {
+			if err := repo.SendCoin(ctx, alice, bob, 10, "thanks"); err != nil {
+				return "", err
+			}
+			// SendCoin не возвращает id, берём последний перевод
+			var id string
+			err := pool.QueryRow(ctx, `SELECT id::text FROM transfers WHERE sender_username = $1 ORDER BY created_at DESC LIMIT 1`, alice).Scan(&id)
+			return id, err
+		}},
+		{"batch", func() (string, error) {
+			res, err := repo.BatchSendCoin(ctx, alice, entities.BatchSendCoinRequest{
+				Transfers: []entities.SendCoinRequest{{ToUser: bob, Amount: 5}},
+			})
+			if err != nil {
+				return "", err
+			}
+			return res.Results[0].TransferID, nil
+		}},
+		{"pending accepted", func() (string, error) {
+			p, err := repo.CreatePendingTransfer(ctx, alice, entities.SendCoinRequest{ToUser: bob, Amount: 5}, time.Now().Add(time.Hour))
+			if err != nil {
+				return "", err
+			}
+			p, err = repo.AcceptPendingTransfer(ctx, bob, p.ID)
+			if err != nil {
+				return "", err
+			}
+			return p.TransferID, nil
+		}},
+		{"scheduled", func() (string, error) {
+			now := time.Now()
+			s, err := repo.CreateScheduledTransfer(ctx, alice, entities.ScheduledTransferRequest{ToUser: bob, Amount: 5}, now)
+			if err != nil {
+				return "", err
+			}
+			run, err := repo.RunScheduledTransfer(ctx, *s, now, nil)
+			if err != nil {
+				return "", err
+			}
+			return run.TransferID, nil
+		}},
+		{"recognition", func() (string, error) {
+			dep := "dep_" + suffix
+			_, err := repo.CreateDepartment(ctx, admin, entities.DepartmentRequest{Name: dep, Manager: carol, Budget: 100})
+			if err != nil {
+				return "", err
+			}
+			_, err = repo.AddDepartmentMembers(ctx, admin, entities.DepartmentMembersRequest{Department: dep, Usernames: []string{bob}})
+			if err != nil {
+				return "", err
+			}
+			rec, err := repo.RecognizeFromBudget(ctx, carol, entities.RecognitionRequest{Department: dep, ToUser: bob, Amount: 20})
+			if err != nil {
+				return "", err
+			}
+			return rec.ID, nil
+		}},
+		{"grant", func() (string, error) {
+			g, err := repo.GrantCoins(ctx, admin, entities.GrantRequest{ToUser: bob, Amount: 50, Reason: "bonus"})
+			if err != nil {
+				return "", err
+			}
+			return g.ID, nil
+		}},
+		{"bulk grant", func() (string, error) {
+			res, err := repo.BulkGrantCoins(ctx, admin, entities.BulkGrantRequest{
+				Rows: []entities.BulkGrantRow{{Line: 1, ToUser: bob, Amount: 50, Reason: "bonus"}},
+			})
+			if err != nil {
+				return "", err
+			}
+			return res.Rows[0].GrantID, nil
+		}},
+		{"reversal", func() (string, error) {
+			res, err := repo.BatchSendCoin(ctx, alice, entities.BatchSendCoinRequest{
+				Transfers: []entities.SendCoinRequest{{ToUser: bob, Amount: 5}},
+			})
+			if err != nil {
+				return "", err
+			}
+			rev, err := repo.ReverseTransfer(ctx, admin, entities.ReverseTransferRequest{TransferID: res.Results[0].TransferID})
+			if err != nil {
+				return "", err
+			}
+			return rev.ID, nil
+		}},
+		{"offboarding", func() (string, error) {
+			res, err := repo.OffboardUser(ctx, admin, entities.OffboardRequest{
+				Username: alice, BalancePolicy: entities.BalancePolicyRedistribute, Recipients: []string{bob}, Reason: "left",
+			})
+			if err != nil {
+				return "", err
+			}
+			return res.Transfers[0].TransferID, nil
+		}},
+	}
+
+	for _, p := range paths {
+		t.Run(p.name, func(t *testing.T) {
+			id, err := p.run()
+			if !assert.NoError(t, err) || !assert.NotEmpty(t, id) {
+				return
+			}
+			assert.Equal(t, 1, countCoinsTransferred(t, pool, id))
+		})
+	}
+}
+
+func countCoinsTransferred(t *testing.T, pool *pgxpool.Pool, id string) int {
+	var count int
+	err := pool.QueryRow(context.Background(),
+		`SELECT COUNT(*) FROM outbox_events WHERE event_type = $1 AND payload->>'transferId' = $2`,
+		entities.EventCoinsTransferred, id).Scan(&count)
+	if err != nil {
+		t.Fatalf("Failed to count outbox events: %v", err)
+	}
+	return count
+}
@@ -0,0 +1,64 @@
+package integration_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"ttavito/config"
+	"ttavito/database"
+	"ttavito/domain/entities"
+	"ttavito/repository"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestReverseTransferSenderChecks проверяет, что сторно не возвращает монеты отправителю,
+// которому заморожены входящие или который уволен, и не списывает их у получателя
+func TestReverseTransferSenderChecks(t *testing.T) {
+	cfg := config.LoadConfig()
+	pool, err := database.NewPostgresDB(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create connection pool: %v", err)
+	}
+	defer pool.Close()
+
+	ctx := context.Background()
+	repo := repository.NewEntityRepo(pool)
+
+	suffix := fmt.Sprint(time.Now().UnixNano())
+	user := func(name string) string {
+		username := name + "_" + suffix
+		if _, err := repo.Auth(ctx, username, "pass"); err != nil {
+			t.Fatalf("Failed to create user %s: %v", username, err)
+		}
+		return username
+	}
+	admin, sender, receiver := user("admin"), user("sender"), user("receiver")
+
+	err = repo.SendCoin(ctx, sender, receiver, 50, "")
+	if err != nil {
+		t.Fatalf("Failed to send coins: %v", err)
+	}
+	var transferID string
+	err = pool.QueryRow(ctx, `SELECT id::text FROM transfers WHERE sender_username = $1`, sender).Scan(&transferID)
+	if err != nil {
+		t.Fatalf("Failed to fetch transfer: %v", err)
+	}
+
+	_, err = repo.FreezeAccount(ctx, admin, entities.FreezeRequest{Username: sender, Reason: "review", BlockIncoming: true})
+	assert.NoError(t, err)
+	_, err = repo.ReverseTransfer(ctx, admin, entities.ReverseTransferRequest{TransferID: transferID})
+	assert.ErrorIs(t, err, entities.ErrRecipientFrozen)
+
+	_, err = pool.Exec(ctx, `UPDATE users SET deactivated_at = CURRENT_TIMESTAMP WHERE username = $1`, sender)
+	assert.NoError(t, err)
+	_, err = repo.ReverseTransfer(ctx, admin, entities.ReverseTransferRequest{TransferID: transferID})
+	assert.ErrorIs(t, err, entities.ErrUserDeactivated)
+
+	var balance int
+	err = pool.QueryRow(ctx, `SELECT balance FROM users WHERE username = $1`, receiver).Scan(&balance)
+	assert.NoError(t, err)
+	assert.Equal(t, 1050, balance)
+}
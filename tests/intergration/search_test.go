@@ -0,0 +1,49 @@
+package integration_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"ttavito/config"
+	"ttavito/database"
+	"ttavito/domain/entities"
+	"ttavito/repository"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Совпадение по началу логина идёт выше похожего логина, даже если ни у кого нет профиля
+func TestSearchUsersWithoutProfile(t *testing.T) {
+	cfg := config.LoadConfig()
+	pool, err := database.NewPostgresDB(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create connection pool: %v", err)
+	}
+	defer pool.Close()
+
+	ctx := context.Background()
+	repo := repository.NewEntityRepo(pool)
+
+	query := fmt.Sprintf("srch%d", time.Now().UnixNano())
+	prefixMatch, fuzzyMatch := query+"_alpha", "x"+query
+	for _, username := range []string{"requester_" + query, fuzzyMatch, prefixMatch} {
+		if _, err := repo.Auth(ctx, username, "pass"); err != nil {
+			t.Fatalf("Failed to create user %s: %v", username, err)
+		}
+	}
+
+	res, err := repo.SearchUsers(ctx, "requester_"+query, entities.UserSearchRequest{Query: query, Limit: 10})
+	if !assert.NoError(t, err) {
+		return
+	}
+	// В выдачу могут попасть похожие логины из прошлых запусков, важен только порядок двух своих
+	position := map[string]int{}
+	for i, u := range res.Users {
+		position[u.Username] = i + 1
+	}
+	if assert.NotZero(t, position[prefixMatch]) && assert.NotZero(t, position[fuzzyMatch]) {
+		assert.Less(t, position[prefixMatch], position[fuzzyMatch])
+	}
+}
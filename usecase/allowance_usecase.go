@@ -0,0 +1,57 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"ttavito/domain/entities"
+)
+
+// IssueAllowance вызывается фоновым воркером. Пособие за текущий период выдаётся один раз,
+// повторные запуски в том же периоде начисляют его только новым пользователям.
+func (u *Usecase) IssueAllowance(ctx context.Context) error {
+	if u.allowanceAmount <= 0 {
+		return nil
+	}
+
+	period, err := AllowancePeriodKey(u.allowancePeriod, time.Now())
+	if err != nil {
+		return err
+	}
+
+	_, err = u.IssueAllowanceForPeriod(ctx, period, u.allowanceAmount)
+	return err
+}
+
+// IssueAllowanceForPeriod выдаёт пособие за указанный период, используется и из CLI
+func (u *Usecase) IssueAllowanceForPeriod(ctx context.Context, period string, amount int) (int, error) {
+	if period == "" || amount <= 0 {
+		return 0, fmt.Errorf("period and positive amount are required")
+	}
+
+	count, err := u.repo.IssueAllowance(ctx, period, amount)
+	if err != nil {
+		return 0, err
+	}
+	if count > 0 {
+		slog.Info("Allowance issued", "period", period, "amount", amount, "users", count)
+	}
+	return count, nil
+}
+
+// AllowancePeriodKey возвращает ключ периода, в который попадает t (в UTC)
+func AllowancePeriodKey(period string, t time.Time) (string, error) {
+	t = t.UTC()
+	switch period {
+	case entities.AllowanceMonthly:
+		return t.Format("2006-01"), nil
+	case entities.AllowanceWeekly:
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week), nil
+	case entities.AllowanceDaily:
+		return t.Format("2006-01-02"), nil
+	}
+	return "", entities.ErrInvalidAllowancePeriod
+}
@@ -0,0 +1,56 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"ttavito/domain/entities"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAllowancePeriodKey(t *testing.T) {
+	at := time.Date(2025, time.February, 14, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		period string
+		want   string
+	}{
+		{entities.AllowanceMonthly, "2025-02"},
+		{entities.AllowanceWeekly, "2025-W07"},
+		{entities.AllowanceDaily, "2025-02-14"},
+	}
+	for _, tt := range tests {
+		got, err := AllowancePeriodKey(tt.period, at)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.want, got)
+	}
+
+	_, err := AllowancePeriodKey("yearly", at)
+	assert.ErrorIs(t, err, entities.ErrInvalidAllowancePeriod)
+}
+
+func TestIssueAllowance(t *testing.T) {
+	mockRepo := new(MockShopRepository)
+	uc := NewUsecase(mockRepo, WithAllowance(500, entities.AllowanceMonthly))
+
+	period, _ := AllowancePeriodKey(entities.AllowanceMonthly, time.Now())
+	mockRepo.On("IssueAllowance", mock.Anything, period, 500).Return(3, nil)
+
+	err := uc.IssueAllowance(context.Background())
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestIssueAllowanceDisabled(t *testing.T) {
+	mockRepo := new(MockShopRepository)
+	uc := NewUsecase(mockRepo)
+
+	err := uc.IssueAllowance(context.Background())
+
+	assert.NoError(t, err)
+	mockRepo.AssertNotCalled(t, "IssueAllowance", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,63 @@
+package usecase
+
+import (
+	"context"
+	"crypto/ed25519"
+	"log/slog"
+	"time"
+
+	"ttavito/audit"
+	"ttavito/domain/entities"
+)
+
+// Сколько записей журнала связывается в цепочку за одну транзакцию
+const auditChainBatch = 1000
+
+func (u *Usecase) GetAuditEvents(ctx context.Context, req entities.AuditQuery) (*entities.AuditEventsResponse, error) {
+	return u.repo.GetAuditEvents(ctx, req)
+}
+
+// ChainAuditEvents вызывается фоновым воркером и связывает в цепочку записи журнала,
+// накопившиеся с прошлого запуска
+func (u *Usecase) ChainAuditEvents(ctx context.Context) error {
+	for {
+		n, err := u.repo.ChainAuditEvents(ctx, auditChainBatch)
+		if err != nil {
+			return err
+		}
+		if n < auditChainBatch {
+			return nil
+		}
+	}
+}
+
+// ExportAuditCheckpoint вызывается фоновым воркером и подписывает последнюю запись журнала,
+// если она изменилась с прошлого запуска
+func (u *Usecase) ExportAuditCheckpoint(ctx context.Context) error {
+	if u.checkpointKey == nil {
+		return nil
+	}
+
+	head, err := u.repo.GetAuditHead(ctx)
+	if err != nil {
+		return err
+	}
+	if head == nil || head.ID == u.lastCheckpointID {
+		return nil
+	}
+
+	err = audit.AppendCheckpoint(u.checkpointFile, audit.Sign(u.checkpointKey, head.ID, head.Hash, time.Now()))
+	if err != nil {
+		return err
+	}
+	u.lastCheckpointID = head.ID
+	slog.Info("Audit checkpoint exported", "id", head.ID)
+	return nil
+}
+
+// VerifyAuditChain проверяет весь журнал и контрольные точки, используется из CLI
+func (u *Usecase) VerifyAuditChain(ctx context.Context, checkpoints []audit.Checkpoint, key ed25519.PublicKey) (*audit.Report, error) {
+	return audit.Verify(func(fn func(entities.AuditEvent) error) error {
+		return u.repo.ScanAuditEvents(ctx, fn)
+	}, checkpoints, key)
+}
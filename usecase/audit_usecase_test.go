@@ -0,0 +1,52 @@
+package usecase
+
+import (
+	"context"
+	"crypto/ed25519"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ttavito/audit"
+	"ttavito/domain/entities"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestExportAuditCheckpoint(t *testing.T) {
+	mockRepo := new(MockShopRepository)
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	path := filepath.Join(t.TempDir(), "checkpoints.jsonl")
+	uc := NewUsecase(mockRepo, WithAuditCheckpoints(priv, path))
+
+	mockRepo.On("GetAuditHead", mock.Anything).Return(&entities.AuditHead{ID: 42, Hash: "abc"}, nil)
+
+	// Второй запуск без новых записей файл не меняет
+	assert.NoError(t, uc.ExportAuditCheckpoint(context.Background()))
+	assert.NoError(t, uc.ExportAuditCheckpoint(context.Background()))
+
+	f, err := os.Open(path)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer f.Close()
+	checkpoints, err := audit.ReadCheckpoints(f)
+	assert.NoError(t, err)
+	if assert.Len(t, checkpoints, 1) {
+		assert.Equal(t, int64(42), checkpoints[0].ID)
+		assert.True(t, checkpoints[0].Verify(pub))
+	}
+}
+
+func TestChainAuditEvents(t *testing.T) {
+	mockRepo := new(MockShopRepository)
+	uc := NewUsecase(mockRepo)
+
+	// Полная пачка означает, что записи могли остаться, и воркер берёт следующую
+	mockRepo.On("ChainAuditEvents", mock.Anything, auditChainBatch).Return(auditChainBatch, nil).Twice()
+	mockRepo.On("ChainAuditEvents", mock.Anything, auditChainBatch).Return(3, nil).Once()
+
+	assert.NoError(t, uc.ChainAuditEvents(context.Background()))
+	mockRepo.AssertNumberOfCalls(t, "ChainAuditEvents", 3)
+}
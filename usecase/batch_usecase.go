@@ -0,0 +1,11 @@
+package usecase
+
+import (
+	"context"
+
+	"ttavito/domain/entities"
+)
+
+func (u *Usecase) BatchSendCoin(ctx context.Context, senderUsername string, req entities.BatchSendCoinRequest) (*entities.BatchSendCoinResponse, error) {
+	return u.repo.BatchSendCoin(ctx, senderUsername, req)
+}
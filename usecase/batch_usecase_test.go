@@ -0,0 +1,36 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"ttavito/domain/entities"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestBatchSendCoin(t *testing.T) {
+	mockRepo := new(MockShopRepository)
+	uc := NewUsecase(mockRepo)
+
+	req := entities.BatchSendCoinRequest{Transfers: []entities.SendCoinRequest{
+		{ToUser: "user2", Amount: 10},
+		{ToUser: "user3", Amount: 10},
+	}}
+	mockRepo.On("BatchSendCoin", mock.Anything, "user1", req).Return(&entities.BatchSendCoinResponse{
+		Results: []entities.BatchTransferResult{
+			{ToUser: "user2", Amount: 10, Status: entities.BatchStatusSent},
+			{ToUser: "user3", Amount: 10, Status: entities.BatchStatusSent},
+		},
+		Total: 20,
+	}, nil)
+
+	res, err := uc.BatchSendCoin(context.Background(), "user1", req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 20, res.Total)
+	assert.Len(t, res.Results, 2)
+
+	mockRepo.AssertExpectations(t)
+}
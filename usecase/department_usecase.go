@@ -0,0 +1,30 @@
+package usecase
+
+import (
+	"context"
+
+	"ttavito/domain/entities"
+)
+
+func (u *Usecase) CreateDepartment(ctx context.Context, admin string, req entities.DepartmentRequest) (*entities.Department, error) {
+	return u.repo.CreateDepartment(ctx, admin, req)
+}
+
+func (u *Usecase) AddDepartmentMembers(ctx context.Context, admin string, req entities.DepartmentMembersRequest) (*entities.Department, error) {
+	return u.repo.AddDepartmentMembers(ctx, admin, req)
+}
+
+func (u *Usecase) AllocateDepartmentBudget(ctx context.Context, admin string, req entities.BudgetAllocationRequest) (*entities.Department, error) {
+	return u.repo.AllocateDepartmentBudget(ctx, admin, req)
+}
+
+func (u *Usecase) RecognizeFromBudget(ctx context.Context, manager string, req entities.RecognitionRequest) (*entities.Recognition, error) {
+	if manager == req.ToUser {
+		return nil, entities.ErrTransferToSelf
+	}
+	return u.repo.RecognizeFromBudget(ctx, manager, req)
+}
+
+func (u *Usecase) GetDepartmentBudgetReport(ctx context.Context, req entities.DepartmentReportRequest) (*entities.DepartmentBudgetReport, error) {
+	return u.repo.GetDepartmentBudgetReport(ctx, req)
+}
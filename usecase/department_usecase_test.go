@@ -0,0 +1,39 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"ttavito/domain/entities"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRecognizeFromBudgetSelf(t *testing.T) {
+	mockRepo := new(MockShopRepository)
+	uc := NewUsecase(mockRepo)
+
+	_, err := uc.RecognizeFromBudget(context.Background(), "lead", entities.RecognitionRequest{
+		Department: "platform",
+		ToUser:     "lead",
+		Amount:     100,
+	})
+
+	assert.ErrorIs(t, err, entities.ErrTransferToSelf)
+	mockRepo.AssertNotCalled(t, "RecognizeFromBudget", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRecognizeFromBudgetExhausted(t *testing.T) {
+	mockRepo := new(MockShopRepository)
+	uc := NewUsecase(mockRepo)
+
+	req := entities.RecognitionRequest{Department: "platform", ToUser: "dev", Amount: 100}
+	mockRepo.On("RecognizeFromBudget", mock.Anything, "lead", req).
+		Return((*entities.Recognition)(nil), entities.ErrBudgetExhausted)
+
+	_, err := uc.RecognizeFromBudget(context.Background(), "lead", req)
+
+	assert.ErrorIs(t, err, entities.ErrBudgetExhausted)
+	mockRepo.AssertExpectations(t)
+}
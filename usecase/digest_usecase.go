@@ -0,0 +1,117 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/mail"
+	"time"
+
+	"ttavito/digest"
+	"ttavito/domain/entities"
+)
+
+// Сколько получателей сводки выбирается за раз
+const digestBatchSize = 100
+
+func (u *Usecase) digestEmail(username string) string {
+	if u.mailer == nil || u.mailDomain == "" {
+		return ""
+	}
+	return username + "@" + u.mailDomain
+}
+
+func (u *Usecase) GetDigestSettings(ctx context.Context, username string) (*entities.DigestSettings, error) {
+	enabled, err := u.repo.GetDigestEnabled(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	return &entities.DigestSettings{Enabled: enabled, Email: u.digestEmail(username)}, nil
+}
+
+func (u *Usecase) UpdateDigestSettings(ctx context.Context, username string, req entities.DigestSettings) (*entities.DigestSettings, error) {
+	if err := u.repo.SetDigestEnabled(ctx, username, req.Enabled); err != nil {
+		return nil, err
+	}
+	return &entities.DigestSettings{Enabled: req.Enabled, Email: u.digestEmail(username)}, nil
+}
+
+// SendDigests отправляет сводки за последнюю завершившуюся неделю тем, кто их ещё не получил.
+// Пользователям без активности за неделю письмо не отправляется. Если адрес из логина
+// недопустим или сервер окончательно отказал получателю, сводка этой недели ему пропускается.
+// При прочих ошибках запуск прекращается: почтовый сервер, скорее всего, недоступен,
+// остальные получат сводку в следующий раз
+func (u *Usecase) SendDigests(ctx context.Context) error {
+	if u.digestEmail("") == "" {
+		return nil
+	}
+
+	weekStart := digest.WeekStart(time.Now())
+	weekEnd := weekStart.AddDate(0, 0, 7)
+
+	sent := 0
+	for {
+		recipients, err := u.repo.GetDigestRecipients(ctx, weekStart, digestBatchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, username := range recipients {
+			claimed, err := u.repo.ClaimDigest(ctx, username, weekStart)
+			if err != nil {
+				return err
+			}
+			if !claimed {
+				continue
+			}
+
+			ok, err := u.sendDigest(ctx, username, weekStart, weekEnd)
+			if errors.Is(err, entities.ErrInvalidEmailAddress) || errors.Is(err, entities.ErrEmailRejected) {
+				// Сводка остаётся занятой, иначе пользователь снова окажется первым в следующем запуске
+				slog.Warn("Digest skipped", "username", username, "error", err)
+				continue
+			}
+			if err != nil {
+				if releaseErr := u.repo.ReleaseDigest(ctx, username, weekStart); releaseErr != nil {
+					slog.Error("Failed to release digest", "username", username, "error", releaseErr)
+				}
+				return fmt.Errorf("digest for %s: %w", username, err)
+			}
+			if ok {
+				sent++
+			}
+		}
+
+		if len(recipients) < digestBatchSize {
+			break
+		}
+	}
+
+	if sent > 0 {
+		slog.Info("Weekly digests sent", "week", weekStart.Format(time.DateOnly), "count", sent)
+	}
+	return nil
+}
+
+// sendDigest возвращает false, если за неделю ничего не произошло и письмо не нужно
+func (u *Usecase) sendDigest(ctx context.Context, username string, weekStart, weekEnd time.Time) (bool, error) {
+	activity, err := u.repo.GetDigestActivity(ctx, username, weekStart, weekEnd)
+	if err != nil {
+		return false, err
+	}
+	if activity.Empty() {
+		return false, nil
+	}
+
+	to := u.digestEmail(username)
+	if _, err := mail.ParseAddress(to); err != nil {
+		return false, fmt.Errorf("%w %q: %v", entities.ErrInvalidEmailAddress, to, err)
+	}
+
+	msg, err := digest.Render(to, *activity)
+	if err != nil {
+		return false, err
+	}
+	return true, u.mailer.Send(ctx, msg)
+}
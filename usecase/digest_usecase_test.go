@@ -0,0 +1,115 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"ttavito/domain/entities"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockMailer struct {
+	mock.Mock
+}
+
+func (m *MockMailer) Send(ctx context.Context, msg entities.EmailMessage) error {
+	args := m.Called(ctx, msg)
+	return args.Error(0)
+}
+
+func TestGetDigestSettings(t *testing.T) {
+	mockRepo := new(MockShopRepository)
+	mockRepo.On("GetDigestEnabled", mock.Anything, "alice").Return(true, nil)
+
+	res, err := NewUsecase(mockRepo, WithDigests(new(MockMailer), "example.com")).GetDigestSettings(context.Background(), "alice")
+	assert.NoError(t, err)
+	assert.Equal(t, &entities.DigestSettings{Enabled: true, Email: "alice@example.com"}, res)
+
+	// Без почты адрес не показывается
+	res, err = NewUsecase(mockRepo).GetDigestSettings(context.Background(), "alice")
+	assert.NoError(t, err)
+	assert.Empty(t, res.Email)
+}
+
+func TestSendDigests(t *testing.T) {
+	mockRepo := new(MockShopRepository)
+	mailer := new(MockMailer)
+	uc := NewUsecase(mockRepo, WithDigests(mailer, "example.com"))
+
+	weekStart := mock.AnythingOfType("time.Time")
+	mockRepo.On("GetDigestRecipients", mock.Anything, weekStart, digestBatchSize).Return([]string{"alice", "bob", "carol"}, nil)
+	mockRepo.On("ClaimDigest", mock.Anything, "alice", weekStart).Return(true, nil)
+	mockRepo.On("ClaimDigest", mock.Anything, "bob", weekStart).Return(true, nil)
+	// Сводку carol уже отправила другая реплика
+	mockRepo.On("ClaimDigest", mock.Anything, "carol", weekStart).Return(false, nil)
+	mockRepo.On("GetDigestActivity", mock.Anything, "alice", weekStart, weekStart).Return(&entities.DigestActivity{
+		Username: "alice", DisplayName: "alice", Received: 100, ReceivedCount: 1,
+	}, nil)
+	mockRepo.On("GetDigestActivity", mock.Anything, "bob", weekStart, weekStart).Return(&entities.DigestActivity{Username: "bob"}, nil)
+	mailer.On("Send", mock.Anything, mock.MatchedBy(func(msg entities.EmailMessage) bool {
+		return msg.To == "alice@example.com" && msg.Text != "" && msg.HTML != ""
+	})).Return(nil)
+
+	assert.NoError(t, uc.SendDigests(context.Background()))
+	mockRepo.AssertExpectations(t)
+	mailer.AssertNumberOfCalls(t, "Send", 1)
+	mockRepo.AssertNotCalled(t, "GetDigestActivity", mock.Anything, "carol", mock.Anything, mock.Anything)
+}
+
+func TestSendDigestsMailerError(t *testing.T) {
+	mockRepo := new(MockShopRepository)
+	mailer := new(MockMailer)
+	uc := NewUsecase(mockRepo, WithDigests(mailer, "example.com"))
+
+	weekStart := mock.AnythingOfType("time.Time")
+	mockRepo.On("GetDigestRecipients", mock.Anything, weekStart, digestBatchSize).Return([]string{"alice", "bob"}, nil)
+	mockRepo.On("ClaimDigest", mock.Anything, "alice", weekStart).Return(true, nil)
+	mockRepo.On("GetDigestActivity", mock.Anything, "alice", weekStart, weekStart).Return(&entities.DigestActivity{
+		Username: "alice", SentCount: 1, Sent: 10,
+	}, nil)
+	mailer.On("Send", mock.Anything, mock.Anything).Return(errors.New("connection refused"))
+	mockRepo.On("ReleaseDigest", mock.Anything, "alice", weekStart).Return(nil)
+
+	err := uc.SendDigests(context.Background())
+	assert.ErrorContains(t, err, "connection refused")
+	mockRepo.AssertExpectations(t)
+	// Остальные получат сводку при следующем запуске
+	mockRepo.AssertNotCalled(t, "ClaimDigest", mock.Anything, "bob", mock.Anything)
+}
+
+func TestSendDigestsSkipsBadAddresses(t *testing.T) {
+	mockRepo := new(MockShopRepository)
+	mailer := new(MockMailer)
+	uc := NewUsecase(mockRepo, WithDigests(mailer, "example.com"))
+
+	weekStart := mock.AnythingOfType("time.Time")
+	activity := func(username string) *entities.DigestActivity {
+		return &entities.DigestActivity{Username: username, DisplayName: username, Received: 100, ReceivedCount: 1}
+	}
+	// Логины раньше bob по алфавиту: из первого не собрать адрес, второму отказывает сервер
+	mockRepo.On("GetDigestRecipients", mock.Anything, weekStart, digestBatchSize).Return([]string{"a b", "anna", "bob"}, nil)
+	for _, username := range []string{"a b", "anna", "bob"} {
+		mockRepo.On("ClaimDigest", mock.Anything, username, weekStart).Return(true, nil)
+		mockRepo.On("GetDigestActivity", mock.Anything, username, weekStart, weekStart).Return(activity(username), nil)
+	}
+	mailer.On("Send", mock.Anything, mock.MatchedBy(func(msg entities.EmailMessage) bool { return msg.To == "anna@example.com" })).
+		Return(fmt.Errorf("%w: 553 mailbox name not allowed", entities.ErrEmailRejected))
+	mailer.On("Send", mock.Anything, mock.MatchedBy(func(msg entities.EmailMessage) bool { return msg.To == "bob@example.com" })).
+		Return(nil)
+
+	assert.NoError(t, uc.SendDigests(context.Background()))
+	mockRepo.AssertExpectations(t)
+	mailer.AssertNumberOfCalls(t, "Send", 2)
+	mockRepo.AssertNotCalled(t, "ReleaseDigest", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSendDigestsDisabled(t *testing.T) {
+	mockRepo := new(MockShopRepository)
+
+	assert.NoError(t, NewUsecase(mockRepo).SendDigests(context.Background()))
+	mockRepo.AssertNotCalled(t, "GetDigestRecipients", mock.Anything, mock.Anything, mock.Anything)
+}
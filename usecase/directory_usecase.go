@@ -0,0 +1,20 @@
+package usecase
+
+import (
+	"context"
+
+	"ttavito/directory"
+	"ttavito/domain/entities"
+)
+
+// ImportOrgChart проверяет структуру файла и только потом идёт в базу
+func (u *Usecase) ImportOrgChart(ctx context.Context, entries []entities.OrgChartEntry, dryRun bool) (*entities.OrgChartImportResult, error) {
+	if err := directory.Validate(entries); err != nil {
+		return nil, err
+	}
+	return u.repo.ImportOrgChart(ctx, entries, dryRun)
+}
+
+func (u *Usecase) GetTeam(ctx context.Context, name string) (*entities.Team, error) {
+	return u.repo.GetTeam(ctx, name)
+}
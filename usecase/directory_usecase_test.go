@@ -0,0 +1,39 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"ttavito/domain/entities"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestImportOrgChart(t *testing.T) {
+	mockRepo := new(MockShopRepository)
+	uc := NewUsecase(mockRepo)
+
+	entries := []entities.OrgChartEntry{
+		{Username: "alice", Department: "platform", Role: entities.OrgRoleDepartmentHead},
+		{Username: "bob", Department: "platform", Team: "core", Manager: "alice"},
+	}
+	mockRepo.On("ImportOrgChart", mock.Anything, entries, true).
+		Return(&entities.OrgChartImportResult{DryRun: true, Departments: 1, Teams: 1, Users: 2}, nil)
+
+	res, err := uc.ImportOrgChart(context.Background(), entries, true)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, res.Users)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestImportOrgChartInvalid(t *testing.T) {
+	mockRepo := new(MockShopRepository)
+	uc := NewUsecase(mockRepo)
+
+	_, err := uc.ImportOrgChart(context.Background(), []entities.OrgChartEntry{{Username: "alice"}}, false)
+
+	assert.Error(t, err)
+	mockRepo.AssertNotCalled(t, "ImportOrgChart", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,27 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"ttavito/domain/entities"
+
+	"github.com/coder/websocket"
+)
+
+// SubscribeUserEvents открывает поток событий пользователя, cancel закрывает его
+func (u *Usecase) SubscribeUserEvents(username string) (<-chan entities.UserEvent, func(), error) {
+	if u.userEvents == nil {
+		return nil, nil, entities.ErrEventStreamUnavailable
+	}
+	return u.userEvents.Subscribe(username)
+}
+
+// ServeEventSocket ведёт WebSocket-сессию /api/ws до её закрытия
+func (u *Usecase) ServeEventSocket(ctx context.Context, conn *websocket.Conn, username string, expiresAt time.Time) error {
+	if u.userEvents == nil {
+		return entities.ErrEventStreamUnavailable
+	}
+	u.userEvents.ServeSocket(ctx, conn, username, expiresAt)
+	return nil
+}
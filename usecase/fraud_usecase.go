@@ -0,0 +1,35 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+
+	"ttavito/domain/entities"
+	"ttavito/fraud"
+)
+
+// DetectFraud вызывается фоновым воркером и добавляет найденные схемы в очередь на разбор
+func (u *Usecase) DetectFraud(ctx context.Context) error {
+	if !u.fraudRules.Enabled() || u.fraudWindow <= 0 {
+		return nil
+	}
+
+	transfers, err := u.repo.GetFraudTransfers(ctx, u.fraudWindow)
+	if err != nil {
+		return err
+	}
+
+	added, err := u.repo.SaveFraudFindings(ctx, fraud.Detect(transfers, u.fraudRules, u.fraudWindow))
+	if added > 0 {
+		slog.Info("Fraud findings added to review queue", "count", added)
+	}
+	return err
+}
+
+func (u *Usecase) GetFraudFindings(ctx context.Context, req entities.FraudFindingsRequest) (*entities.FraudFindingsResponse, error) {
+	return u.repo.GetFraudFindings(ctx, req)
+}
+
+func (u *Usecase) ReviewFraudFinding(ctx context.Context, admin string, req entities.FraudReviewRequest) (*entities.FraudFinding, error) {
+	return u.repo.ReviewFraudFinding(ctx, admin, req)
+}
@@ -0,0 +1,39 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"ttavito/domain/entities"
+	"ttavito/fraud"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestDetectFraud(t *testing.T) {
+	mockRepo := new(MockShopRepository)
+	uc := NewUsecase(mockRepo, WithFraudDetection(fraud.Rules{CycleMaxLength: 3}, 24*time.Hour))
+
+	now := time.Now()
+	mockRepo.On("GetFraudTransfers", mock.Anything, 24*time.Hour).Return([]entities.FraudTransfer{
+		{ID: "1", From: "alice", To: "bob", Amount: 100, CreatedAt: now},
+		{ID: "2", From: "bob", To: "carol", Amount: 100, CreatedAt: now},
+		{ID: "3", From: "carol", To: "alice", Amount: 100, CreatedAt: now},
+	}, nil)
+	mockRepo.On("SaveFraudFindings", mock.Anything, mock.MatchedBy(func(findings []entities.FraudFinding) bool {
+		return len(findings) == 1 && findings[0].Kind == entities.FraudKindCycle
+	})).Return(1, nil)
+
+	assert.NoError(t, uc.DetectFraud(context.Background()))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestDetectFraudDisabled(t *testing.T) {
+	mockRepo := new(MockShopRepository)
+	uc := NewUsecase(mockRepo)
+
+	assert.NoError(t, uc.DetectFraud(context.Background()))
+	mockRepo.AssertNotCalled(t, "GetFraudTransfers", mock.Anything, mock.Anything)
+}
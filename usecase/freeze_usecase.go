@@ -0,0 +1,15 @@
+package usecase
+
+import (
+	"context"
+
+	"ttavito/domain/entities"
+)
+
+func (u *Usecase) FreezeAccount(ctx context.Context, admin string, req entities.FreezeRequest) (*entities.AccountFreeze, error) {
+	return u.repo.FreezeAccount(ctx, admin, req)
+}
+
+func (u *Usecase) UnfreezeAccount(ctx context.Context, admin string, req entities.UnfreezeRequest) (*entities.AccountFreeze, error) {
+	return u.repo.UnfreezeAccount(ctx, admin, req)
+}
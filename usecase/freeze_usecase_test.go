@@ -0,0 +1,25 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"ttavito/domain/entities"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestFreezeAccount(t *testing.T) {
+	mockRepo := new(MockShopRepository)
+	uc := NewUsecase(mockRepo)
+
+	req := entities.FreezeRequest{Username: "suspect", Reason: "проверка", BlockIncoming: true}
+	mockRepo.On("FreezeAccount", mock.Anything, "admin", req).
+		Return((*entities.AccountFreeze)(nil), entities.ErrAlreadyFrozen)
+
+	_, err := uc.FreezeAccount(context.Background(), "admin", req)
+
+	assert.ErrorIs(t, err, entities.ErrAlreadyFrozen)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,14 @@
+package usecase
+
+import (
+	"context"
+
+	"ttavito/domain/entities"
+)
+
+func (u *Usecase) GiftItem(ctx context.Context, buyer string, req entities.GiftRequest) error {
+	if buyer == req.ToUser {
+		return entities.ErrGiftToSelf
+	}
+	return u.repo.GiftItem(ctx, buyer, req)
+}
@@ -0,0 +1,36 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"ttavito/domain/entities"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGiftItem(t *testing.T) {
+	mockRepo := new(MockShopRepository)
+	uc := NewUsecase(mockRepo)
+
+	req := entities.GiftRequest{Item: "cup", ToUser: "userB", Message: "С днём рождения!"}
+	mockRepo.On("GiftItem", mock.Anything, "userA", req).Return(nil)
+
+	err := uc.GiftItem(context.Background(), "userA", req)
+
+	assert.NoError(t, err)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGiftItemToSelf(t *testing.T) {
+	mockRepo := new(MockShopRepository)
+	uc := NewUsecase(mockRepo)
+
+	err := uc.GiftItem(context.Background(), "userA", entities.GiftRequest{Item: "cup", ToUser: "userA"})
+
+	assert.ErrorIs(t, err, entities.ErrGiftToSelf)
+
+	mockRepo.AssertNotCalled(t, "GiftItem", mock.Anything, mock.Anything, mock.Anything)
+}
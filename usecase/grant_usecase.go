@@ -0,0 +1,15 @@
+package usecase
+
+import (
+	"context"
+
+	"ttavito/domain/entities"
+)
+
+func (u *Usecase) GrantCoins(ctx context.Context, admin string, req entities.GrantRequest) (*entities.Grant, error) {
+	return u.repo.GrantCoins(ctx, admin, req)
+}
+
+func (u *Usecase) BulkGrantCoins(ctx context.Context, admin string, req entities.BulkGrantRequest) (*entities.BulkGrantResponse, error) {
+	return u.repo.BulkGrantCoins(ctx, admin, req)
+}
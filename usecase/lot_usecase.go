@@ -0,0 +1,16 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// ExpireCoinLots вызывается фоновым воркером и сжигает просроченные партии монет
+func (u *Usecase) ExpireCoinLots(ctx context.Context) error {
+	expired, err := u.repo.ExpireCoinLots(ctx, time.Now())
+	if expired > 0 {
+		slog.Info("Expired coins written off", "amount", expired)
+	}
+	return err
+}
@@ -0,0 +1,21 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestExpireCoinLots(t *testing.T) {
+	mockRepo := new(MockShopRepository)
+	uc := NewUsecase(mockRepo)
+
+	mockRepo.On("ExpireCoinLots", mock.Anything, mock.AnythingOfType("time.Time")).Return(150, nil)
+
+	err := uc.ExpireCoinLots(context.Background())
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,23 @@
+package usecase
+
+import (
+	"context"
+
+	"ttavito/domain/entities"
+)
+
+func (u *Usecase) GetNotifications(ctx context.Context, username string, req entities.NotificationsRequest) (*entities.NotificationsResponse, error) {
+	return u.repo.GetNotifications(ctx, username, req)
+}
+
+func (u *Usecase) MarkNotificationRead(ctx context.Context, username string, id int64) (*entities.Notification, error) {
+	return u.repo.MarkNotificationRead(ctx, username, id)
+}
+
+func (u *Usecase) MarkNotificationsRead(ctx context.Context, username string, upToID int64) (*entities.ReadNotificationsResponse, error) {
+	updated, err := u.repo.MarkNotificationsRead(ctx, username, upToID)
+	if err != nil {
+		return nil, err
+	}
+	return &entities.ReadNotificationsResponse{Updated: updated}, nil
+}
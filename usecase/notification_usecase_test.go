@@ -0,0 +1,21 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestMarkNotificationsRead(t *testing.T) {
+	mockRepo := new(MockShopRepository)
+	uc := NewUsecase(mockRepo)
+
+	mockRepo.On("MarkNotificationsRead", mock.Anything, "alice", int64(0)).Return(4, nil)
+
+	res, err := uc.MarkNotificationsRead(context.Background(), "alice", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, res.Updated)
+	mockRepo.AssertExpectations(t)
+}
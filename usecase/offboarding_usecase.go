@@ -0,0 +1,21 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"ttavito/domain/entities"
+)
+
+func (u *Usecase) OffboardUser(ctx context.Context, admin string, req entities.OffboardRequest) (*entities.Offboarding, error) {
+	switch req.BalancePolicy {
+	case entities.BalancePolicyForfeit:
+	case entities.BalancePolicyRedistribute:
+		if len(req.Recipients) == 0 {
+			return nil, fmt.Errorf("%w: redistribute requires recipients", entities.ErrInvalidBalancePolicy)
+		}
+	default:
+		return nil, entities.ErrInvalidBalancePolicy
+	}
+	return u.repo.OffboardUser(ctx, admin, req)
+}
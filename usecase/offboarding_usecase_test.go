@@ -0,0 +1,55 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"ttavito/domain/entities"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAuthDeactivated(t *testing.T) {
+	mockRepo := new(MockShopRepository)
+	uc := NewUsecase(mockRepo)
+
+	mockRepo.On("Auth", mock.Anything, "leaver", "password123").Return(false, entities.ErrUserDeactivated)
+
+	err := uc.Auth(context.Background(), "leaver", "password123")
+
+	assert.ErrorIs(t, err, entities.ErrUserDeactivated)
+}
+
+func TestOffboardUser(t *testing.T) {
+	mockRepo := new(MockShopRepository)
+	uc := NewUsecase(mockRepo)
+
+	req := entities.OffboardRequest{
+		Username:      "leaver",
+		BalancePolicy: entities.BalancePolicyRedistribute,
+		Recipients:    []string{"bob", "carol"},
+		Reason:        "увольнение",
+	}
+	mockRepo.On("OffboardUser", mock.Anything, "admin", req).
+		Return(&entities.Offboarding{Username: "leaver", BalanceAmount: 501}, nil)
+
+	res, err := uc.OffboardUser(context.Background(), "admin", req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 501, res.BalanceAmount)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestOffboardUserInvalidPolicy(t *testing.T) {
+	mockRepo := new(MockShopRepository)
+	uc := NewUsecase(mockRepo)
+
+	_, err := uc.OffboardUser(context.Background(), "admin", entities.OffboardRequest{Username: "leaver", BalancePolicy: "donate"})
+	assert.ErrorIs(t, err, entities.ErrInvalidBalancePolicy)
+
+	_, err = uc.OffboardUser(context.Background(), "admin", entities.OffboardRequest{Username: "leaver", BalancePolicy: entities.BalancePolicyRedistribute})
+	assert.ErrorIs(t, err, entities.ErrInvalidBalancePolicy)
+
+	mockRepo.AssertNotCalled(t, "OffboardUser", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+const (
+	// Сколько событий outbox публикуется за один запуск воркера и сколько они
+	// закреплены за репликой, прежде чем их сможет забрать другая
+	outboxBatchSize = 100
+	outboxLease     = time.Minute
+	// Повторы публикации: 2s, 4s, 8s... но не реже раза в час
+	outboxRetryBase = 2 * time.Second
+	outboxRetryMax  = time.Hour
+)
+
+// RelayOutbox вызывается фоновым воркером и публикует накопившиеся события. Событие отмечается
+// опубликованным только после ответа брокера, поэтому при сбое между ними оно уйдёт повторно
+func (u *Usecase) RelayOutbox(ctx context.Context) error {
+	if u.publisher == nil {
+		return nil
+	}
+
+	events, err := u.repo.ClaimOutboxEvents(ctx, outboxBatchSize, outboxLease)
+	if err != nil {
+		return err
+	}
+
+	var published []int64
+	for _, e := range events {
+		err := u.publisher.Publish(ctx, e)
+		if err == nil {
+			published = append(published, e.ID)
+			continue
+		}
+
+		slog.Warn("Failed to publish event", "id", e.ID, "type", e.Type, "attempt", e.Attempts, "error", err)
+		err = u.repo.RetryOutboxEvent(ctx, e.ID, err.Error(), time.Now().Add(retryDelay(e.Attempts, outboxRetryBase, outboxRetryMax)))
+		if err != nil {
+			return errors.Join(err, u.repo.MarkOutboxPublished(ctx, published))
+		}
+	}
+	return u.repo.MarkOutboxPublished(ctx, published)
+}
+
+// retryDelay - пауза перед следующей попыткой после attempts неудачных: base, 2*base, 4*base... до limit
+func retryDelay(attempts int, base, limit time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempts && delay < limit; i++ {
+		delay *= 2
+	}
+	return min(delay, limit)
+}
+
+// CleanupOutbox вызывается фоновым воркером и удаляет давно опубликованные события
+func (u *Usecase) CleanupOutbox(ctx context.Context) error {
+	if u.outboxRetention <= 0 {
+		return nil
+	}
+
+	deleted, err := u.repo.DeleteOutboxPublished(ctx, time.Now().Add(-u.outboxRetention))
+	if deleted > 0 {
+		slog.Info("Published outbox events deleted", "count", deleted)
+	}
+	return err
+}
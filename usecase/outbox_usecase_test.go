@@ -0,0 +1,57 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"ttavito/domain/entities"
+	"ttavito/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRelayOutbox(t *testing.T) {
+	mockRepo := new(MockShopRepository)
+	bus := events.NewBus()
+	uc := NewUsecase(mockRepo, WithEventPublisher(bus, 0))
+
+	var delivered []int64
+	bus.Subscribe("", func(ctx context.Context, e entities.OutboxEvent) error {
+		if e.ID == 2 {
+			return errors.New("subscriber is down")
+		}
+		delivered = append(delivered, e.ID)
+		return nil
+	})
+
+	mockRepo.On("ClaimOutboxEvents", mock.Anything, outboxBatchSize, outboxLease).Return([]entities.OutboxEvent{
+		{ID: 1, Type: entities.EventCoinsTransferred, Attempts: 1},
+		{ID: 2, Type: entities.EventItemPurchased, Attempts: 3},
+		{ID: 3, Type: entities.EventItemPurchased, Attempts: 1},
+	}, nil)
+	mockRepo.On("RetryOutboxEvent", mock.Anything, int64(2), mock.AnythingOfType("string"), mock.MatchedBy(func(next time.Time) bool {
+		return next.After(time.Now().Add(7*time.Second)) && next.Before(time.Now().Add(9*time.Second))
+	})).Return(nil)
+	mockRepo.On("MarkOutboxPublished", mock.Anything, []int64{1, 3}).Return(nil)
+
+	assert.NoError(t, uc.RelayOutbox(context.Background()))
+	assert.Equal(t, []int64{1, 3}, delivered)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRetryDelay(t *testing.T) {
+	assert.Equal(t, 2*time.Second, retryDelay(1, outboxRetryBase, outboxRetryMax))
+	assert.Equal(t, 16*time.Second, retryDelay(4, outboxRetryBase, outboxRetryMax))
+	assert.Equal(t, time.Hour, retryDelay(50, outboxRetryBase, outboxRetryMax))
+}
+
+func TestCleanupOutboxDisabled(t *testing.T) {
+	mockRepo := new(MockShopRepository)
+	uc := NewUsecase(mockRepo, WithEventPublisher(events.NewBus(), 0))
+
+	assert.NoError(t, uc.CleanupOutbox(context.Background()))
+	mockRepo.AssertNotCalled(t, "DeleteOutboxPublished", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,37 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"ttavito/domain/entities"
+)
+
+func (u *Usecase) SendPendingCoin(ctx context.Context, senderUsername string, req entities.SendCoinRequest) (*entities.PendingTransfer, error) {
+	return u.repo.CreatePendingTransfer(ctx, senderUsername, req, time.Now().Add(u.pendingTTL))
+}
+
+func (u *Usecase) GetPendingTransfers(ctx context.Context, username string) (*entities.PendingTransfersResponse, error) {
+	return u.repo.GetPendingTransfers(ctx, username)
+}
+
+func (u *Usecase) AcceptPendingTransfer(ctx context.Context, username, id string) (*entities.PendingTransfer, error) {
+	return u.repo.AcceptPendingTransfer(ctx, username, id)
+}
+
+func (u *Usecase) DeclinePendingTransfer(ctx context.Context, username, id string) (*entities.PendingTransfer, error) {
+	return u.repo.DeclinePendingTransfer(ctx, username, id)
+}
+
+// ExpirePendingTransfers вызывается фоновым воркером
+func (u *Usecase) ExpirePendingTransfers(ctx context.Context) error {
+	count, err := u.repo.ExpirePendingTransfers(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		slog.Info("Expired pending transfers returned to senders", "count", count)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"ttavito/domain/entities"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSendPendingCoin(t *testing.T) {
+	mockRepo := new(MockShopRepository)
+	uc := NewUsecase(mockRepo, WithPendingTransferTTL(time.Hour))
+
+	req := entities.SendCoinRequest{ToUser: "user2", Amount: 100, RequireAcceptance: true}
+	before := time.Now()
+	mockRepo.On("CreatePendingTransfer", mock.Anything, "user1", req, mock.MatchedBy(func(expiresAt time.Time) bool {
+		// Срок подтверждения отсчитывается от момента создания
+		return !expiresAt.Before(before.Add(time.Hour)) && expiresAt.Before(before.Add(time.Hour+time.Minute))
+	})).Return(&entities.PendingTransfer{
+		ID:       "0b6b1f8e-3f43-4a53-9a4c-2f0f6b0d8f11",
+		FromUser: "user1",
+		ToUser:   "user2",
+		Amount:   100,
+		Status:   entities.PendingStatusPending,
+	}, nil)
+
+	res, err := uc.SendPendingCoin(context.Background(), "user1", req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, entities.PendingStatusPending, res.Status)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAcceptPendingTransferExpired(t *testing.T) {
+	mockRepo := new(MockShopRepository)
+	uc := NewUsecase(mockRepo)
+
+	mockRepo.On("AcceptPendingTransfer", mock.Anything, "user2", "0b6b1f8e-3f43-4a53-9a4c-2f0f6b0d8f11").
+		Return((*entities.PendingTransfer)(nil), entities.ErrPendingTransferExpired)
+
+	res, err := uc.AcceptPendingTransfer(context.Background(), "user2", "0b6b1f8e-3f43-4a53-9a4c-2f0f6b0d8f11")
+
+	assert.ErrorIs(t, err, entities.ErrPendingTransferExpired)
+	assert.Nil(t, res)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestExpirePendingTransfers(t *testing.T) {
+	mockRepo := new(MockShopRepository)
+	uc := NewUsecase(mockRepo)
+
+	mockRepo.On("ExpirePendingTransfers", mock.Anything, mock.AnythingOfType("time.Time")).Return(3, nil)
+
+	err := uc.ExpirePendingTransfers(context.Background())
+
+	assert.NoError(t, err)
+
+	mockRepo.AssertExpectations(t)
+}
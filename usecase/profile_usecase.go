@@ -0,0 +1,75 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"ttavito/domain/entities"
+	"ttavito/domain/interfaces"
+)
+
+func (u *Usecase) GetProfile(ctx context.Context, username string) (*entities.Profile, error) {
+	p, err := u.repo.GetProfile(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	return withAvatarURL(p), nil
+}
+
+func (u *Usecase) UpdateProfile(ctx context.Context, username string, req entities.UpdateProfileRequest) (*entities.Profile, error) {
+	if err := u.repo.UpdateProfile(ctx, username, req); err != nil {
+		return nil, err
+	}
+	return u.GetProfile(ctx, username)
+}
+
+// UploadAvatar сохраняет файл под ключом от хеша имени, повторная загрузка перезаписывает его
+func (u *Usecase) UploadAvatar(ctx context.Context, username string, avatar entities.Avatar) (*entities.Profile, error) {
+	if u.avatars == nil {
+		return nil, entities.ErrAvatarStorageDisabled
+	}
+
+	sum := sha256.Sum256([]byte(username))
+	key := "avatars/" + hex.EncodeToString(sum[:])
+	if err := u.avatars.Put(ctx, key, avatar.Data, avatar.ContentType); err != nil {
+		return nil, fmt.Errorf("failed to store avatar: %w", err)
+	}
+
+	if err := u.repo.SetAvatar(ctx, username, key, avatar.ContentType); err != nil {
+		return nil, err
+	}
+	return u.GetProfile(ctx, username)
+}
+
+func (u *Usecase) GetAvatar(ctx context.Context, username string) (*entities.Avatar, error) {
+	if u.avatars == nil {
+		return nil, entities.ErrAvatarStorageDisabled
+	}
+
+	key, contentType, err := u.repo.GetAvatarKey(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := u.avatars.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, interfaces.ErrObjectNotFound) {
+			return nil, entities.ErrAvatarNotFound
+		}
+		return nil, fmt.Errorf("failed to load avatar: %w", err)
+	}
+
+	return &entities.Avatar{Data: data, ContentType: contentType}, nil
+}
+
+// withAvatarURL добавляет ссылку на аватар. Время загрузки в ссылке сбрасывает кеш браузера
+func withAvatarURL(p *entities.Profile) *entities.Profile {
+	if p.AvatarUpdatedAt != nil {
+		p.AvatarURL = fmt.Sprintf("/api/users/%s/avatar?v=%d", url.PathEscape(p.Username), p.AvatarUpdatedAt.Unix())
+	}
+	return p
+}
@@ -0,0 +1,67 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"ttavito/domain/entities"
+	"ttavito/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetProfileAvatarURL(t *testing.T) {
+	mockRepo := new(MockShopRepository)
+	uc := NewUsecase(mockRepo)
+
+	uploaded := time.Unix(1739520000, 0)
+	mockRepo.On("GetProfile", mock.Anything, "bob").
+		Return(&entities.Profile{Username: "bob", DisplayName: "Bob", AvatarUpdatedAt: &uploaded}, nil)
+	mockRepo.On("GetProfile", mock.Anything, "carol").
+		Return(&entities.Profile{Username: "carol", DisplayName: "carol"}, nil)
+
+	res, err := uc.GetProfile(context.Background(), "bob")
+	assert.NoError(t, err)
+	assert.Equal(t, "/api/users/bob/avatar?v=1739520000", res.AvatarURL)
+
+	res, err = uc.GetProfile(context.Background(), "carol")
+	assert.NoError(t, err)
+	assert.Empty(t, res.AvatarURL)
+}
+
+func TestUploadAvatar(t *testing.T) {
+	store, err := storage.NewLocalStore(t.TempDir())
+	assert.NoError(t, err)
+
+	mockRepo := new(MockShopRepository)
+	uc := NewUsecase(mockRepo, WithAvatarStore(store))
+
+	var key string
+	mockRepo.On("SetAvatar", mock.Anything, "bob", mock.Anything, "image/png").
+		Run(func(args mock.Arguments) { key = args.String(2) }).
+		Return(nil)
+	mockRepo.On("GetProfile", mock.Anything, "bob").
+		Return(&entities.Profile{Username: "bob", DisplayName: "bob"}, nil)
+
+	_, err = uc.UploadAvatar(context.Background(), "bob", entities.Avatar{Data: []byte("png"), ContentType: "image/png"})
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+
+	mockRepo.On("GetAvatarKey", mock.Anything, "bob").Return(key, "image/png", nil)
+	avatar, err := uc.GetAvatar(context.Background(), "bob")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("png"), avatar.Data)
+	assert.Equal(t, "image/png", avatar.ContentType)
+}
+
+func TestUploadAvatarWithoutStore(t *testing.T) {
+	mockRepo := new(MockShopRepository)
+	uc := NewUsecase(mockRepo)
+
+	_, err := uc.UploadAvatar(context.Background(), "bob", entities.Avatar{Data: []byte("png"), ContentType: "image/png"})
+
+	assert.ErrorIs(t, err, entities.ErrAvatarStorageDisabled)
+	mockRepo.AssertNotCalled(t, "SetAvatar", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
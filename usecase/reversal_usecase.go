@@ -0,0 +1,11 @@
+package usecase
+
+import (
+	"context"
+
+	"ttavito/domain/entities"
+)
+
+func (u *Usecase) ReverseTransfer(ctx context.Context, admin string, req entities.ReverseTransferRequest) (*entities.ReversalResponse, error) {
+	return u.repo.ReverseTransfer(ctx, admin, req)
+}
@@ -0,0 +1,50 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"ttavito/domain/entities"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestReverseTransfer(t *testing.T) {
+	mockRepo := new(MockShopRepository)
+	uc := NewUsecase(mockRepo)
+
+	req := entities.ReverseTransferRequest{TransferID: "0b6b1f8e-3f43-4a53-9a4c-2f0f6b0d8f11", AllowPartial: true}
+	mockRepo.On("ReverseTransfer", mock.Anything, "admin", req).Return(&entities.ReversalResponse{
+		ID:         "5d1c2e64-8c5e-4f4a-bd0e-2b0c8a1f7c22",
+		ReversalOf: req.TransferID,
+		FromUser:   "user2",
+		ToUser:     "user1",
+		Amount:     30,
+		Partial:    true,
+	}, nil)
+
+	res, err := uc.ReverseTransfer(context.Background(), "admin", req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, req.TransferID, res.ReversalOf)
+	assert.Equal(t, 30, res.Amount)
+	assert.True(t, res.Partial)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestReverseTransferAlreadySpent(t *testing.T) {
+	mockRepo := new(MockShopRepository)
+	uc := NewUsecase(mockRepo)
+
+	req := entities.ReverseTransferRequest{TransferID: "0b6b1f8e-3f43-4a53-9a4c-2f0f6b0d8f11"}
+	mockRepo.On("ReverseTransfer", mock.Anything, "admin", req).Return((*entities.ReversalResponse)(nil), entities.ErrReversalInsufficientFunds)
+
+	res, err := uc.ReverseTransfer(context.Background(), "admin", req)
+
+	assert.ErrorIs(t, err, entities.ErrReversalInsufficientFunds)
+	assert.Nil(t, res)
+
+	mockRepo.AssertExpectations(t)
+}
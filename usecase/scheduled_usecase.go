@@ -0,0 +1,134 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"ttavito/cron"
+	"ttavito/domain/entities"
+)
+
+// Сколько расписаний обрабатывается за один запуск воркера
+const scheduledTransfersBatchSize = 100
+
+func (u *Usecase) CreateScheduledTransfer(ctx context.Context, senderUsername string, req entities.ScheduledTransferRequest) (*entities.ScheduledTransfer, error) {
+	var nextRunAt time.Time
+	if req.Cron != "" {
+		next, err := nextCronRun(req.Cron, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		nextRunAt = next
+	} else if req.RunAt != nil {
+		nextRunAt = *req.RunAt
+	} else {
+		return nil, entities.ErrInvalidSchedule
+	}
+
+	return u.repo.CreateScheduledTransfer(ctx, senderUsername, req, nextRunAt)
+}
+
+func (u *Usecase) GetScheduledTransfers(ctx context.Context, username string) ([]entities.ScheduledTransfer, error) {
+	return u.repo.GetScheduledTransfers(ctx, username)
+}
+
+func (u *Usecase) PauseScheduledTransfer(ctx context.Context, username, id string) (*entities.ScheduledTransfer, error) {
+	return u.changeScheduledTransferStatus(ctx, username, id, entities.ScheduleStatusPaused)
+}
+
+func (u *Usecase) ResumeScheduledTransfer(ctx context.Context, username, id string) (*entities.ScheduledTransfer, error) {
+	return u.changeScheduledTransferStatus(ctx, username, id, entities.ScheduleStatusActive)
+}
+
+func (u *Usecase) CancelScheduledTransfer(ctx context.Context, username, id string) (*entities.ScheduledTransfer, error) {
+	return u.changeScheduledTransferStatus(ctx, username, id, entities.ScheduleStatusCancelled)
+}
+
+// Допустимые переходы статуса расписания по запросу пользователя
+var scheduleTransitions = map[string][]string{
+	entities.ScheduleStatusPaused:    {entities.ScheduleStatusActive},
+	entities.ScheduleStatusActive:    {entities.ScheduleStatusPaused},
+	entities.ScheduleStatusCancelled: {entities.ScheduleStatusActive, entities.ScheduleStatusPaused},
+}
+
+func (u *Usecase) changeScheduledTransferStatus(ctx context.Context, username, id, status string) (*entities.ScheduledTransfer, error) {
+	s, err := u.repo.GetScheduledTransfer(ctx, username, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !slices.Contains(scheduleTransitions[status], s.Status) {
+		return nil, entities.ErrInvalidScheduleTransition
+	}
+
+	// Регулярный перевод после паузы продолжается со следующего слота, пропущенные не догоняются
+	var nextRunAt *time.Time
+	if status == entities.ScheduleStatusActive && s.Cron != "" {
+		next, err := nextCronRun(s.Cron, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		nextRunAt = &next
+	}
+
+	err = u.repo.UpdateScheduledTransferStatus(ctx, id, s.Status, status, nextRunAt)
+	if err != nil {
+		return nil, err
+	}
+
+	s.Status = status
+	if nextRunAt != nil {
+		s.NextRunAt = nextRunAt
+	}
+	return s, nil
+}
+
+// RunScheduledTransfers вызывается фоновым воркером и выполняет наступившие переводы
+func (u *Usecase) RunScheduledTransfers(ctx context.Context) error {
+	now := time.Now()
+	due, err := u.repo.GetDueScheduledTransfers(ctx, now, scheduledTransfersBatchSize)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, s := range due {
+		var nextRunAt *time.Time
+		if s.Cron != "" {
+			next, err := nextCronRun(s.Cron, now)
+			if err == nil {
+				nextRunAt = &next
+			}
+		}
+
+		run, err := u.repo.RunScheduledTransfer(ctx, s, now, nextRunAt)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("scheduled transfer %s: %w", s.ID, err))
+			continue
+		}
+		if run == nil {
+			continue
+		}
+		if run.Status != entities.ScheduleRunSuccess {
+			slog.Warn("Scheduled transfer failed", "id", s.ID, "sender", s.FromUser, "status", run.Status, "error", run.Error)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func nextCronRun(expr string, after time.Time) (time.Time, error) {
+	schedule, err := cron.Parse(expr)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %v", entities.ErrInvalidSchedule, err)
+	}
+	next := schedule.Next(after.UTC())
+	if next.IsZero() {
+		return time.Time{}, entities.ErrScheduleNeverRuns
+	}
+	return next, nil
+}
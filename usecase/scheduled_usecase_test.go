@@ -0,0 +1,94 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"ttavito/domain/entities"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateScheduledTransferCron(t *testing.T) {
+	mockRepo := new(MockShopRepository)
+	uc := NewUsecase(mockRepo)
+
+	req := entities.ScheduledTransferRequest{ToUser: "user2", Amount: 10, Cron: "0 9 1 * *"}
+	mockRepo.On("CreateScheduledTransfer", mock.Anything, "user1", req, mock.MatchedBy(func(next time.Time) bool {
+		return next.After(time.Now()) && next.Day() == 1 && next.Hour() == 9 && next.Minute() == 0
+	})).Return(&entities.ScheduledTransfer{ID: "0b6b1f8e-3f43-4a53-9a4c-2f0f6b0d8f11", Status: entities.ScheduleStatusActive}, nil)
+
+	res, err := uc.CreateScheduledTransfer(context.Background(), "user1", req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, entities.ScheduleStatusActive, res.Status)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateScheduledTransferInvalidCron(t *testing.T) {
+	mockRepo := new(MockShopRepository)
+	uc := NewUsecase(mockRepo)
+
+	_, err := uc.CreateScheduledTransfer(context.Background(), "user1", entities.ScheduledTransferRequest{ToUser: "user2", Amount: 10, Cron: "0 0 30 2 *"})
+
+	assert.ErrorIs(t, err, entities.ErrScheduleNeverRuns)
+
+	mockRepo.AssertNotCalled(t, "CreateScheduledTransfer", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPauseScheduledTransfer(t *testing.T) {
+	mockRepo := new(MockShopRepository)
+	uc := NewUsecase(mockRepo)
+
+	id := "0b6b1f8e-3f43-4a53-9a4c-2f0f6b0d8f11"
+	mockRepo.On("GetScheduledTransfer", mock.Anything, "user1", id).Return(&entities.ScheduledTransfer{ID: id, Status: entities.ScheduleStatusActive, Cron: "0 9 * * *"}, nil)
+	mockRepo.On("UpdateScheduledTransferStatus", mock.Anything, id, entities.ScheduleStatusActive, entities.ScheduleStatusPaused, (*time.Time)(nil)).Return(nil)
+
+	res, err := uc.PauseScheduledTransfer(context.Background(), "user1", id)
+
+	assert.NoError(t, err)
+	assert.Equal(t, entities.ScheduleStatusPaused, res.Status)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestResumeCancelledScheduledTransfer(t *testing.T) {
+	mockRepo := new(MockShopRepository)
+	uc := NewUsecase(mockRepo)
+
+	id := "0b6b1f8e-3f43-4a53-9a4c-2f0f6b0d8f11"
+	mockRepo.On("GetScheduledTransfer", mock.Anything, "user1", id).Return(&entities.ScheduledTransfer{ID: id, Status: entities.ScheduleStatusCancelled}, nil)
+
+	_, err := uc.ResumeScheduledTransfer(context.Background(), "user1", id)
+
+	assert.ErrorIs(t, err, entities.ErrInvalidScheduleTransition)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRunScheduledTransfers(t *testing.T) {
+	mockRepo := new(MockShopRepository)
+	uc := NewUsecase(mockRepo)
+
+	oneOff := entities.ScheduledTransfer{ID: "0b6b1f8e-3f43-4a53-9a4c-2f0f6b0d8f11", FromUser: "user1", ToUser: "user2", Amount: 10}
+	recurring := entities.ScheduledTransfer{ID: "5d1c2e64-8c5e-4f4a-bd0e-2b0c8a1f7c22", FromUser: "user1", ToUser: "user3", Amount: 10, Cron: "0 9 * * *"}
+
+	mockRepo.On("GetDueScheduledTransfers", mock.Anything, mock.AnythingOfType("time.Time"), scheduledTransfersBatchSize).
+		Return([]entities.ScheduledTransfer{oneOff, recurring}, nil)
+	// Разовый перевод больше не повторяется
+	mockRepo.On("RunScheduledTransfer", mock.Anything, oneOff, mock.AnythingOfType("time.Time"), (*time.Time)(nil)).
+		Return(&entities.ScheduledTransferRun{ScheduleID: oneOff.ID, Status: entities.ScheduleRunSuccess}, nil)
+	// Регулярный переносится на следующий слот даже при нехватке монет
+	mockRepo.On("RunScheduledTransfer", mock.Anything, recurring, mock.AnythingOfType("time.Time"), mock.MatchedBy(func(next *time.Time) bool {
+		return next != nil && next.Hour() == 9
+	})).Return(&entities.ScheduledTransferRun{ScheduleID: recurring.ID, Status: entities.ScheduleRunInsufficientBalance}, nil)
+
+	err := uc.RunScheduledTransfers(context.Background())
+
+	assert.NoError(t, err)
+
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,11 @@
+package usecase
+
+import (
+	"context"
+
+	"ttavito/domain/entities"
+)
+
+func (u *Usecase) SearchUsers(ctx context.Context, requester string, req entities.UserSearchRequest) (*entities.UserSearchResponse, error) {
+	return u.repo.SearchUsers(ctx, requester, req)
+}
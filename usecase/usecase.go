@@ -2,33 +2,155 @@ package usecase
 
 import (
 	"context"
+	"crypto/ed25519"
+	"errors"
 	"fmt"
+	"time"
 	"ttavito/domain/entities"
 	"ttavito/domain/interfaces"
+	"ttavito/fraud"
+	"ttavito/realtime"
 )
 
+const DefaultPendingTransferTTL = 72 * time.Hour
+
 type Usecase struct {
-	repo interfaces.ShopRepository
+	repo       interfaces.ShopRepository
+	pendingTTL time.Duration
+
+	allowanceAmount int
+	allowancePeriod string
+
+	avatars interfaces.FileStore
+
+	fraudRules  fraud.Rules
+	fraudWindow time.Duration
+
+	checkpointKey  ed25519.PrivateKey
+	checkpointFile string
+	// Воркер контрольных точек один, поэтому поле не защищено
+	lastCheckpointID int64
+
+	publisher       interfaces.EventPublisher
+	outboxRetention time.Duration
+
+	webhooks           interfaces.WebhookSender
+	webhookMaxAttempts int
+
+	userEvents *realtime.Hub
+
+	mailer     interfaces.Mailer
+	mailDomain string
+}
+
+type Option func(*Usecase)
+
+// WithPendingTransferTTL задаёт, сколько перевод ждёт подтверждения получателем
+func WithPendingTransferTTL(ttl time.Duration) Option {
+	return func(u *Usecase) {
+		u.pendingTTL = ttl
+	}
+}
+
+// WithAllowance включает выдачу amount монет всем пользователям раз в period
+func WithAllowance(amount int, period string) Option {
+	return func(u *Usecase) {
+		u.allowanceAmount = amount
+		u.allowancePeriod = period
+	}
+}
+
+// WithAvatarStore задаёт хранилище файлов аватаров
+func WithAvatarStore(store interfaces.FileStore) Option {
+	return func(u *Usecase) {
+		u.avatars = store
+	}
+}
+
+// WithFraudDetection включает поиск схем по переводам за последние window
+func WithFraudDetection(rules fraud.Rules, window time.Duration) Option {
+	return func(u *Usecase) {
+		u.fraudRules = rules
+		u.fraudWindow = window
+	}
+}
+
+// WithAuditCheckpoints включает подписанные контрольные точки журнала в файле path
+func WithAuditCheckpoints(key ed25519.PrivateKey, path string) Option {
+	return func(u *Usecase) {
+		u.checkpointKey = key
+		u.checkpointFile = path
+	}
+}
+
+// WithEventPublisher включает публикацию событий из outbox. Опубликованные события
+// хранятся retention, 0 - не удаляются
+func WithEventPublisher(publisher interfaces.EventPublisher, retention time.Duration) Option {
+	return func(u *Usecase) {
+		u.publisher = publisher
+		u.outboxRetention = retention
+	}
+}
+
+// WithWebhooks включает доставку вебхуков. После maxAttempts неудачных попыток доставка
+// переходит в dead
+func WithWebhooks(sender interfaces.WebhookSender, maxAttempts int) Option {
+	return func(u *Usecase) {
+		u.webhooks = sender
+		u.webhookMaxAttempts = maxAttempts
+	}
+}
+
+// WithUserEvents включает поток событий /api/events
+func WithUserEvents(hub *realtime.Hub) Option {
+	return func(u *Usecase) {
+		u.userEvents = hub
+	}
+}
+
+// WithDigests включает еженедельные письма-сводки. Адрес сотрудника - логин@domain
+func WithDigests(mailer interfaces.Mailer, domain string) Option {
+	return func(u *Usecase) {
+		u.mailer = mailer
+		u.mailDomain = domain
+	}
 }
 
 func (u *Usecase) GetInfo(ctx context.Context, username string) (*entities.InfoResponse, error) {
 	return u.repo.GetInfo(ctx, username)
 }
+
 func (u *Usecase) BuyItem(ctx context.Context, username, item string) error {
 	return u.repo.BuyItem(ctx, username, item)
 }
 
-func (u *Usecase) SendCoin(ctx context.Context, senderUsername string, recipientUsername string, amount int) error {
-	return u.repo.SendCoin(ctx, senderUsername, recipientUsername, amount)
+func (u *Usecase) SendCoin(ctx context.Context, senderUsername string, recipientUsername string, amount int, message string) error {
+	return u.repo.SendCoin(ctx, senderUsername, recipientUsername, amount, message)
 }
+
 func (u *Usecase) Auth(ctx context.Context, username, password string) error {
 	sd, err := u.repo.Auth(ctx, username, password)
+	if errors.Is(err, entities.ErrUserDeactivated) {
+		return err
+	}
 	if !sd || err != nil {
 		return fmt.Errorf("passwords does not match")
 	}
 	return nil
 }
 
-func NewUsecase(repo interfaces.ShopRepository) *Usecase {
-	return &Usecase{repo: repo}
+// SessionsRevokedAt используется AuthMiddleware для проверки отозванных токенов
+func (u *Usecase) SessionsRevokedAt(ctx context.Context, username string) (time.Time, error) {
+	return u.repo.SessionsRevokedAt(ctx, username)
+}
+
+func NewUsecase(repo interfaces.ShopRepository, opts ...Option) *Usecase {
+	u := &Usecase{
+		repo:       repo,
+		pendingTTL: DefaultPendingTransferTTL,
+	}
+	for _, opt := range opts {
+		opt(u)
+	}
+	return u
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 	"ttavito/domain/entities"
 
 	"github.com/stretchr/testify/assert"
@@ -24,16 +25,336 @@ func (m *MockShopRepository) BuyItem(ctx context.Context, username, item string)
 	return args.Error(0)
 }
 
-func (m *MockShopRepository) SendCoin(ctx context.Context, senderUsername, recipientUsername string, amount int) error {
-	args := m.Called(ctx, senderUsername, recipientUsername, amount)
+func (m *MockShopRepository) GiftItem(ctx context.Context, buyer string, req entities.GiftRequest) error {
+	args := m.Called(ctx, buyer, req)
 	return args.Error(0)
 }
 
+func (m *MockShopRepository) SendCoin(ctx context.Context, senderUsername, recipientUsername string, amount int, message string) error {
+	args := m.Called(ctx, senderUsername, recipientUsername, amount, message)
+	return args.Error(0)
+}
+
+func (m *MockShopRepository) BatchSendCoin(ctx context.Context, senderUsername string, req entities.BatchSendCoinRequest) (*entities.BatchSendCoinResponse, error) {
+	args := m.Called(ctx, senderUsername, req)
+	return args.Get(0).(*entities.BatchSendCoinResponse), args.Error(1)
+}
+
+func (m *MockShopRepository) CreatePendingTransfer(ctx context.Context, senderUsername string, req entities.SendCoinRequest, expiresAt time.Time) (*entities.PendingTransfer, error) {
+	args := m.Called(ctx, senderUsername, req, expiresAt)
+	return args.Get(0).(*entities.PendingTransfer), args.Error(1)
+}
+
+func (m *MockShopRepository) GetPendingTransfers(ctx context.Context, username string) (*entities.PendingTransfersResponse, error) {
+	args := m.Called(ctx, username)
+	return args.Get(0).(*entities.PendingTransfersResponse), args.Error(1)
+}
+
+func (m *MockShopRepository) AcceptPendingTransfer(ctx context.Context, username, id string) (*entities.PendingTransfer, error) {
+	args := m.Called(ctx, username, id)
+	return args.Get(0).(*entities.PendingTransfer), args.Error(1)
+}
+
+func (m *MockShopRepository) DeclinePendingTransfer(ctx context.Context, username, id string) (*entities.PendingTransfer, error) {
+	args := m.Called(ctx, username, id)
+	return args.Get(0).(*entities.PendingTransfer), args.Error(1)
+}
+
+func (m *MockShopRepository) ExpirePendingTransfers(ctx context.Context, now time.Time) (int, error) {
+	args := m.Called(ctx, now)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockShopRepository) CreateScheduledTransfer(ctx context.Context, senderUsername string, req entities.ScheduledTransferRequest, nextRunAt time.Time) (*entities.ScheduledTransfer, error) {
+	args := m.Called(ctx, senderUsername, req, nextRunAt)
+	return args.Get(0).(*entities.ScheduledTransfer), args.Error(1)
+}
+
+func (m *MockShopRepository) GetScheduledTransfers(ctx context.Context, username string) ([]entities.ScheduledTransfer, error) {
+	args := m.Called(ctx, username)
+	return args.Get(0).([]entities.ScheduledTransfer), args.Error(1)
+}
+
+func (m *MockShopRepository) GetScheduledTransfer(ctx context.Context, username, id string) (*entities.ScheduledTransfer, error) {
+	args := m.Called(ctx, username, id)
+	return args.Get(0).(*entities.ScheduledTransfer), args.Error(1)
+}
+
+func (m *MockShopRepository) UpdateScheduledTransferStatus(ctx context.Context, id, fromStatus, toStatus string, nextRunAt *time.Time) error {
+	args := m.Called(ctx, id, fromStatus, toStatus, nextRunAt)
+	return args.Error(0)
+}
+
+func (m *MockShopRepository) GetDueScheduledTransfers(ctx context.Context, now time.Time, limit int) ([]entities.ScheduledTransfer, error) {
+	args := m.Called(ctx, now, limit)
+	return args.Get(0).([]entities.ScheduledTransfer), args.Error(1)
+}
+
+func (m *MockShopRepository) RunScheduledTransfer(ctx context.Context, s entities.ScheduledTransfer, now time.Time, nextRunAt *time.Time) (*entities.ScheduledTransferRun, error) {
+	args := m.Called(ctx, s, now, nextRunAt)
+	return args.Get(0).(*entities.ScheduledTransferRun), args.Error(1)
+}
+
+func (m *MockShopRepository) GrantCoins(ctx context.Context, admin string, req entities.GrantRequest) (*entities.Grant, error) {
+	args := m.Called(ctx, admin, req)
+	return args.Get(0).(*entities.Grant), args.Error(1)
+}
+
+func (m *MockShopRepository) BulkGrantCoins(ctx context.Context, admin string, req entities.BulkGrantRequest) (*entities.BulkGrantResponse, error) {
+	args := m.Called(ctx, admin, req)
+	return args.Get(0).(*entities.BulkGrantResponse), args.Error(1)
+}
+
+func (m *MockShopRepository) CreateDepartment(ctx context.Context, admin string, req entities.DepartmentRequest) (*entities.Department, error) {
+	args := m.Called(ctx, admin, req)
+	return args.Get(0).(*entities.Department), args.Error(1)
+}
+
+func (m *MockShopRepository) AddDepartmentMembers(ctx context.Context, admin string, req entities.DepartmentMembersRequest) (*entities.Department, error) {
+	args := m.Called(ctx, admin, req)
+	return args.Get(0).(*entities.Department), args.Error(1)
+}
+
+func (m *MockShopRepository) AllocateDepartmentBudget(ctx context.Context, admin string, req entities.BudgetAllocationRequest) (*entities.Department, error) {
+	args := m.Called(ctx, admin, req)
+	return args.Get(0).(*entities.Department), args.Error(1)
+}
+
+func (m *MockShopRepository) RecognizeFromBudget(ctx context.Context, manager string, req entities.RecognitionRequest) (*entities.Recognition, error) {
+	args := m.Called(ctx, manager, req)
+	return args.Get(0).(*entities.Recognition), args.Error(1)
+}
+
+func (m *MockShopRepository) GetDepartmentBudgetReport(ctx context.Context, req entities.DepartmentReportRequest) (*entities.DepartmentBudgetReport, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(*entities.DepartmentBudgetReport), args.Error(1)
+}
+
+func (m *MockShopRepository) ImportOrgChart(ctx context.Context, entries []entities.OrgChartEntry, dryRun bool) (*entities.OrgChartImportResult, error) {
+	args := m.Called(ctx, entries, dryRun)
+	return args.Get(0).(*entities.OrgChartImportResult), args.Error(1)
+}
+
+func (m *MockShopRepository) GetTeam(ctx context.Context, name string) (*entities.Team, error) {
+	args := m.Called(ctx, name)
+	return args.Get(0).(*entities.Team), args.Error(1)
+}
+
+func (m *MockShopRepository) GetProfile(ctx context.Context, username string) (*entities.Profile, error) {
+	args := m.Called(ctx, username)
+	return args.Get(0).(*entities.Profile), args.Error(1)
+}
+
+func (m *MockShopRepository) UpdateProfile(ctx context.Context, username string, req entities.UpdateProfileRequest) error {
+	args := m.Called(ctx, username, req)
+	return args.Error(0)
+}
+
+func (m *MockShopRepository) SetAvatar(ctx context.Context, username, key, contentType string) error {
+	args := m.Called(ctx, username, key, contentType)
+	return args.Error(0)
+}
+
+func (m *MockShopRepository) SearchUsers(ctx context.Context, requester string, req entities.UserSearchRequest) (*entities.UserSearchResponse, error) {
+	args := m.Called(ctx, requester, req)
+	return args.Get(0).(*entities.UserSearchResponse), args.Error(1)
+}
+
+func (m *MockShopRepository) GetAvatarKey(ctx context.Context, username string) (string, string, error) {
+	args := m.Called(ctx, username)
+	return args.String(0), args.String(1), args.Error(2)
+}
+
+func (m *MockShopRepository) OffboardUser(ctx context.Context, admin string, req entities.OffboardRequest) (*entities.Offboarding, error) {
+	args := m.Called(ctx, admin, req)
+	return args.Get(0).(*entities.Offboarding), args.Error(1)
+}
+
+func (m *MockShopRepository) SessionsRevokedAt(ctx context.Context, username string) (time.Time, error) {
+	args := m.Called(ctx, username)
+	return args.Get(0).(time.Time), args.Error(1)
+}
+
+func (m *MockShopRepository) FreezeAccount(ctx context.Context, admin string, req entities.FreezeRequest) (*entities.AccountFreeze, error) {
+	args := m.Called(ctx, admin, req)
+	return args.Get(0).(*entities.AccountFreeze), args.Error(1)
+}
+
+func (m *MockShopRepository) UnfreezeAccount(ctx context.Context, admin string, req entities.UnfreezeRequest) (*entities.AccountFreeze, error) {
+	args := m.Called(ctx, admin, req)
+	return args.Get(0).(*entities.AccountFreeze), args.Error(1)
+}
+
+func (m *MockShopRepository) GetFraudTransfers(ctx context.Context, window time.Duration) ([]entities.FraudTransfer, error) {
+	args := m.Called(ctx, window)
+	return args.Get(0).([]entities.FraudTransfer), args.Error(1)
+}
+
+func (m *MockShopRepository) SaveFraudFindings(ctx context.Context, findings []entities.FraudFinding) (int, error) {
+	args := m.Called(ctx, findings)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockShopRepository) GetFraudFindings(ctx context.Context, req entities.FraudFindingsRequest) (*entities.FraudFindingsResponse, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(*entities.FraudFindingsResponse), args.Error(1)
+}
+
+func (m *MockShopRepository) ReviewFraudFinding(ctx context.Context, admin string, req entities.FraudReviewRequest) (*entities.FraudFinding, error) {
+	args := m.Called(ctx, admin, req)
+	return args.Get(0).(*entities.FraudFinding), args.Error(1)
+}
+
+func (m *MockShopRepository) GetAuditEvents(ctx context.Context, req entities.AuditQuery) (*entities.AuditEventsResponse, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(*entities.AuditEventsResponse), args.Error(1)
+}
+
+func (m *MockShopRepository) ChainAuditEvents(ctx context.Context, limit int) (int, error) {
+	args := m.Called(ctx, limit)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockShopRepository) GetAuditHead(ctx context.Context) (*entities.AuditHead, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(*entities.AuditHead), args.Error(1)
+}
+
+func (m *MockShopRepository) ScanAuditEvents(ctx context.Context, fn func(entities.AuditEvent) error) error {
+	args := m.Called(ctx, fn)
+	return args.Error(0)
+}
+
+func (m *MockShopRepository) ClaimOutboxEvents(ctx context.Context, limit int, lease time.Duration) ([]entities.OutboxEvent, error) {
+	args := m.Called(ctx, limit, lease)
+	return args.Get(0).([]entities.OutboxEvent), args.Error(1)
+}
+
+func (m *MockShopRepository) MarkOutboxPublished(ctx context.Context, ids []int64) error {
+	args := m.Called(ctx, ids)
+	return args.Error(0)
+}
+
+func (m *MockShopRepository) RetryOutboxEvent(ctx context.Context, id int64, lastError string, nextAttemptAt time.Time) error {
+	args := m.Called(ctx, id, lastError, nextAttemptAt)
+	return args.Error(0)
+}
+
+func (m *MockShopRepository) DeleteOutboxPublished(ctx context.Context, before time.Time) (int, error) {
+	args := m.Called(ctx, before)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockShopRepository) CreateWebhook(ctx context.Context, admin string, req entities.WebhookRequest) (*entities.WebhookSubscription, error) {
+	args := m.Called(ctx, admin, req)
+	return args.Get(0).(*entities.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockShopRepository) GetWebhooks(ctx context.Context) ([]entities.WebhookSubscription, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]entities.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockShopRepository) DeleteWebhook(ctx context.Context, admin, id string) error {
+	args := m.Called(ctx, admin, id)
+	return args.Error(0)
+}
+
+func (m *MockShopRepository) EnqueueWebhookDeliveries(ctx context.Context, e entities.OutboxEvent) (int, error) {
+	args := m.Called(ctx, e)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockShopRepository) ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]entities.WebhookDelivery, error) {
+	args := m.Called(ctx, limit, lease)
+	return args.Get(0).([]entities.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockShopRepository) CompleteWebhookDelivery(ctx context.Context, id string, statusCode int) error {
+	args := m.Called(ctx, id, statusCode)
+	return args.Error(0)
+}
+
+func (m *MockShopRepository) FailWebhookDelivery(ctx context.Context, id string, statusCode int, lastError string, nextAttemptAt *time.Time) error {
+	args := m.Called(ctx, id, statusCode, lastError, nextAttemptAt)
+	return args.Error(0)
+}
+
+func (m *MockShopRepository) GetWebhookDeliveries(ctx context.Context, req entities.WebhookDeliveriesRequest) (*entities.WebhookDeliveriesResponse, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(*entities.WebhookDeliveriesResponse), args.Error(1)
+}
+
+func (m *MockShopRepository) RetryWebhookDelivery(ctx context.Context, admin, id string) (*entities.WebhookDelivery, error) {
+	args := m.Called(ctx, admin, id)
+	return args.Get(0).(*entities.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockShopRepository) GetNotifications(ctx context.Context, username string, req entities.NotificationsRequest) (*entities.NotificationsResponse, error) {
+	args := m.Called(ctx, username, req)
+	return args.Get(0).(*entities.NotificationsResponse), args.Error(1)
+}
+
+func (m *MockShopRepository) MarkNotificationRead(ctx context.Context, username string, id int64) (*entities.Notification, error) {
+	args := m.Called(ctx, username, id)
+	return args.Get(0).(*entities.Notification), args.Error(1)
+}
+
+func (m *MockShopRepository) MarkNotificationsRead(ctx context.Context, username string, upToID int64) (int, error) {
+	args := m.Called(ctx, username, upToID)
+	return args.Get(0).(int), args.Error(1)
+}
+
+func (m *MockShopRepository) GetDigestEnabled(ctx context.Context, username string) (bool, error) {
+	args := m.Called(ctx, username)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockShopRepository) SetDigestEnabled(ctx context.Context, username string, enabled bool) error {
+	args := m.Called(ctx, username, enabled)
+	return args.Error(0)
+}
+
+func (m *MockShopRepository) GetDigestRecipients(ctx context.Context, weekStart time.Time, limit int) ([]string, error) {
+	args := m.Called(ctx, weekStart, limit)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockShopRepository) ClaimDigest(ctx context.Context, username string, weekStart time.Time) (bool, error) {
+	args := m.Called(ctx, username, weekStart)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockShopRepository) ReleaseDigest(ctx context.Context, username string, weekStart time.Time) error {
+	args := m.Called(ctx, username, weekStart)
+	return args.Error(0)
+}
+
+func (m *MockShopRepository) GetDigestActivity(ctx context.Context, username string, from, to time.Time) (*entities.DigestActivity, error) {
+	args := m.Called(ctx, username, from, to)
+	return args.Get(0).(*entities.DigestActivity), args.Error(1)
+}
+
+func (m *MockShopRepository) ExpireCoinLots(ctx context.Context, now time.Time) (int, error) {
+	args := m.Called(ctx, now)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockShopRepository) IssueAllowance(ctx context.Context, period string, amount int) (int, error) {
+	args := m.Called(ctx, period, amount)
+	return args.Int(0), args.Error(1)
+}
+
 func (m *MockShopRepository) Auth(ctx context.Context, username, password string) (bool, error) {
 	args := m.Called(ctx, username, password)
 	return args.Bool(0), args.Error(1)
 }
 
+func (m *MockShopRepository) ReverseTransfer(ctx context.Context, admin string, req entities.ReverseTransferRequest) (*entities.ReversalResponse, error) {
+	args := m.Called(ctx, admin, req)
+	return args.Get(0).(*entities.ReversalResponse), args.Error(1)
+}
+
 func TestGetInfo(t *testing.T) {
 	mockRepo := new(MockShopRepository)
 	uc := NewUsecase(mockRepo)
@@ -85,9 +406,9 @@ func TestSendCoin(t *testing.T) {
 	mockRepo := new(MockShopRepository)
 	uc := NewUsecase(mockRepo)
 
-	mockRepo.On("SendCoin", mock.Anything, "user1", "user2", 100).Return(nil)
+	mockRepo.On("SendCoin", mock.Anything, "user1", "user2", 100, "спасибо").Return(nil)
 
-	err := uc.SendCoin(context.Background(), "user1", "user2", 100)
+	err := uc.SendCoin(context.Background(), "user1", "user2", 100, "спасибо")
 
 	assert.NoError(t, err)
 
@@ -139,9 +460,9 @@ func TestSendCoinInsufficientBalance(t *testing.T) {
 	mockRepo := new(MockShopRepository)
 	uc := NewUsecase(mockRepo)
 
-	mockRepo.On("SendCoin", mock.Anything, "user1", "user2", 100).Return(fmt.Errorf("not enough coins"))
+	mockRepo.On("SendCoin", mock.Anything, "user1", "user2", 100, "").Return(fmt.Errorf("not enough coins"))
 
-	err := uc.SendCoin(context.Background(), "user1", "user2", 100)
+	err := uc.SendCoin(context.Background(), "user1", "user2", 100, "")
 
 	assert.Error(t, err)
 	assert.Equal(t, "not enough coins", err.Error())
@@ -0,0 +1,117 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"ttavito/domain/entities"
+)
+
+const (
+	// Доставки вебхуков: за запуск воркера, одновременно и сколько закреплены за репликой.
+	// Lease больше, чем займёт пачка при таймауте каждой доставки
+	webhookBatchSize   = 50
+	webhookConcurrency = 8
+	webhookLease       = 5 * time.Minute
+	// Повторы доставки: 30s, 1m, 2m... но не реже раза в 6 часов
+	webhookRetryBase = 30 * time.Second
+	webhookRetryMax  = 6 * time.Hour
+)
+
+// CreateWebhook создаёт подписку. Если секрет не задан, он генерируется и возвращается в ответе
+func (u *Usecase) CreateWebhook(ctx context.Context, admin string, req entities.WebhookRequest) (*entities.WebhookSubscription, error) {
+	if req.Secret == "" {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, fmt.Errorf("failed to generate webhook secret: %w", err)
+		}
+		req.Secret = hex.EncodeToString(secret)
+	}
+	return u.repo.CreateWebhook(ctx, admin, req)
+}
+
+func (u *Usecase) GetWebhooks(ctx context.Context) (*entities.WebhooksResponse, error) {
+	webhooks, err := u.repo.GetWebhooks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &entities.WebhooksResponse{Webhooks: webhooks}, nil
+}
+
+func (u *Usecase) DeleteWebhook(ctx context.Context, admin, id string) error {
+	return u.repo.DeleteWebhook(ctx, admin, id)
+}
+
+func (u *Usecase) GetWebhookDeliveries(ctx context.Context, req entities.WebhookDeliveriesRequest) (*entities.WebhookDeliveriesResponse, error) {
+	return u.repo.GetWebhookDeliveries(ctx, req)
+}
+
+func (u *Usecase) RetryWebhookDelivery(ctx context.Context, admin, id string) (*entities.WebhookDelivery, error) {
+	return u.repo.RetryWebhookDelivery(ctx, admin, id)
+}
+
+// EnqueueWebhooks подписан на события outbox и ставит доставки подписчикам в очередь
+func (u *Usecase) EnqueueWebhooks(ctx context.Context, e entities.OutboxEvent) error {
+	if u.webhooks == nil {
+		return nil
+	}
+	_, err := u.repo.EnqueueWebhookDeliveries(ctx, e)
+	return err
+}
+
+// DeliverWebhooks вызывается фоновым воркером и отправляет доставки, которым пора уйти
+func (u *Usecase) DeliverWebhooks(ctx context.Context) error {
+	if u.webhooks == nil {
+		return nil
+	}
+
+	deliveries, err := u.repo.ClaimWebhookDeliveries(ctx, webhookBatchSize, webhookLease)
+	if err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var errs []error
+	sem := make(chan struct{}, webhookConcurrency)
+	for _, d := range deliveries {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if err := u.deliverWebhook(ctx, d); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+func (u *Usecase) deliverWebhook(ctx context.Context, d entities.WebhookDelivery) error {
+	code, sendErr := u.webhooks.Send(ctx, d)
+	if sendErr == nil {
+		return u.repo.CompleteWebhookDelivery(ctx, d.ID, code)
+	}
+
+	var next *time.Time
+	if d.Attempts < u.webhookMaxAttempts {
+		at := time.Now().Add(retryDelay(d.Attempts, webhookRetryBase, webhookRetryMax))
+		next = &at
+		slog.Warn("Webhook delivery failed", "id", d.ID, "attempt", d.Attempts, "error", sendErr)
+	} else {
+		slog.Error("Webhook delivery is dead", "id", d.ID, "attempts", d.Attempts, "error", sendErr)
+	}
+	return u.repo.FailWebhookDelivery(ctx, d.ID, code, sendErr.Error(), next)
+}
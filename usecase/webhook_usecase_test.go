@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"ttavito/domain/entities"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateWebhookGeneratesSecret(t *testing.T) {
+	mockRepo := new(MockShopRepository)
+	uc := NewUsecase(mockRepo)
+
+	mockRepo.On("CreateWebhook", mock.Anything, "admin", mock.MatchedBy(func(req entities.WebhookRequest) bool {
+		return len(req.Secret) == 64
+	})).Return(&entities.WebhookSubscription{ID: "w"}, nil)
+
+	_, err := uc.CreateWebhook(context.Background(), "admin", entities.WebhookRequest{URL: "https://example.com"})
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+// statusSender отвечает подписчиком с кодом из таблицы по адресу доставки
+type statusSender map[string]int
+
+func (s statusSender) Send(ctx context.Context, d entities.WebhookDelivery) (int, error) {
+	code := s[d.URL]
+	if code != http.StatusOK {
+		return code, fmt.Errorf("unexpected status %d", code)
+	}
+	return code, nil
+}
+
+func TestDeliverWebhooks(t *testing.T) {
+	sender := statusSender{"https://bot.example.com/ok": http.StatusOK, "https://bot.example.com/down": http.StatusBadGateway}
+
+	mockRepo := new(MockShopRepository)
+	uc := NewUsecase(mockRepo, WithWebhooks(sender, 3))
+
+	mockRepo.On("ClaimWebhookDeliveries", mock.Anything, webhookBatchSize, webhookLease).Return([]entities.WebhookDelivery{
+		{ID: "ok", URL: "https://bot.example.com/ok", Attempts: 1, Payload: json.RawMessage(`{}`)},
+		{ID: "retry", URL: "https://bot.example.com/down", Attempts: 2, Payload: json.RawMessage(`{}`)},
+		{ID: "dead", URL: "https://bot.example.com/down", Attempts: 3, Payload: json.RawMessage(`{}`)},
+	}, nil)
+	mockRepo.On("CompleteWebhookDelivery", mock.Anything, "ok", http.StatusOK).Return(nil)
+	mockRepo.On("FailWebhookDelivery", mock.Anything, "retry", http.StatusBadGateway, mock.AnythingOfType("string"),
+		mock.MatchedBy(func(next *time.Time) bool {
+			return next != nil && next.After(time.Now().Add(50*time.Second))
+		})).Return(nil)
+	mockRepo.On("FailWebhookDelivery", mock.Anything, "dead", http.StatusBadGateway, mock.AnythingOfType("string"),
+		(*time.Time)(nil)).Return(nil)
+
+	assert.NoError(t, uc.DeliverWebhooks(context.Background()))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestEnqueueWebhooksDisabled(t *testing.T) {
+	mockRepo := new(MockShopRepository)
+	uc := NewUsecase(mockRepo)
+
+	assert.NoError(t, uc.EnqueueWebhooks(context.Background(), entities.OutboxEvent{ID: 1}))
+	mockRepo.AssertNotCalled(t, "EnqueueWebhookDeliveries", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,127 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"ttavito/domain/entities"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var now = time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+func TestSignVerify(t *testing.T) {
+	body := []byte(`{"id":1}`)
+	header := Sign("secret", now, body)
+	assert.Regexp(t, `^t=\d+,v1=[0-9a-f]{64}$`, header)
+
+	assert.NoError(t, Verify("secret", header, body, 5*time.Minute, now.Add(time.Minute)))
+	assert.ErrorIs(t, Verify("other", header, body, 5*time.Minute, now), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify("secret", header, []byte(`{"id":2}`), 5*time.Minute, now), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify("secret", header, body, 5*time.Minute, now.Add(time.Hour)), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify("secret", "garbage", body, 5*time.Minute, now), ErrInvalidSignature)
+}
+
+func TestSenderSend(t *testing.T) {
+	var gotHeader http.Header
+	var gotBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	sender := newLoopbackSender()
+	sender.now = func() time.Time { return now }
+
+	d := entities.WebhookDelivery{
+		ID: "d-1", EventType: entities.EventCoinsTransferred, URL: server.URL,
+		Secret: "secret", Payload: json.RawMessage(`{"id":5}`),
+	}
+	code, err := sender.Send(context.Background(), d)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusAccepted, code)
+	assert.Equal(t, `{"id":5}`, string(gotBody))
+	assert.Equal(t, entities.EventCoinsTransferred, gotHeader.Get(EventHeader))
+	assert.Equal(t, "d-1", gotHeader.Get(DeliveryHeader))
+	assert.NoError(t, Verify("secret", gotHeader.Get(SignatureHeader), gotBody, time.Minute, now))
+}
+
+func TestSenderSendFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "/elsewhere", http.StatusFound)
+			return
+		}
+		http.Error(w, "bot is down", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	sender := newLoopbackSender()
+
+	code, err := sender.Send(context.Background(), entities.WebhookDelivery{URL: server.URL, Payload: json.RawMessage(`{}`)})
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.ErrorContains(t, err, "bot is down")
+
+	// Редирект не выполняется и считается ошибкой
+	code, err = sender.Send(context.Background(), entities.WebhookDelivery{URL: server.URL + "/redirect", Payload: json.RawMessage(`{}`)})
+	assert.Equal(t, http.StatusFound, code)
+	assert.Error(t, err)
+
+	server.Close()
+	code, err = sender.Send(context.Background(), entities.WebhookDelivery{URL: server.URL, Payload: json.RawMessage(`{}`)})
+	assert.Equal(t, 0, code)
+	assert.Error(t, err)
+}
+
+// newLoopbackSender разрешает отправку на loopback: тестовый сервер слушает 127.0.0.1
+func newLoopbackSender() *Sender {
+	sender := NewSender(time.Second)
+	sender.client.Transport = http.DefaultTransport
+	return sender
+}
+
+func TestPublicAddr(t *testing.T) {
+	for addr, want := range map[string]bool{
+		"93.184.216.34":    true,
+		"2606:4700::1111":  true,
+		"127.0.0.1":        false,
+		"::1":              false,
+		"10.1.2.3":         false,
+		"172.16.0.1":       false,
+		"192.168.0.1":      false,
+		"169.254.169.254":  false,
+		"fe80::1":          false,
+		"fd00::1":          false,
+		"0.0.0.0":          false,
+		"::ffff:127.0.0.1": false,
+	} {
+		assert.Equal(t, want, PublicAddr(netip.MustParseAddr(addr)), addr)
+	}
+}
+
+func TestSenderRejectsPrivateTarget(t *testing.T) {
+	var called bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	// Имя разрешается в loopback уже при отправке, проверка на регистрации его бы не поймала
+	target := strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+	code, err := NewSender(time.Second).Send(context.Background(), entities.WebhookDelivery{URL: target, Payload: json.RawMessage(`{}`)})
+
+	assert.Equal(t, 0, code)
+	assert.ErrorIs(t, err, ErrForbiddenTarget)
+	assert.False(t, called)
+}
@@ -0,0 +1,30 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+type Job func(ctx context.Context) error
+
+// RunPeriodic запускает job раз в interval, пока не отменён ctx.
+// Ошибка одного запуска логируется и не останавливает следующие.
+func RunPeriodic(ctx context.Context, name string, interval time.Duration, job Job) {
+	slog.Info("Worker started", "worker", name, "interval", interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("Worker stopped", "worker", name)
+			return
+		case <-ticker.C:
+			if err := job(ctx); err != nil {
+				slog.Error("Worker run failed", "worker", name, "error", err)
+			}
+		}
+	}
+}
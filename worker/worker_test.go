@@ -0,0 +1,36 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunPeriodic(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var runs atomic.Int32
+	done := make(chan struct{})
+	go func() {
+		RunPeriodic(ctx, "test", time.Millisecond, func(ctx context.Context) error {
+			// Ошибка не должна останавливать воркер
+			if runs.Add(1) >= 3 {
+				cancel()
+			}
+			return errors.New("boom")
+		})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("worker did not stop after context cancel")
+	}
+
+	assert.GreaterOrEqual(t, runs.Load(), int32(3))
+}